@@ -0,0 +1,70 @@
+// Package main provides the dict commands for the CLI.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/app"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/pkg/logger"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+type importWiktionaryOptions struct {
+	dbPath               string
+	language             string
+	translationLanguages []string
+}
+
+// NewDictCmd returns the dict cobra command that manages local dictionary sources.
+func NewDictCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dict",
+		Short: "Manage local dictionary sources",
+	}
+	cmd.AddCommand(newImportWiktionaryCmd())
+	return cmd
+}
+
+func newImportWiktionaryCmd() *cobra.Command {
+	opts := &importWiktionaryOptions{}
+	cmd := &cobra.Command{
+		Use:   "import-wiktionary <kaikki.jsonl>",
+		Short: "Import a Wiktextract (kaikki.org) JSONL dump into the local wiktionary store",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			verbose, _ = cmd.Root().PersistentFlags().GetBool("verbose")
+			runImportWiktionary(args[0], opts, verbose)
+		},
+	}
+	cmd.Flags().StringVar(&opts.dbPath, "db", "dict/wiktionary.db", "Path of the wiktionary store to create")
+	cmd.Flags().StringVar(&opts.language, "lang", "en", "Language code of the entries to import")
+	cmd.Flags().StringSliceVar(&opts.translationLanguages, "translations", []string{"ru"}, "Language codes of translations to keep")
+	return cmd
+}
+
+func runImportWiktionary(dumpFile string, opts *importWiktionaryOptions, verbose bool) {
+	log := logger.SetupLogger(verbose)
+	defer func() {
+		if err := log.Sync(); err != nil {
+			fmt.Fprintf(os.Stderr, "logger.Sync error: %v\n", err)
+		}
+	}()
+
+	config := &app.WiktionaryImporterConfig{
+		DumpFile:             dumpFile,
+		DBPath:               opts.dbPath,
+		Language:             opts.language,
+		TranslationLanguages: opts.translationLanguages,
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if err := app.NewWiktionaryImporter(config, log).Run(ctx); err != nil {
+		log.Fatal("Wiktionary import failed", zap.Error(err)) //nolint:gocritic
+	}
+}
@@ -0,0 +1,87 @@
+// Package main provides the extract-highlights command for the CLI.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/app"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/pkg/logger"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+type extractHighlightsOptions struct {
+	outputExcelPath string
+	sourceLang      string
+	targetLang      string
+	format          string
+	epub            string
+	title           string
+	books           []string
+	maxWords        int
+	translation     translationOptions
+}
+
+// NewExtractHighlightsCmd returns the extract-highlights cobra command.
+func NewExtractHighlightsCmd() *cobra.Command {
+	opts := &extractHighlightsOptions{}
+	cmd := &cobra.Command{
+		Use:   "extract-highlights <highlights>",
+		Short: "Extract words highlighted in KOReader, calibre, Apple Books or Moon+ Reader to Excel",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			verbose, _ = cmd.Root().PersistentFlags().GetBool("verbose")
+			runExtractHighlights(args[0], opts, verbose)
+		},
+	}
+	cmd.Flags().StringVar(&opts.outputExcelPath, "output-excel-path", "", "Output Excel file path (required)")
+	cmd.Flags().StringVar(&opts.sourceLang, "source-lang", "ru", "ISO 639-1 code of the language you know (column A)")
+	cmd.Flags().StringVar(&opts.targetLang, "target-lang", "en", "ISO 639-1 code of the language of the book")
+	cmd.Flags().StringVar(&opts.format, "format", "auto", "Highlights format: auto (by extension), koreader, calibre, apple-books, moon-reader") //nolint:lll
+	cmd.Flags().StringVar(&opts.epub, "epub", "", "EPUB the highlights were made in, for example sentences and chapters")
+	cmd.Flags().StringVar(&opts.title, "title", "", "Book title to tag the words with, replacing the stored one")
+	cmd.Flags().StringSliceVar(&opts.books, "book", nil, "Only import highlights from books whose title contains this text (repeatable)")
+	cmd.Flags().IntVar(&opts.maxWords, "max-words", 4, "Skip highlights longer than this many words (0 keeps all)")
+	opts.translation.addFlags(cmd)
+
+	cmd.MarkFlagRequired("output-excel-path") //nolint:errcheck
+	return cmd
+}
+
+func runExtractHighlights(highlightsPath string, opts *extractHighlightsOptions, verbose bool) {
+	log := logger.SetupLogger(verbose)
+	defer func() {
+		if err := log.Sync(); err != nil {
+			fmt.Fprintf(os.Stderr, "logger.Sync error: %v\n", err)
+		}
+	}()
+
+	languages := core.LanguagePair{Source: opts.sourceLang, Target: opts.targetLang}
+	if err := languages.Validate(); err != nil {
+		log.Fatal("Invalid languages", zap.Error(err)) //nolint:gocritic
+	}
+
+	config := &app.HighlightExtractorConfig{
+		HighlightsPath: highlightsPath,
+		Format:         opts.format,
+		EPUBPath:       opts.epub,
+		Title:          opts.title,
+		SheetPath:      opts.outputExcelPath,
+		Languages:      languages,
+		Books:          opts.books,
+		MaxWords:       opts.maxWords,
+		// Translation
+		TranslationConfig: opts.translation.config(),
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if err := app.NewHighlightExtractor(config, log).Run(ctx); err != nil {
+		log.Fatal("Highlight extraction failed", zap.Error(err))
+	}
+}
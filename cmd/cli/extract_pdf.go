@@ -2,10 +2,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 
 	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/app"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
 	"github.com/commedesvlados/anki-flashcards-autogen-cli/pkg/logger"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
@@ -13,8 +16,26 @@ import (
 
 type extractPdfOptions struct {
 	uniPDFAPIKey     string
+	pdfBackend       string
 	inputPDFBookPath string
 	outputExcelPath  string
+	merge            bool
+	highlightNew     bool
+	book             string
+	sourceLang       string
+	targetLang       string
+	lemmaList        string
+	frequencyList    string
+	noLemmas         bool
+	types            []string
+	colors           []string
+	authors          []string
+	since            string
+	until            string
+	pages            string
+	colorTags        map[string]string
+	colorDecks       map[string]string
+	translation      translationOptions
 }
 
 // NewExtractPdfCmd returns the extract-pdf cobra command.
@@ -30,10 +51,28 @@ func NewExtractPdfCmd() *cobra.Command {
 			runExtractPdf(cmd, opts, progressBar, verbose)
 		},
 	}
-	cmd.Flags().StringVar(&opts.uniPDFAPIKey, "uni-api-key", "", "UniPDF API key (required)")
+	cmd.Flags().StringVar(&opts.uniPDFAPIKey, "uni-api-key", "", "UniPDF API key (required for the unipdf backend)")
+	cmd.Flags().StringVar(&opts.pdfBackend, "pdf-backend", app.PDFBackendAuto, "PDF library: unipdf, native (no license) or auto (unipdf when a key is set)") //nolint:lll
 	cmd.Flags().StringVar(&opts.inputPDFBookPath, "input-pdf-book-path", "", "Input PDF file path (required)")
 	cmd.Flags().StringVar(&opts.outputExcelPath, "output-excel-path", "", "Output Excel file path (required)")
-	cmd.MarkFlagRequired("uni-api-key")         //nolint:errcheck
+	cmd.Flags().BoolVar(&opts.merge, "merge", false, "Add new words to an existing output workbook instead of overwriting it")
+	cmd.Flags().BoolVar(&opts.highlightNew, "highlight-new", false, "Highlight the rows added by --merge")
+	cmd.Flags().StringVar(&opts.book, "book", "", "Book name written to the Book column by --merge (default: the PDF file name)")
+	cmd.Flags().StringVar(&opts.sourceLang, "source-lang", "ru", "ISO 639-1 code of the language you know (column A)")
+	cmd.Flags().StringVar(&opts.targetLang, "target-lang", "en", "ISO 639-1 code of the language of the book (column B)")
+	cmd.Flags().StringVar(&opts.lemmaList, "lemma-list", "", "\"lemma<TAB>form\" list of dictionary forms (English has built-in rules)")
+	cmd.Flags().StringVar(&opts.frequencyList, "frequency-list", "", "Word frequency list, most common first, to pick the dictionary form of English words") //nolint:lll
+	cmd.Flags().BoolVar(&opts.noLemmas, "no-lemmas", false, "Keep highlighted words as written instead of their dictionary form")
+	cmd.Flags().StringSliceVar(&opts.types, "type", []string{"highlight", "underline"}, "Annotation types: highlight, underline, squiggly, strikeout")           //nolint:lll
+	cmd.Flags().StringSliceVar(&opts.colors, "color", nil, "Only extract annotations of this colour: a name (yellow, green, blue, ...) or #rrggbb (repeatable)") //nolint:lll
+	cmd.Flags().StringSliceVar(&opts.authors, "author", nil, "Only extract annotations whose author contains this text (repeatable)")
+	cmd.Flags().StringVar(&opts.since, "since", "", "Only extract annotations modified on or after this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&opts.until, "until", "", "Only extract annotations modified on or before this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&opts.pages, "pages", "", "Only extract annotations on these pages, e.g. 1-10,15,20-")
+	cmd.Flags().StringToStringVar(&opts.colorTags, "color-tag", nil, "Tag the words by annotation colour, e.g. yellow=vocabulary,green=quotes")
+	cmd.Flags().StringToStringVar(&opts.colorDecks, "color-deck", nil, "Put the words in subdecks by annotation colour, e.g. green=Quotes")
+	opts.translation.addFlags(cmd)
+
 	cmd.MarkFlagRequired("input-pdf-book-path") //nolint:errcheck
 	cmd.MarkFlagRequired("output-excel-path")   //nolint:errcheck
 	return cmd
@@ -47,15 +86,73 @@ func runExtractPdf(_ *cobra.Command, opts *extractPdfOptions, progressBar, verbo
 		}
 	}()
 
+	languages := core.LanguagePair{Source: opts.sourceLang, Target: opts.targetLang}
+	if err := languages.Validate(); err != nil {
+		log.Fatal("Invalid languages", zap.Error(err)) //nolint:gocritic
+	}
+	annotations, err := opts.annotationOptions()
+	if err != nil {
+		log.Fatal("Invalid annotation filter", zap.Error(err))
+	}
+
 	config := &app.PDFExtractorConfig{
-		ProgressBar:  progressBar,
-		UniPDFAPIKey: opts.uniPDFAPIKey,
-		PDFPath:      opts.inputPDFBookPath,
-		SheetPath:    opts.outputExcelPath,
+		ProgressBar:   progressBar,
+		UniPDFAPIKey:  opts.uniPDFAPIKey,
+		Backend:       opts.pdfBackend,
+		PDFPath:       opts.inputPDFBookPath,
+		SheetPath:     opts.outputExcelPath,
+		Merge:         opts.merge,
+		HighlightNew:  opts.highlightNew,
+		Book:          opts.book,
+		Languages:     languages,
+		LemmaList:     opts.lemmaList,
+		NoLemmas:      opts.noLemmas,
+		FrequencyList: opts.frequencyList,
+		Annotations:   *annotations,
+		// Translation
+		TranslationConfig: opts.translation.config(),
 	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
 	extractor := app.NewPDFExtractor(config, log)
-	if err := extractor.Run(); err != nil {
+	if err := extractor.Run(ctx); err != nil {
 		log.Fatal("PDF extraction failed", zap.Error(err)) //nolint:gocritic
 	}
 	log.Info("Wrote words to Excel", zap.String("output", opts.outputExcelPath))
 }
+
+// annotationOptions parses and checks the annotation filter flags
+func (opts *extractPdfOptions) annotationOptions() (*app.AnnotationOptions, error) {
+	since, err := parseDate(opts.since)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --since date: %w", err)
+	}
+	until, err := parseDate(opts.until)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --until date: %w", err)
+	}
+	if !until.IsZero() {
+		until = until.AddDate(0, 0, 1) // include the whole day
+	}
+	pages, err := app.ParsePageRanges(opts.pages)
+	if err != nil {
+		return nil, err
+	}
+
+	annotations := &app.AnnotationOptions{
+		Types:      opts.types,
+		Colors:     opts.colors,
+		Authors:    opts.authors,
+		Since:      since,
+		Until:      until,
+		Pages:      pages,
+		ColorTags:  opts.colorTags,
+		ColorDecks: opts.colorDecks,
+	}
+	if err := annotations.Validate(); err != nil {
+		return nil, err
+	}
+	return annotations, nil
+}
@@ -0,0 +1,93 @@
+// Package main provides the extract-subs command for the CLI.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/app"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/pkg/logger"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+type extractSubsOptions struct {
+	outputExcelPath string
+	sourceLang      string
+	targetLang      string
+	encoding        string
+	frequencyList   string
+	maxRank         int
+	known           []string
+	lemmaList       string
+	noLemmas        bool
+	minLength       int
+	translation     translationOptions
+}
+
+// NewExtractSubsCmd returns the extract-subs cobra command.
+func NewExtractSubsCmd() *cobra.Command {
+	opts := &extractSubsOptions{}
+	cmd := &cobra.Command{
+		Use:   "extract-subs <subtitles.srt|.vtt>...",
+		Short: "Mine new words from SRT/VTT subtitles to Excel",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			verbose, _ = cmd.Root().PersistentFlags().GetBool("verbose")
+			runExtractSubs(args, opts, verbose)
+		},
+	}
+	cmd.Flags().StringVar(&opts.outputExcelPath, "output-excel-path", "", "Output Excel file path (required)")
+	cmd.Flags().StringVar(&opts.sourceLang, "source-lang", "ru", "ISO 639-1 code of the language you know (column A)")
+	cmd.Flags().StringVar(&opts.targetLang, "target-lang", "en", "ISO 639-1 code of the subtitles")
+	cmd.Flags().StringVar(&opts.encoding, "encoding", "utf-8", "Text encoding of the subtitles, e.g. windows-1252")
+	cmd.Flags().StringVar(&opts.frequencyList, "frequency-list", "", "Word frequency list, most common first (one word per line, extra columns ignored)") //nolint:lll
+	cmd.Flags().IntVar(&opts.maxRank, "max-rank", 3000, "Skip the words among this many most common words of --frequency-list")
+	cmd.Flags().StringSliceVar(&opts.known, "known", nil, "Known words to skip: .apkg deck, make-apkg word list or text file with one word per line (repeatable)") //nolint:lll
+	cmd.Flags().StringVar(&opts.lemmaList, "lemma-list", "", "\"lemma<TAB>form\" list of dictionary forms (English has built-in rules)")
+	cmd.Flags().BoolVar(&opts.noLemmas, "no-lemmas", false, "Keep words as written instead of their dictionary form")
+	cmd.Flags().IntVar(&opts.minLength, "min-length", 3, "Skip words shorter than this many letters")
+	opts.translation.addFlags(cmd)
+
+	cmd.MarkFlagRequired("output-excel-path") //nolint:errcheck
+	return cmd
+}
+
+func runExtractSubs(subtitlePaths []string, opts *extractSubsOptions, verbose bool) {
+	log := logger.SetupLogger(verbose)
+	defer func() {
+		if err := log.Sync(); err != nil {
+			fmt.Fprintf(os.Stderr, "logger.Sync error: %v\n", err)
+		}
+	}()
+
+	languages := core.LanguagePair{Source: opts.sourceLang, Target: opts.targetLang}
+	if err := languages.Validate(); err != nil {
+		log.Fatal("Invalid languages", zap.Error(err)) //nolint:gocritic
+	}
+
+	config := &app.SubtitleMinerConfig{
+		SubtitlePaths: subtitlePaths,
+		Encoding:      opts.encoding,
+		SheetPath:     opts.outputExcelPath,
+		Languages:     languages,
+		FrequencyList: opts.frequencyList,
+		MaxRank:       opts.maxRank,
+		KnownPaths:    opts.known,
+		LemmaList:     opts.lemmaList,
+		NoLemmas:      opts.noLemmas,
+		MinLength:     opts.minLength,
+		// Translation
+		TranslationConfig: opts.translation.config(),
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if err := app.NewSubtitleMiner(config, log).Run(ctx); err != nil {
+		log.Fatal("Subtitle mining failed", zap.Error(err))
+	}
+}
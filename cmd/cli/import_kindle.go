@@ -0,0 +1,114 @@
+// Package main provides the import-kindle command for the CLI.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/app"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/pkg/logger"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// dateLayout is the format of --since and --until
+const dateLayout = "2006-01-02"
+
+type importKindleOptions struct {
+	outputExcelPath string
+	sourceLang      string
+	targetLang      string
+	books           []string
+	since           string
+	until           string
+	includeMastered bool
+	noStems         bool
+	translation     translationOptions
+}
+
+// NewImportKindleCmd returns the import-kindle cobra command.
+func NewImportKindleCmd() *cobra.Command {
+	opts := &importKindleOptions{}
+	cmd := &cobra.Command{
+		Use:   "import-kindle <vocab.db>",
+		Short: "Import words looked up on a Kindle (Vocabulary Builder) to Excel",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			verbose, _ = cmd.Root().PersistentFlags().GetBool("verbose")
+			runImportKindle(args[0], opts, verbose)
+		},
+	}
+	cmd.Flags().StringVar(&opts.outputExcelPath, "output-excel-path", "", "Output Excel file path (required)")
+	cmd.Flags().StringVar(&opts.sourceLang, "source-lang", "ru", "ISO 639-1 code of the language you know (column A)")
+	cmd.Flags().StringVar(&opts.targetLang, "target-lang", "en", "ISO 639-1 code of the books; lookups in other languages are skipped")
+	cmd.Flags().StringSliceVar(&opts.books, "book", nil, "Only import lookups from books whose title contains this text (repeatable)")
+	cmd.Flags().StringVar(&opts.since, "since", "", "Only import lookups made on or after this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&opts.until, "until", "", "Only import lookups made on or before this date (YYYY-MM-DD)")
+	cmd.Flags().BoolVar(&opts.includeMastered, "include-mastered", false, "Also import words marked as mastered on the Kindle")
+	cmd.Flags().BoolVar(&opts.noStems, "no-stems", false, "Keep the looked-up word form (\"running\") instead of its stem (\"run\")")
+	opts.translation.addFlags(cmd)
+
+	cmd.MarkFlagRequired("output-excel-path") //nolint:errcheck
+	return cmd
+}
+
+func runImportKindle(vocabPath string, opts *importKindleOptions, verbose bool) {
+	log := logger.SetupLogger(verbose)
+	defer func() {
+		if err := log.Sync(); err != nil {
+			fmt.Fprintf(os.Stderr, "logger.Sync error: %v\n", err)
+		}
+	}()
+
+	languages := core.LanguagePair{Source: opts.sourceLang, Target: opts.targetLang}
+	if err := languages.Validate(); err != nil {
+		log.Fatal("Invalid languages", zap.Error(err)) //nolint:gocritic
+	}
+	since, err := parseDate(opts.since)
+	if err != nil {
+		log.Fatal("Invalid --since date", zap.Error(err))
+	}
+	until, err := parseDate(opts.until)
+	if err != nil {
+		log.Fatal("Invalid --until date", zap.Error(err))
+	}
+	if !until.IsZero() {
+		until = until.AddDate(0, 0, 1) // include the whole day
+	}
+
+	config := &app.KindleImporterConfig{
+		VocabPath:       vocabPath,
+		SheetPath:       opts.outputExcelPath,
+		Languages:       languages,
+		Books:           opts.books,
+		Since:           since,
+		Until:           until,
+		IncludeMastered: opts.includeMastered,
+		UseStems:        !opts.noStems,
+		// Translation
+		TranslationConfig: opts.translation.config(),
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if err := app.NewKindleImporter(config, log).Run(ctx); err != nil {
+		log.Fatal("Kindle import failed", zap.Error(err))
+	}
+}
+
+// parseDate parses a YYYY-MM-DD date in local time; empty returns the zero time
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.ParseInLocation(dateLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected YYYY-MM-DD: %w", err)
+	}
+	return t, nil
+}
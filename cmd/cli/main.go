@@ -21,7 +21,7 @@ var (
 var rootCmd = &cobra.Command{
 	Use:     "anki-builder",
 	Short:   "Anki Flashcard Builder",
-	Long:    `A CLI tool that reads Russian-English word pairs from Excel, enriches them with dictionary data and images, and generates Anki packages.`, //nolint:lll
+	Long:    `A CLI tool that reads word pairs (Russian-English by default) from Excel, CSV or text files, enriches them with dictionary data and images, and generates Anki packages.`, //nolint:lll
 	Version: Version,
 	Run:     runMain,
 }
@@ -35,6 +35,10 @@ func registerCommands() {
 	registerGlobalFlags(rootCmd)
 	rootCmd.AddCommand(NewMakeApkgCmd())
 	rootCmd.AddCommand(NewExtractPdfCmd())
+	rootCmd.AddCommand(NewExtractHighlightsCmd())
+	rootCmd.AddCommand(NewExtractSubsCmd())
+	rootCmd.AddCommand(NewImportKindleCmd())
+	rootCmd.AddCommand(NewDictCmd())
 	rootCmd.SetHelpTemplate(helpTemplate)
 }
 
@@ -54,13 +58,18 @@ func runMain(cmd *cobra.Command, args []string) {
 
 const helpTemplate = `Anki Flashcard Builder
 
-A CLI tool that reads Russian-English word pairs from Excel, enriches them with dictionary data and images, and generates Anki packages.
+A CLI tool that reads word pairs (Russian-English by default) from Excel, CSV or text files, enriches them with dictionary data and images, and generates Anki packages.
 
 Usage:
   anki-builder [command] [flags]
 
 Available Commands:
-  make-apkg   Create a new Anki .apkg file from Excel data
+  make-apkg          Create a new Anki .apkg file from an Excel, CSV, TSV or text word list
+  extract-pdf        Extract highlighted/underlined words from PDF to Excel
+  extract-highlights Extract words highlighted in KOReader, calibre, Apple Books or Moon+ Reader to Excel
+  extract-subs       Mine new words from SRT/VTT subtitles (extract-subs <file>...) to Excel
+  import-kindle      Import words looked up on a Kindle (import-kindle <vocab.db>) to Excel
+  dict               Manage local dictionary sources (dict import-wiktionary <kaikki.jsonl>)
 
 Global Flags:
   --help, -h                   Show this help message
@@ -68,22 +77,127 @@ Global Flags:
   --verbose, -v                Enable verbose logging (default false)
 
 make-apkg Flags:
-  --input, -i string           Path to Excel file with word pairs (default "data/words.xlsx")
+  --input, -i string           Path to the word list: Excel, CSV, TSV or text file (default "data/words.xlsx")
+  --input-format string        Input format: auto (by extension), xlsx, csv, tsv, text (default "auto")
+  --sheet string               Sheet name or 1-based index to read (default first sheet)
+  --columns stringToString     Column roles by header name or letter, e.g. source=Translation,target=English,pos=C
+  --no-header                  No header row: columns A, B and C are source, target and part of speech
+  --delimiter string           CSV/TSV field separator, e.g. ';' or tab (default ',' for CSV, tab for TSV)
+  --no-quotes                  Read CSV/TSV fields literally instead of unquoting "..." fields
+  --encoding string            Encoding of CSV/TSV and text input, e.g. windows-1251, koi8-r, utf-16 (default "utf-8")
   --output, -o string          Output Anki package file (default "output/vocab.apkg")
   --media string               Directory for downloaded media files (default "media")
   --enriched string            Directory for enriched JSON data (default "enriched")
   --unsplash string            Unsplash API access key (or set UNSPLASH_API_KEY env var)
-  --deck string                Name of the Anki deck (default "Designed Autogenerated RU-EN Vocabulary")
+  --pixabay-key string         Pixabay API key (or set PIXABAY_API_KEY env var)
+  --pexels-key string          Pexels API key (or set PEXELS_API_KEY env var)
+  --image-provider string      Image source: unsplash, pixabay, pexels, local, none (default "unsplash")
+  --image-dir string           Folder with your own images for the local image provider (default "images")
+  --deck string                Name of the Anki deck (default "Designed Autogenerated RU-EN Vocabulary", follows the languages)
+  --source-lang string         ISO 639-1 code of the language you know (column A) (default "ru")
+  --target-lang string         ISO 639-1 code of the language you learn (column B) (default "en")
   --no-media-cache             Delete all files in media/ after .apkg is built (default false)
+  --python-genanki             Build the .apkg with scripts/make_apkg.py instead of the built-in writer (default false)
+  --cache-dir string           Directory for the enrichment cache (default "cache")
+  --cache-ttl duration         How long cached dictionary/image lookups stay valid, 0 = forever (default 720h0m0s)
+  --refresh strings            Comma-separated words to fetch again, ignoring the cache
+  --no-cache                   Disable the enrichment cache (default false)
+  --concurrency int            Number of flashcards enriched in parallel (default 4)
+  --dict-rate float            Max dictionary API requests per second, 0 = unlimited (default 5)
+  --image-rate float           Max image API requests per hour, 0 = unlimited (default 50)
+  --download-rate float        Max media downloads per second, 0 = unlimited (default 10)
+  --dictionaries strings       Dictionary providers in priority order: free-dictionary, glossary, wiktionary (default [free-dictionary])
+  --glossary string            Path to a JSON glossary file used by the glossary dictionary provider
+  --wiktionary-db string       Wiktionary store used by the wiktionary dictionary provider (default "dict/wiktionary.db")
+  --senses int                 Number of dictionary senses on a card, best match for the sheet's part of speech first (default 3)
+
+extract-pdf Flags:
+  --uni-api-key string         UniPDF API key (required for the unipdf backend)
+  --pdf-backend string         PDF library: unipdf, native (no license) or auto (unipdf when a key is set) (default "auto")
+  --input-pdf-book-path string Input PDF file path (required)
+  --output-excel-path string   Output Excel file path (required)
+  --merge                      Add new words to an existing output workbook instead of overwriting it (default false)
+  --highlight-new              Highlight the rows added by --merge (default false)
+  --book string                Book name written to the Book column by --merge (default: the PDF file name)
+  --source-lang string         ISO 639-1 code of the language you know (column A) (default "ru")
+  --target-lang string         ISO 639-1 code of the language of the book (column B) (default "en")
+  --lemma-list string          "lemma<TAB>form" list of dictionary forms (English has built-in rules)
+  --frequency-list string      Word frequency list, most common first, to pick the dictionary form of English words
+  --no-lemmas                  Keep highlighted words as written instead of their dictionary form (default false)
+  --type strings               Annotation types: highlight, underline, squiggly, strikeout (default [highlight,underline])
+  --color strings              Only extract annotations of this colour: a name (yellow, green, blue, ...) or #rrggbb (repeatable)
+  --author strings             Only extract annotations whose author contains this text (repeatable)
+  --since string               Only extract annotations modified on or after this date (YYYY-MM-DD)
+  --until string               Only extract annotations modified on or before this date (YYYY-MM-DD)
+  --pages string               Only extract annotations on these pages, e.g. 1-10,15,20-
+  --color-tag stringToString   Tag the words by annotation colour, e.g. yellow=vocabulary,green=quotes
+  --color-deck stringToString  Put the words in subdecks by annotation colour, e.g. green=Quotes
+  --translator strings         Translators that fill column A, in priority order: file, wiktionary, libretranslate (default: the offline ones set up)
+  --translation-file string    Bilingual TSV/CSV word list (target, source) for --translator file
+  --wiktionary-db string       Wiktionary store used by the wiktionary translator (default "dict/wiktionary.db")
+  --translation-url string     LibreTranslate server for --translator libretranslate (default "http://localhost:5000")
+  --translation-key string     LibreTranslate API key (or set LIBRETRANSLATE_API_KEY env var)
+  --min-confidence float       Translations below this confidence (0-1) are marked for review (default 0.7)
+
+extract-highlights Flags:
+  --output-excel-path string   Output Excel file path (required)
+  --source-lang string         ISO 639-1 code of the language you know (column A) (default "ru")
+  --target-lang string         ISO 639-1 code of the language of the book (default "en")
+  --format string              Highlights format: auto (by extension), koreader, calibre, apple-books, moon-reader (default "auto")
+  --epub string                EPUB the highlights were made in, for example sentences and chapters
+  --title string               Book title to tag the words with, replacing the stored one
+  --book strings               Only import highlights from books whose title contains this text (repeatable)
+  --max-words int              Skip highlights longer than this many words, 0 keeps all (default 4)
+  --translator, --translation-file, --wiktionary-db, --translation-url, --translation-key, --min-confidence
+                               Same as extract-pdf
+
+extract-subs Flags:
+  --output-excel-path string   Output Excel file path (required)
+  --source-lang string         ISO 639-1 code of the language you know (column A) (default "ru")
+  --target-lang string         ISO 639-1 code of the subtitles (default "en")
+  --encoding string            Text encoding of the subtitles, e.g. windows-1252 (default "utf-8")
+  --frequency-list string      Word frequency list, most common first (one word per line, extra columns ignored)
+  --max-rank int               Skip the words among this many most common words of --frequency-list (default 3000)
+  --known strings              Known words to skip: .apkg deck, make-apkg word list or text file with one word per line (repeatable)
+  --lemma-list string          "lemma<TAB>form" list of dictionary forms (English has built-in rules)
+  --no-lemmas                  Keep words as written instead of their dictionary form (default false)
+  --min-length int             Skip words shorter than this many letters (default 3)
+  --translator, --translation-file, --wiktionary-db, --translation-url, --translation-key, --min-confidence
+                               Same as extract-pdf
+
+import-kindle Flags:
+  --output-excel-path string   Output Excel file path (required)
+  --source-lang string         ISO 639-1 code of the language you know (column A) (default "ru")
+  --target-lang string         ISO 639-1 code of the books; lookups in other languages are skipped (default "en")
+  --book strings               Only import lookups from books whose title contains this text (repeatable)
+  --since string               Only import lookups made on or after this date (YYYY-MM-DD)
+  --until string               Only import lookups made on or before this date (YYYY-MM-DD)
+  --include-mastered           Also import words marked as mastered on the Kindle (default false)
+  --no-stems                   Keep the looked-up word form ("running") instead of its stem ("run") (default false)
+  --translator, --translation-file, --wiktionary-db, --translation-url, --translation-key, --min-confidence
+                               Same as extract-pdf
+
+dict import-wiktionary Flags:
+  --db string                  Path of the wiktionary store to create (default "dict/wiktionary.db")
+  --lang string                Language code of the entries to import (default "en")
+  --translations strings       Language codes of translations to keep (default [ru])
 
 Example:
   anki-builder make-apkg --input data/vocabulary.xlsx --output my_deck.apkg --unsplash YOUR_API_KEY --deck "My Vocabulary Name"
+  anki-builder make-apkg --input data/de.xlsx --source-lang en --target-lang de --dictionaries wiktionary --image-provider pixabay
+  anki-builder dict import-wiktionary kaikki.org-dictionary-English.jsonl
+  anki-builder extract-highlights books/novel.epub --output-excel-path data/novel.xlsx --translator wiktionary
+  anki-builder extract-subs s01e01.srt s01e02.srt --frequency-list en_50k.txt --known output/deck.apkg --output-excel-path data/s01.xlsx
+  anki-builder import-kindle /Volumes/Kindle/system/vocabulary/vocab.db --output-excel-path data/kindle.xlsx --since 2024-01-01
 
 Environment Variables:
   UNSPLASH_API_KEY: Unsplash API access key (alternative to --unsplash flag)
+  PIXABAY_API_KEY: Pixabay API key (alternative to --pixabay-key flag)
+  PEXELS_API_KEY: Pexels API key (alternative to --pexels-key flag)
+  LIBRETRANSLATE_API_KEY: LibreTranslate API key (alternative to --translation-key flag)
 
 Requirements:
-  - Python 3 with genanki library installed
-  - Unsplash API access key
-  - Excel file with Russian-English word pairs
+  - API key for the selected image provider (Unsplash by default; not needed for local or none)
+  - Word list with source-target word pairs (Excel, CSV, TSV or text)
+  - Python 3 with genanki library installed (only with --python-genanki)
 `
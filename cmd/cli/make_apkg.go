@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/app"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
 	"github.com/commedesvlados/anki-flashcards-autogen-cli/pkg/common"
 	"github.com/commedesvlados/anki-flashcards-autogen-cli/pkg/logger"
 	"github.com/spf13/cobra"
@@ -16,13 +17,39 @@ import (
 )
 
 type makeApkgOptions struct {
-	inputExcelFile string
+	inputFile      string
+	inputFormat    string
+	sheet          string
+	columns        map[string]string
+	noHeader       bool
+	delimiter      string
+	noQuotes       bool
+	encoding       string
 	outputAkgFile  string
 	unsplashKey    string
+	pixabayKey     string
+	pexelsKey      string
+	imageProvider  string
+	imageDir       string
 	deckName       string
+	sourceLang     string
+	targetLang     string
 	mediaDir       string
 	enrichedDir    string
 	noMediaCache   bool
+	pythonGenanki  bool
+	cacheDir       string
+	cacheTTL       time.Duration
+	refresh        []string
+	noCache        bool
+	concurrency    int
+	dictionaryRate float64
+	imageRate      float64
+	downloadRate   float64
+	dictionaries   []string
+	glossaryFile   string
+	wiktionaryDB   string
+	senses         int
 }
 
 // NewMakeApkgCmd returns the make-apkg cobra command.
@@ -32,7 +59,7 @@ func NewMakeApkgCmd() *cobra.Command {
 	var verbose bool
 	cmd := &cobra.Command{
 		Use:   "make-apkg",
-		Short: "Create a new Anki .apkg file from Excel data",
+		Short: "Create a new Anki .apkg file from an Excel, CSV, TSV or text word list",
 		Run: func(cmd *cobra.Command, _ []string) {
 			// Get global flags
 			progressBar, _ = cmd.Root().PersistentFlags().GetBool("progress")
@@ -40,17 +67,43 @@ func NewMakeApkgCmd() *cobra.Command {
 			runMakeApkg(cmd, opts, progressBar, verbose)
 		},
 	}
-	cmd.Flags().StringVarP(&opts.inputExcelFile, "input", "i", "data/words.xlsx", "Path to Excel file with word pairs")
+	cmd.Flags().StringVarP(&opts.inputFile, "input", "i", "data/words.xlsx", "Path to the word list: Excel, CSV, TSV or text file")
+	cmd.Flags().StringVar(&opts.inputFormat, "input-format", "auto", "Input format: auto (by extension), xlsx, csv, tsv, text")
+	cmd.Flags().StringVar(&opts.sheet, "sheet", "", "Sheet name or 1-based index to read (default first sheet)")
+	cmd.Flags().StringToStringVar(&opts.columns, "columns", nil, "Column roles by header name or letter, e.g. source=Translation,target=English,pos=C") //nolint:lll
+	cmd.Flags().BoolVar(&opts.noHeader, "no-header", false, "No header row: columns A, B and C are source, target and part of speech")
+	cmd.Flags().StringVar(&opts.delimiter, "delimiter", "", "CSV/TSV field separator, e.g. ';' or tab (default ',' for CSV, tab for TSV)")
+	cmd.Flags().BoolVar(&opts.noQuotes, "no-quotes", false, "Read CSV/TSV fields literally instead of unquoting \"...\" fields")
+	cmd.Flags().StringVar(&opts.encoding, "encoding", "utf-8", "Encoding of CSV/TSV and text input, e.g. windows-1251, koi8-r, utf-16")
 	cmd.Flags().StringVarP(&opts.outputAkgFile, "output", "o", "output/vocab.apkg", "Output Anki package file")
 	cmd.Flags().StringVar(&opts.unsplashKey, "unsplash", "", "Unsplash API access key (or set UNSPLASH_API_KEY env var)")
-	cmd.Flags().StringVar(&opts.deckName, "deck", "Designed Autogenerated RU-EN Vocabulary", "Name of the Anki deck")
+	cmd.Flags().StringVar(&opts.pixabayKey, "pixabay-key", "", "Pixabay API key (or set PIXABAY_API_KEY env var)")
+	cmd.Flags().StringVar(&opts.pexelsKey, "pexels-key", "", "Pexels API key (or set PEXELS_API_KEY env var)")
+	cmd.Flags().StringVar(&opts.imageProvider, "image-provider", "unsplash", "Image source: unsplash, pixabay, pexels, local, none")
+	cmd.Flags().StringVar(&opts.imageDir, "image-dir", "images", "Folder with your own images for the local image provider, e.g. images/apple.jpg")        //nolint:lll
+	cmd.Flags().StringVar(&opts.deckName, "deck", "Designed Autogenerated RU-EN Vocabulary", "Name of the Anki deck (default name follows the languages)") //nolint:lll
+	cmd.Flags().StringVar(&opts.sourceLang, "source-lang", "ru", "ISO 639-1 code of the language you know (column A)")
+	cmd.Flags().StringVar(&opts.targetLang, "target-lang", "en", "ISO 639-1 code of the language you learn (column B)")
 	cmd.Flags().StringVar(&opts.mediaDir, "media", "media", "Directory for downloaded media files")
 	cmd.Flags().StringVar(&opts.enrichedDir, "enriched", "enriched", "Directory for enriched JSON data")
 	cmd.Flags().BoolVar(&opts.noMediaCache, "no-media-cache", false, "Delete all files in media/ after .apkg is built")
+	cmd.Flags().BoolVar(&opts.pythonGenanki, "python-genanki", false, "Build the .apkg with scripts/make_apkg.py (requires Python venv with genanki)")
+	cmd.Flags().StringVar(&opts.cacheDir, "cache-dir", "cache", "Directory for the enrichment cache")
+	cmd.Flags().DurationVar(&opts.cacheTTL, "cache-ttl", 30*24*time.Hour, "How long cached dictionary/image lookups stay valid (0 = forever)") //nolint:mnd
+	cmd.Flags().StringSliceVar(&opts.refresh, "refresh", nil, "Comma-separated words to fetch again, ignoring the cache")
+	cmd.Flags().BoolVar(&opts.noCache, "no-cache", false, "Disable the enrichment cache")
+	cmd.Flags().IntVar(&opts.concurrency, "concurrency", 4, "Number of flashcards enriched in parallel")                                                                         //nolint:mnd
+	cmd.Flags().Float64Var(&opts.dictionaryRate, "dict-rate", 5, "Max dictionary API requests per second (0 = unlimited)")                                                       //nolint:mnd
+	cmd.Flags().Float64Var(&opts.imageRate, "image-rate", 50, "Max image API requests per hour, e.g. Unsplash quota (0 = unlimited)")                                            //nolint:mnd
+	cmd.Flags().Float64Var(&opts.downloadRate, "download-rate", 10, "Max media downloads per second (0 = unlimited)")                                                            //nolint:mnd
+	cmd.Flags().StringSliceVar(&opts.dictionaries, "dictionaries", []string{"free-dictionary"}, "Dictionary providers in priority order: free-dictionary, glossary, wiktionary") //nolint:lll
+	cmd.Flags().StringVar(&opts.glossaryFile, "glossary", "", "Path to a JSON glossary file used by the glossary dictionary provider")
+	cmd.Flags().StringVar(&opts.wiktionaryDB, "wiktionary-db", "dict/wiktionary.db", "Wiktionary store used by the wiktionary dictionary provider")
+	cmd.Flags().IntVar(&opts.senses, "senses", core.DefaultMaxSenses, "Number of dictionary senses on a card, best match for the sheet's part of speech first") //nolint:lll
 	return cmd
 }
 
-func runMakeApkg(_ *cobra.Command, opts *makeApkgOptions, progressBar, verbose bool) {
+func runMakeApkg(cmd *cobra.Command, opts *makeApkgOptions, progressBar, verbose bool) {
 	log := logger.SetupLogger(verbose)
 	defer func() {
 		if err := log.Sync(); err != nil {
@@ -58,12 +111,21 @@ func runMakeApkg(_ *cobra.Command, opts *makeApkgOptions, progressBar, verbose b
 		}
 	}()
 
-	unsplashKey := opts.unsplashKey
-	if unsplashKey == "" {
-		unsplashKey = os.Getenv("UNSPLASH_API_KEY")
-	}
-	if unsplashKey == "" {
+	unsplashKey := flagOrEnv(opts.unsplashKey, "UNSPLASH_API_KEY")
+	pixabayKey := flagOrEnv(opts.pixabayKey, "PIXABAY_API_KEY")
+	pexelsKey := flagOrEnv(opts.pexelsKey, "PEXELS_API_KEY")
+	switch {
+	case opts.imageProvider == "unsplash" && unsplashKey == "":
 		log.Fatal("Unsplash API key is required. Use --unsplash flag or set UNSPLASH_API_KEY environment variable.") //nolint:gocritic
+	case opts.imageProvider == "pixabay" && pixabayKey == "":
+		log.Fatal("Pixabay API key is required. Use --pixabay-key flag or set PIXABAY_API_KEY environment variable.")
+	case opts.imageProvider == "pexels" && pexelsKey == "":
+		log.Fatal("Pexels API key is required. Use --pexels-key flag or set PEXELS_API_KEY environment variable.")
+	}
+
+	languages := core.LanguagePair{Source: opts.sourceLang, Target: opts.targetLang}
+	if !cmd.Flags().Changed("deck") {
+		opts.deckName = fmt.Sprintf("Designed Autogenerated %s Vocabulary", languages)
 	}
 
 	finalOutputFile := opts.outputAkgFile
@@ -80,17 +142,46 @@ func runMakeApkg(_ *cobra.Command, opts *makeApkgOptions, progressBar, verbose b
 	}
 
 	config := &app.ApkgMakerConfig{
-		ExcelFile:    opts.inputExcelFile,
-		MediaDir:     opts.mediaDir,
-		EnrichedDir:  opts.enrichedDir,
-		OutputFile:   finalOutputFile,
-		UnsplashKey:  unsplashKey,
-		ProgressBar:  progressBar,
-		DeckName:     opts.deckName,
-		NoMediaCache: opts.noMediaCache,
+		InputFile:      opts.inputFile,
+		InputFormat:    opts.inputFormat,
+		Sheet:          opts.sheet,
+		Columns:        opts.columns,
+		NoHeader:       opts.noHeader,
+		Delimiter:      opts.delimiter,
+		NoQuotes:       opts.noQuotes,
+		Encoding:       opts.encoding,
+		MediaDir:       opts.mediaDir,
+		EnrichedDir:    opts.enrichedDir,
+		OutputFile:     finalOutputFile,
+		UnsplashKey:    unsplashKey,
+		PixabayKey:     pixabayKey,
+		PexelsKey:      pexelsKey,
+		ImageProvider:  opts.imageProvider,
+		ImageDir:       opts.imageDir,
+		ProgressBar:    progressBar,
+		DeckName:       opts.deckName,
+		Languages:      languages,
+		NoMediaCache:   opts.noMediaCache,
+		PythonGenanki:  opts.pythonGenanki,
+		CacheDir:       opts.cacheDir,
+		CacheTTL:       opts.cacheTTL,
+		Refresh:        opts.refresh,
+		NoCache:        opts.noCache,
+		Concurrency:    opts.concurrency,
+		DictionaryRate: opts.dictionaryRate,
+		ImageRate:      opts.imageRate,
+		DownloadRate:   opts.downloadRate,
+		Dictionaries:   opts.dictionaries,
+		GlossaryFile:   opts.glossaryFile,
+		WiktionaryDB:   opts.wiktionaryDB,
+		MaxSenses:      opts.senses,
 	}
 
-	application := app.NewApkgMaker(config, log)
+	application, err := app.NewApkgMaker(config, log)
+	if err != nil {
+		log.Fatal("Failed to initialize application", zap.Error(err))
+	}
+	defer application.Close()
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute) //nolint:mnd
 	defer cancel()
 
@@ -100,3 +191,11 @@ func runMakeApkg(_ *cobra.Command, opts *makeApkgOptions, progressBar, verbose b
 
 	log.Info("Application completed successfully")
 }
+
+// flagOrEnv returns the flag value, falling back to the environment variable
+func flagOrEnv(value, envVar string) string {
+	if value != "" {
+		return value
+	}
+	return os.Getenv(envVar)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// translationOptions are the translator flags shared by the commands that write word sheets
+type translationOptions struct {
+	translators     []string
+	translationFile string
+	wiktionaryDB    string
+	translationURL  string
+	translationKey  string
+	minConfidence   float64
+}
+
+func (o *translationOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&o.translators, "translator", nil, "Translators that fill column A, in priority order: file, wiktionary, libretranslate (default: the offline ones set up)") //nolint:lll
+	cmd.Flags().StringVar(&o.translationFile, "translation-file", "", "Bilingual TSV/CSV word list (target, source) for --translator file")
+	cmd.Flags().StringVar(&o.wiktionaryDB, "wiktionary-db", "dict/wiktionary.db", "Wiktionary store used by the wiktionary translator")
+	cmd.Flags().StringVar(&o.translationURL, "translation-url", "http://localhost:5000", "LibreTranslate server for --translator libretranslate") //nolint:lll
+	cmd.Flags().StringVar(&o.translationKey, "translation-key", "", "LibreTranslate API key (or set LIBRETRANSLATE_API_KEY env var)")
+	cmd.Flags().Float64Var(&o.minConfidence, "min-confidence", 0.7, "Translations below this confidence (0-1) are marked for review") //nolint:mnd
+}
+
+func (o *translationOptions) config() app.TranslationConfig {
+	return app.TranslationConfig{
+		Translators:     o.translators,
+		TranslationFile: o.translationFile,
+		WiktionaryDB:    o.wiktionaryDB,
+		TranslationURL:  o.translationURL,
+		TranslationKey:  flagOrEnv(o.translationKey, "LIBRETRANSLATE_API_KEY"),
+		MinConfidence:   o.minConfidence,
+	}
+}
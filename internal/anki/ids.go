@@ -0,0 +1,36 @@
+package anki
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"strings"
+)
+
+// Anki recommends deck and model IDs in [2^30, 2^31) to stay clear of IDs Anki generates itself
+const (
+	idRangeStart = 1 << 30
+	idRangeSize  = 1 << 30
+)
+
+// DeckID derives a stable deck ID from the deck name, so different names never share a deck
+func DeckID(name string) int64 {
+	return hashID("deck", strings.TrimSpace(name))
+}
+
+// ModelID derives a stable note type ID from its definition: name, fields, templates and styling.
+// Changing the note type yields a new ID instead of silently overwriting the old one in Anki.
+func ModelID(m *Model) int64 {
+	parts := []string{"model", m.Name}
+	parts = append(parts, m.Fields...)
+	for _, tmpl := range m.Templates {
+		parts = append(parts, tmpl.Name, tmpl.Front, tmpl.Back)
+	}
+	parts = append(parts, m.CSS)
+	return hashID(parts...)
+}
+
+func hashID(parts ...string) int64 {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x1f")))
+	n := binary.BigEndian.Uint64(sum[:8])
+	return int64(idRangeStart + n%idRangeSize) //nolint:gosec // always below 2^31
+}
@@ -0,0 +1,152 @@
+// Package anki writes Anki packages (.apkg) without any external tooling.
+package anki
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Template is a single card template of a note type
+type Template struct {
+	Name  string
+	Front string
+	Back  string
+}
+
+// Model is an Anki note type: ordered fields, card templates and styling
+type Model struct {
+	ID        int64
+	Name      string
+	Fields    []string
+	Templates []Template
+	CSS       string
+}
+
+// fieldSeparator joins the fields of a note in the notes table
+const fieldSeparator = "\x1f"
+
+// Note is a single note; Fields are in the same order as Model.Fields
+type Note struct {
+	GUID   string
+	Fields []string
+	Tags   []string
+}
+
+// Deck groups notes of one model under a deck name
+type Deck struct {
+	ID    int64
+	Name  string
+	Model *Model
+	Notes []*Note
+}
+
+// AddNote appends a note to the deck
+func (d *Deck) AddNote(note *Note) {
+	d.Notes = append(d.Notes, note)
+}
+
+// modelJSON builds the JSON representation stored in the col.models column
+func (m *Model) modelJSON(deckID, modTime int64) map[string]any {
+	fields := make([]map[string]any, len(m.Fields))
+	for i, name := range m.Fields {
+		fields[i] = map[string]any{
+			"name":   name,
+			"ord":    i,
+			"font":   "Arial",
+			"media":  []string{},
+			"rtl":    false,
+			"size":   20, //nolint:mnd
+			"sticky": false,
+		}
+	}
+
+	templates := make([]map[string]any, len(m.Templates))
+	for i, tmpl := range m.Templates {
+		templates[i] = map[string]any{
+			"name":  tmpl.Name,
+			"ord":   i,
+			"qfmt":  tmpl.Front,
+			"afmt":  tmpl.Back,
+			"bqfmt": "",
+			"bafmt": "",
+			"did":   nil,
+		}
+	}
+
+	return map[string]any{
+		"id":        m.ID,
+		"name":      m.Name,
+		"type":      0,
+		"mod":       modTime,
+		"usn":       -1,
+		"sortf":     0,
+		"did":       deckID,
+		"tmpls":     templates,
+		"flds":      fields,
+		"css":       m.CSS,
+		"latexPre":  latexPre,
+		"latexPost": latexPost,
+		"latexsvg":  false,
+		"req":       m.requirements(),
+		"tags":      []string{},
+		"vers":      []any{},
+	}
+}
+
+// deckJSON builds the JSON representation stored in the col.decks column
+func (d *Deck) deckJSON(modTime int64) map[string]any {
+	return map[string]any{
+		"id":        d.ID,
+		"name":      d.Name,
+		"desc":      "",
+		"collapsed": false,
+		"conf":      1,
+		"dyn":       0,
+		"extendNew": 0,
+		"extendRev": 50, //nolint:mnd
+		"mod":       modTime,
+		"usn":       -1,
+		"lrnToday":  []int{0, 0},
+		"newToday":  []int{0, 0},
+		"revToday":  []int{0, 0},
+		"timeToday": []int{0, 0},
+	}
+}
+
+var fieldRefRe = regexp.MustCompile(`{{\s*([^#/^!{}][^{}]*?)\s*}}`)
+
+// requirements computes the legacy "req" list: which fields must be non-empty for a card to be generated.
+// A front side referencing a single field requires it; otherwise any referenced field is enough.
+func (m *Model) requirements() [][]any {
+	index := make(map[string]int, len(m.Fields))
+	for i, name := range m.Fields {
+		index[name] = i
+	}
+
+	req := make([][]any, 0, len(m.Templates))
+	for ord, tmpl := range m.Templates {
+		var fieldOrds []int
+		seen := make(map[int]bool)
+		for _, match := range fieldRefRe.FindAllStringSubmatch(tmpl.Front, -1) {
+			name := match[1]
+			if i := strings.LastIndex(name, ":"); i >= 0 {
+				name = name[i+1:]
+			}
+			if i, ok := index[name]; ok && !seen[i] {
+				seen[i] = true
+				fieldOrds = append(fieldOrds, i)
+			}
+		}
+		kind := "any"
+		if len(fieldOrds) == 1 {
+			kind = "all"
+		}
+		req = append(req, []any{ord, kind, fieldOrds})
+	}
+	return req
+}
+
+const latexPre = "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n\\usepackage[utf8]{inputenc}\n" +
+	"\\usepackage{amssymb,amsmath}\n\\pagestyle{empty}\n\\setlength{\\parindent}{0in}\n\\begin{document}\n"
+
+const latexPost = "\\end{document}"
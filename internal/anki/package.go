@@ -0,0 +1,273 @@
+package anki
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha1" //nolint:gosec // Anki uses SHA-1 for field checksums
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/pkg/common"
+
+	"go.uber.org/zap"
+
+	_ "modernc.org/sqlite" // pure-Go SQLite driver
+)
+
+// Package is the content of an .apkg file: decks with their notes and the media files they reference
+type Package struct {
+	Decks      []*Deck
+	MediaFiles []string // paths on disk; stored in the package under their base name
+	logger     *zap.Logger
+}
+
+// NewPackage creates an empty package
+func NewPackage(logger *zap.Logger) *Package {
+	return &Package{
+		logger: logger,
+	}
+}
+
+// AddDeck adds a deck to the package
+func (p *Package) AddDeck(deck *Deck) {
+	p.Decks = append(p.Decks, deck)
+}
+
+// AddMediaFile adds a media file to the package
+func (p *Package) AddMediaFile(path string) {
+	p.MediaFiles = append(p.MediaFiles, path)
+}
+
+// WriteToFile writes the package as a zip container with the collection database and media. The package is
+// written to a temporary file and renamed when complete, so a failed write keeps the previous file.
+func (p *Package) WriteToFile(ctx context.Context, outputPath string) error {
+	tmpDir, err := os.MkdirTemp("", "anki-apkg-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	dbPath := filepath.Join(tmpDir, "collection.anki2")
+	if err := p.writeCollection(ctx, dbPath, time.Now()); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil { //nolint:mnd
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	out, err := os.CreateTemp(filepath.Dir(outputPath), "."+filepath.Base(outputPath)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create package file: %w", err)
+	}
+	defer os.Remove(out.Name())
+
+	if err := p.writeZip(out, dbPath); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Chmod(0644); err != nil { //nolint:mnd
+		out.Close()
+		return fmt.Errorf("failed to write package file: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write package file: %w", err)
+	}
+	if err := os.Rename(out.Name(), outputPath); err != nil {
+		return fmt.Errorf("failed to write package file: %w", err)
+	}
+
+	p.logger.Debug("Wrote Anki package",
+		zap.String("path", outputPath),
+		zap.Int("decks", len(p.Decks)),
+		zap.Int("media", len(p.MediaFiles)))
+	return nil
+}
+
+// writeZip writes the zip container: the collection, the media files and the media map
+func (p *Package) writeZip(out io.Writer, dbPath string) error {
+	zw := zip.NewWriter(out)
+	if err := addFileToZip(zw, "collection.anki2", dbPath); err != nil {
+		return err
+	}
+
+	// Media files are stored as "0", "1", ... with a JSON map back to their real names
+	mediaMap := make(map[string]string, len(p.MediaFiles))
+	for i, path := range p.MediaFiles {
+		name := strconv.Itoa(i)
+		if err := addFileToZip(zw, name, path); err != nil {
+			return err
+		}
+		mediaMap[name] = filepath.Base(path)
+	}
+
+	mediaJSON, err := json.Marshal(mediaMap)
+	if err != nil {
+		return fmt.Errorf("failed to marshal media map: %w", err)
+	}
+	w, err := zw.Create("media")
+	if err != nil {
+		return fmt.Errorf("failed to add media map: %w", err)
+	}
+	if _, err := w.Write(mediaJSON); err != nil {
+		return fmt.Errorf("failed to write media map: %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finalize package: %w", err)
+	}
+	return nil
+}
+
+// writeCollection creates the SQLite collection with all decks, models, notes and cards
+func (p *Package) writeCollection(ctx context.Context, dbPath string, now time.Time) error {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open collection database: %w", err)
+	}
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, collectionSchema); err != nil {
+		return fmt.Errorf("failed to create collection schema: %w", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if err := p.insertCol(ctx, tx, now); err != nil {
+		return err
+	}
+
+	// Note and card IDs are millisecond timestamps, incremented for every row
+	nextID := now.UnixMilli()
+	for _, deck := range p.Decks {
+		for _, note := range deck.Notes {
+			noteID := nextID
+			nextID++
+			if err := insertNote(ctx, tx, deck, note, noteID, now.Unix()); err != nil {
+				return err
+			}
+			for ord := range deck.Model.Templates {
+				if _, err := tx.ExecContext(ctx,
+					`INSERT INTO cards VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+					nextID, noteID, deck.ID, ord, now.Unix(), -1, 0, 0, noteID, 0, 0, 0, 0, 0, 0, 0, 0, ""); err != nil {
+					return fmt.Errorf("failed to insert card: %w", err)
+				}
+				nextID++
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit collection: %w", err)
+	}
+	return nil
+}
+
+// insertCol writes the single col row holding models, decks and configuration
+func (p *Package) insertCol(ctx context.Context, tx *sql.Tx, now time.Time) error {
+	var defaults map[string]any
+	if err := json.Unmarshal([]byte(defaultDeck), &defaults); err != nil {
+		return fmt.Errorf("failed to parse default deck: %w", err)
+	}
+
+	models := make(map[string]any)
+	decks := map[string]any{"1": defaults}
+	for _, deck := range p.Decks {
+		models[strconv.FormatInt(deck.Model.ID, 10)] = deck.Model.modelJSON(deck.ID, now.Unix())
+		decks[strconv.FormatInt(deck.ID, 10)] = deck.deckJSON(now.Unix())
+	}
+
+	modelsJSON, err := json.Marshal(models)
+	if err != nil {
+		return fmt.Errorf("failed to marshal models: %w", err)
+	}
+	decksJSON, err := json.Marshal(decks)
+	if err != nil {
+		return fmt.Errorf("failed to marshal decks: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO col VALUES(null,?,?,?,?,?,?,?,?,?,?,?,?)`,
+		now.Unix(), now.UnixMilli(), now.UnixMilli(), schemaVersion, 0, 0, 0,
+		collectionConf, string(modelsJSON), string(decksJSON), deckConfigs, "{}")
+	if err != nil {
+		return fmt.Errorf("failed to insert collection row: %w", err)
+	}
+	return nil
+}
+
+// insertNote writes a single note row
+func insertNote(ctx context.Context, tx *sql.Tx, deck *Deck, note *Note, noteID, modTime int64) error {
+	if len(note.Fields) != len(deck.Model.Fields) {
+		return fmt.Errorf("note has %d fields, model %q expects %d", len(note.Fields), deck.Model.Name, len(deck.Model.Fields))
+	}
+
+	guid := note.GUID
+	if guid == "" {
+		guid = common.GUIDFor(note.Fields...)
+	}
+
+	tags := ""
+	if len(note.Tags) > 0 {
+		tags = " " + strings.Join(note.Tags, " ") + " "
+	}
+
+	sortField := stripHTMLMedia(note.Fields[0])
+	_, err := tx.ExecContext(ctx, `INSERT INTO notes VALUES(?,?,?,?,?,?,?,?,?,?,?)`,
+		noteID, guid, deck.Model.ID, modTime, -1, tags,
+		strings.Join(note.Fields, fieldSeparator), sortField, fieldChecksum(sortField), 0, "")
+	if err != nil {
+		return fmt.Errorf("failed to insert note: %w", err)
+	}
+	return nil
+}
+
+// addFileToZip copies a file from disk into the zip archive under the given name
+func addFileToZip(zw *zip.Writer, name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to package: %w", name, err)
+	}
+	if _, err := io.Copy(w, f); err != nil {
+		return fmt.Errorf("failed to write %s to package: %w", name, err)
+	}
+	return nil
+}
+
+var (
+	htmlTagRe  = regexp.MustCompile(`(?s)<[^>]*>`)
+	soundTagRe = regexp.MustCompile(`\[sound:[^]]+\]`)
+	imgSrcRe   = regexp.MustCompile(`(?i)<img[^>]+src=["']?([^"'>]+)["']?[^>]*>`)
+)
+
+// stripHTMLMedia strips HTML like Anki does for the sort field, keeping image file names
+func stripHTMLMedia(s string) string {
+	s = imgSrcRe.ReplaceAllString(s, " $1 ")
+	s = soundTagRe.ReplaceAllString(s, "")
+	s = htmlTagRe.ReplaceAllString(s, "")
+	return strings.TrimSpace(s)
+}
+
+// fieldChecksum is the first 8 hex digits of the SHA-1 of the field, used by Anki for duplicate detection
+func fieldChecksum(s string) int64 {
+	sum := sha1.Sum([]byte(s)) //nolint:gosec
+	v, _ := strconv.ParseInt(fmt.Sprintf("%x", sum[:4]), 16, 64)
+	return v
+}
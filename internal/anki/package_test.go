@@ -0,0 +1,188 @@
+package anki
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+)
+
+func TestPackage_WriteToFile(t *testing.T) {
+	dir := t.TempDir()
+	imagePath := filepath.Join(dir, "1_apple.jpg")
+	if err := os.WriteFile(imagePath, []byte("jpeg"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	deck := NewVocabularyDeck("Test Deck", core.DefaultLanguagePair)
+	deck.AddNote(VocabularyNote(&core.ExportFlash{
+		ID:           1,
+		Source:       "яблоко",
+		Target:       "apple",
+		PartOfSpeech: "noun",
+		ImagePath:    "1_apple.jpg",
+		Synonyms:     []string{"pome", "malus"},
+	}))
+
+	pkg := NewPackage(zap.NewNop())
+	pkg.AddDeck(deck)
+	pkg.AddMediaFile(imagePath)
+
+	outputPath := filepath.Join(dir, "out", "deck.apkg")
+	if err := pkg.WriteToFile(context.Background(), outputPath); err != nil {
+		t.Fatalf("WriteToFile failed: %v", err)
+	}
+
+	zr, err := zip.OpenReader(outputPath)
+	if err != nil {
+		t.Fatalf("failed to open package: %v", err)
+	}
+	defer zr.Close()
+
+	entries := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		entries[f.Name] = f
+	}
+	for _, name := range []string{"collection.anki2", "media", "0"} {
+		if entries[name] == nil {
+			t.Fatalf("package is missing %q", name)
+		}
+	}
+
+	var media map[string]string
+	if err := json.Unmarshal(readZipEntry(t, entries["media"]), &media); err != nil {
+		t.Fatalf("invalid media map: %v", err)
+	}
+	if media["0"] != "1_apple.jpg" {
+		t.Errorf("Expected media 0 to be 1_apple.jpg, got %q", media["0"])
+	}
+
+	dbPath := filepath.Join(dir, "collection.anki2")
+	if err := os.WriteFile(dbPath, readZipEntry(t, entries["collection.anki2"]), 0600); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var flds, sfld string
+	var mid int64
+	if err := db.QueryRow("SELECT flds, sfld, mid FROM notes").Scan(&flds, &sfld, &mid); err != nil {
+		t.Fatalf("failed to read note: %v", err)
+	}
+	fields := strings.Split(flds, "\x1f")
+	if len(fields) != len(deck.Model.Fields) {
+		t.Fatalf("Expected %d fields, got %d", len(deck.Model.Fields), len(fields))
+	}
+	if fields[1] != "apple" || fields[9] != `<img src="1_apple.jpg">` || fields[10] != "pome, malus" || fields[11] != "" {
+		t.Errorf("Unexpected note fields: %q", fields)
+	}
+	if sfld != "яблоко" {
+		t.Errorf("Expected sort field яблоко, got %q", sfld)
+	}
+	if mid != deck.Model.ID {
+		t.Errorf("Expected model ID %d, got %d", deck.Model.ID, mid)
+	}
+
+	var cards int
+	var did int64
+	if err := db.QueryRow("SELECT COUNT(*), MAX(did) FROM cards").Scan(&cards, &did); err != nil {
+		t.Fatalf("failed to read cards: %v", err)
+	}
+	if cards != 1 || did != deck.ID {
+		t.Errorf("Expected 1 card in deck %d, got %d in deck %d", deck.ID, cards, did)
+	}
+
+	var decksJSON string
+	if err := db.QueryRow("SELECT decks FROM col").Scan(&decksJSON); err != nil {
+		t.Fatalf("failed to read col: %v", err)
+	}
+	if !strings.Contains(decksJSON, `"Test Deck"`) {
+		t.Errorf("Expected deck name in col.decks, got %s", decksJSON)
+	}
+}
+
+// TestPackage_WriteToFile_Failure keeps the previous package when writing a new one fails halfway
+func TestPackage_WriteToFile_Failure(t *testing.T) {
+	dir := t.TempDir()
+	outputPath := filepath.Join(dir, "deck.apkg")
+	if err := os.WriteFile(outputPath, []byte("previous deck"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	pkg := NewPackage(zap.NewNop())
+	pkg.AddDeck(NewVocabularyDeck("Test Deck", core.DefaultLanguagePair))
+	pkg.AddMediaFile(filepath.Join(dir, "missing.jpg"))
+	if err := pkg.WriteToFile(context.Background(), outputPath); err == nil {
+		t.Fatal("WriteToFile succeeded with a missing media file")
+	}
+
+	if data, err := os.ReadFile(outputPath); err != nil || string(data) != "previous deck" {
+		t.Errorf("previous package was changed: %q, %v", data, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+}
+
+func TestDeckID(t *testing.T) {
+	a := DeckID("English Vocabulary")
+	if a != DeckID("English Vocabulary") {
+		t.Errorf("DeckID is not deterministic")
+	}
+	if a == DeckID("German Vocabulary") {
+		t.Errorf("Different deck names must not share a deck ID")
+	}
+	if a < 1<<30 || a >= 1<<31 {
+		t.Errorf("DeckID %d outside of [2^30, 2^31)", a)
+	}
+}
+
+func TestModelID(t *testing.T) {
+	m := VocabularyModel(core.DefaultLanguagePair)
+	if m.ID != ModelID(VocabularyModel(core.DefaultLanguagePair)) {
+		t.Errorf("ModelID is not deterministic")
+	}
+	// The RU-EN note type must keep its ID, or existing decks get a second note type. It last changed when the
+	// Synonyms and Antonyms fields were added.
+	if m.ID != 1153277893 {
+		t.Errorf("RU-EN model ID changed: %d", m.ID)
+	}
+	m.CSS += ".card { color: red; }"
+	if ModelID(m) == VocabularyModel(core.DefaultLanguagePair).ID {
+		t.Errorf("Changing the note type definition must change its ID")
+	}
+
+	de := VocabularyModel(core.LanguagePair{Source: "en", Target: "de"})
+	if de.Fields[0] != "EN" || de.Fields[1] != "DE" || de.ID == m.ID {
+		t.Errorf("unexpected EN-DE note type: fields %v, ID %d", de.Fields[:2], de.ID)
+	}
+	if !strings.Contains(de.Templates[0].Back, "translation/german-english/{{DE}}") {
+		t.Errorf("EN-DE back template should link German to English")
+	}
+}
+
+func readZipEntry(t *testing.T, f *zip.File) []byte {
+	t.Helper()
+	rc, err := f.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
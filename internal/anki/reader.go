@@ -0,0 +1,152 @@
+package anki
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Collection files in an .apkg, newest first. collection.anki21b is zstd-compressed and not supported.
+const (
+	collectionAnki21  = "collection.anki21"
+	collectionAnki2   = "collection.anki2"
+	collectionAnki21b = "collection.anki21b"
+)
+
+// storedModel is the part of a note type read from the col.models JSON
+type storedModel struct {
+	Fields []struct {
+		Name string `json:"name"`
+		Ord  int    `json:"ord"`
+	} `json:"flds"`
+}
+
+// ReadWords returns one field of every note in an .apkg: the first field whose name is in fieldNames
+// (case-insensitive), or the first field of the note type. HTML and media references are stripped.
+func ReadWords(ctx context.Context, path string, fieldNames []string) ([]string, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Anki package: %w", err)
+	}
+	defer zr.Close()
+
+	var collection, newFormat *zip.File
+	for _, name := range []string{collectionAnki21, collectionAnki2} {
+		for _, f := range zr.File {
+			if f.Name == name && collection == nil {
+				collection = f
+			}
+			if f.Name == collectionAnki21b {
+				newFormat = f
+			}
+		}
+	}
+	if newFormat != nil || collection == nil {
+		return nil, fmt.Errorf("cannot read %s: export the deck again with \"Support older Anki versions\" checked", path)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "anki-read-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	dbPath := filepath.Join(tmpDir, collection.Name)
+	if err := extractZipFile(collection, dbPath); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", "file:"+dbPath+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Anki collection: %w", err)
+	}
+	defer db.Close()
+
+	fieldIndex, err := modelFieldIndexes(ctx, db, fieldNames)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT mid, flds FROM notes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Anki notes: %w", err)
+	}
+	defer rows.Close()
+
+	var words []string
+	for rows.Next() {
+		var (
+			modelID int64
+			fields  string
+		)
+		if err := rows.Scan(&modelID, &fields); err != nil {
+			return nil, fmt.Errorf("failed to read Anki note: %w", err)
+		}
+		values := strings.Split(fields, fieldSeparator)
+		if i := fieldIndex[modelID]; i < len(values) {
+			if word := html.UnescapeString(stripHTMLMedia(values[i])); word != "" {
+				words = append(words, word)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Anki notes: %w", err)
+	}
+	return words, nil
+}
+
+// modelFieldIndexes maps note type IDs to the index of the field to read
+func modelFieldIndexes(ctx context.Context, db *sql.DB, fieldNames []string) (map[int64]int, error) {
+	var modelsJSON string
+	if err := db.QueryRowContext(ctx, "SELECT models FROM col").Scan(&modelsJSON); err != nil {
+		return nil, fmt.Errorf("failed to read Anki note types: %w", err)
+	}
+	var models map[string]storedModel
+	if err := json.Unmarshal([]byte(modelsJSON), &models); err != nil {
+		return nil, fmt.Errorf("failed to parse Anki note types: %w", err)
+	}
+
+	indexes := make(map[int64]int, len(models))
+	for id, model := range models {
+		modelID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			continue
+		}
+		indexes[modelID] = 0
+	fields:
+		for _, name := range fieldNames {
+			for _, field := range model.Fields {
+				if strings.EqualFold(field.Name, name) {
+					indexes[modelID] = field.Ord
+					break fields
+				}
+			}
+		}
+	}
+	return indexes, nil
+}
+
+func extractZipFile(f *zip.File, path string) error {
+	src, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", f.Name, err)
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to extract %s: %w", f.Name, err)
+	}
+	if _, err := io.Copy(dst, src); err != nil { //nolint:gosec // the collection is read-only input
+		dst.Close()
+		return fmt.Errorf("failed to extract %s: %w", f.Name, err)
+	}
+	return dst.Close()
+}
@@ -0,0 +1,41 @@
+package anki
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+)
+
+func TestReadWords(t *testing.T) {
+	deck := NewVocabularyDeck("Known", core.DefaultLanguagePair)
+	deck.AddNote(VocabularyNote(&core.ExportFlash{Source: "яблоко", Target: "apple"}))
+	deck.AddNote(VocabularyNote(&core.ExportFlash{Source: "бежать", Target: "<b>run</b> &amp; hide"}))
+	pkg := NewPackage(zap.NewNop())
+	pkg.AddDeck(deck)
+	path := filepath.Join(t.TempDir(), "known.apkg")
+	if err := pkg.WriteToFile(context.Background(), path); err != nil {
+		t.Fatalf("WriteToFile: %v", err)
+	}
+
+	words, err := ReadWords(context.Background(), path, []string{"EN"})
+	if err != nil {
+		t.Fatalf("ReadWords: %v", err)
+	}
+	if want := []string{"apple", "run & hide"}; !reflect.DeepEqual(words, want) {
+		t.Errorf("got %q, want %q", words, want)
+	}
+
+	// Without a matching field name the first field is read
+	words, err = ReadWords(context.Background(), path, []string{"Word"})
+	if err != nil {
+		t.Fatalf("ReadWords: %v", err)
+	}
+	if want := []string{"яблоко", "бежать"}; !reflect.DeepEqual(words, want) {
+		t.Errorf("got %q, want %q", words, want)
+	}
+}
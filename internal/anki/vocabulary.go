@@ -0,0 +1,301 @@
+package anki
+
+import (
+	"html"
+	"strings"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+)
+
+// VocabularyModel returns the note type used for vocabulary flashcards of a language pair.
+// The templates are ported from scripts/make_apkg.py and written for RU-EN: the word fields are named
+// after the language codes, so RU-EN keeps its RU and EN field names. The ID is derived from the whole
+// definition (see ModelID), so it changes whenever the fields, templates or styling do.
+func VocabularyModel(langs core.LanguagePair) *Model {
+	source, target := strings.ToUpper(langs.Source), strings.ToUpper(langs.Target)
+	name := "Designed Autogenerated " + langs.String() + " Flashcard"
+
+	replacer := strings.NewReplacer(
+		"{{RU}}", "{{"+source+"}}",
+		"{{EN}}", "{{"+target+"}}",
+		"ru-word", langs.Source+"-word",
+		"en-word", langs.Target+"-word",
+		"english-russian", core.LanguageName(langs.Target)+"-"+core.LanguageName(langs.Source),
+	)
+	back := vocabularyBack
+	if core.LanguageName(langs.Source) == "" || core.LanguageName(langs.Target) == "" {
+		// Reverso links need language names; drop the link for languages we cannot name
+		back = strings.Replace(back, vocabularyReversoLink, "", 1)
+	}
+
+	m := &Model{
+		Name: name,
+		Fields: []string{
+			source, target, "PartOfSpeech", "Definition", "Example",
+			"IPA_UK", "IPA_US", "AudioUK", "AudioUS", "Image",
+			"Synonyms", "Antonyms",
+		},
+		Templates: []Template{
+			{
+				Name:  name,
+				Front: replacer.Replace(vocabularyFront),
+				Back:  replacer.Replace(back),
+			},
+		},
+		CSS: replacer.Replace(vocabularyCSS),
+	}
+	m.ID = ModelID(m)
+	return m
+}
+
+// NewVocabularyDeck creates an empty deck using the vocabulary note type of the language pair
+func NewVocabularyDeck(name string, langs core.LanguagePair) *Deck {
+	return &Deck{
+		ID:    DeckID(name),
+		Name:  name,
+		Model: VocabularyModel(langs),
+	}
+}
+
+// VocabularyNote converts an exported flashcard into a note of the vocabulary note type
+func VocabularyNote(f *core.ExportFlash) *Note {
+	return &Note{
+		GUID: f.GUID,
+		Fields: []string{
+			f.Source,
+			f.Target,
+			f.PartOfSpeech,
+			definitionMarkup(f),
+			f.Example,
+			f.IPAUK,
+			f.IPAUS,
+			soundMarkup(f.AudioUK),
+			soundMarkup(f.AudioUS),
+			imageMarkup(f.ImagePath),
+			strings.Join(f.Synonyms, ", "),
+			strings.Join(f.Antonyms, ", "),
+		},
+		Tags: f.Tags,
+	}
+}
+
+// MediaFiles returns the media file names referenced by a flashcard
+func MediaFiles(f *core.ExportFlash) []string {
+	var files []string
+	for _, name := range []string{f.AudioUK, f.AudioUS, f.ImagePath} {
+		if name != "" {
+			files = append(files, name)
+		}
+	}
+	return files
+}
+
+func soundMarkup(file string) string {
+	if file == "" {
+		return ""
+	}
+	return "[sound:" + file + "]"
+}
+
+func imageMarkup(file string) string {
+	if file == "" {
+		return ""
+	}
+	return `<img src="` + file + `">`
+}
+
+// definitionMarkup returns the definition, or a numbered list of the ranked senses when the flashcard kept
+// several. The senses are styled inline, so they need no field or CSS of their own.
+func definitionMarkup(f *core.ExportFlash) string {
+	if len(f.Senses) < 2 { //nolint:mnd
+		return f.Definition
+	}
+	var b strings.Builder
+	b.WriteString(`<ol style="text-align: left; display: inline-block; margin: 0;">`)
+	for _, sense := range f.Senses { //nolint:gocritic
+		b.WriteString("<li>")
+		if sense.PartOfSpeech != "" {
+			b.WriteString("<i>" + html.EscapeString(sense.PartOfSpeech) + "</i> ")
+		}
+		b.WriteString(html.EscapeString(sense.Definition))
+		if sense.Example != "" {
+			b.WriteString(`<br><small><em>` + html.EscapeString(sense.Example) + "</em></small>")
+		}
+		b.WriteString("</li>")
+	}
+	b.WriteString("</ol>")
+	return b.String()
+}
+
+const vocabularyFront = `
+    <div class="card">
+      <div class="card-image">{{Image}}</div>
+      <div class="ru-word">{{RU}}</div>
+    </div>
+    `
+
+const vocabularyBack = `
+    <div class="card">
+
+      <div class="card-image">{{Image}}</div>
+
+      <div class="ru-word">{{RU}}</div>
+      <div class="en-word">{{EN}}</div>
+      {{#PartOfSpeech}}
+      <div class="part-of-speech"><i>{{PartOfSpeech}}</i></div>
+      {{/PartOfSpeech}}
+      {{#Definition}}
+      <div class="definition">{{Definition}}</div>
+      {{/Definition}}
+      {{#Example}}
+      <div class="example"><em>{{Example}}</em></div>
+      {{/Example}}
+
+      {{#Synonyms}}
+      <div class="related"><span>Synonyms:</span> {{Synonyms}}</div>
+      {{/Synonyms}}
+      {{#Antonyms}}
+      <div class="related"><span>Antonyms:</span> {{Antonyms}}</div>
+      {{/Antonyms}}
+
+      {{#AudioUK}}
+      <div class="audio-row">
+        {{AudioUK}} <span>UK</span> <span class="phonetic">[{{IPA_UK}}]</span>
+      </div>
+      {{/AudioUK}}
+
+      {{#AudioUS}}
+      <div class="audio-row">
+        {{AudioUS}} <span>US</span> <span class="phonetic">[{{IPA_US}}]</span>
+      </div>
+      {{/AudioUS}}
+
+` + vocabularyReversoLink + `
+    </div>
+    `
+
+const vocabularyReversoLink = `      <div class="reverso-link">
+        <span>
+          <a class="reverso-link" target="_blank" href="https://context.reverso.net/translation/english-russian/{{EN}}">🔗 Link to reverso</a>
+        </span>
+      </div>
+`
+
+const vocabularyCSS = `
+    .card {
+      font-family: 'Segoe UI', sans-serif;
+      background-color: #f8f9fa;
+      border-radius: 20px;
+      padding: 20px;
+      text-align: center;
+      color: #212529;
+      max-width: 600px;
+      margin: auto;
+      box-shadow: 0 4px 10px rgba(0, 0, 0, 0.05);
+    }
+
+    .card-image img {
+      max-width: 500px;
+      margin: 0 auto 20px;
+      display: block;
+    }
+
+    .ru-word {
+      font-size: 2em;
+      font-weight: bold;
+      margin-bottom: 10px;
+    }
+
+    .en-word {
+      font-size: 1.5em;
+      font-weight: bold;
+      color: #333;
+    }
+
+    .part-of-speech {
+      font-style: italic;
+      color: #555;
+      margin: 5px 0;
+    }
+
+    .definition {
+      margin: 10px 0;
+      font-size: 1em;
+    }
+
+    .example {
+      font-style: italic;
+      color: #444;
+      margin-bottom: 10px;
+    }
+
+    .related {
+      color: #555;
+      margin: 4px 0;
+    }
+
+    .related span {
+      font-weight: bold;
+    }
+
+    .audio-row {
+      display: flex;
+      justify-content: center;
+      align-items: center;
+      gap: 10px;
+      margin: 8px 0;
+      flex-wrap: wrap;
+    }
+
+    .audio-row span {
+      font-weight:bold
+    }
+
+    .audio-row .phonetic {
+      font-size: 1.1em;
+      color: #666;
+    }
+
+    .replay-button {
+      /* make it a perfect circle */
+      width: 36px;
+      height: 36px;
+      border-radius: 50%;
+      display: inline-flex;
+      align-items: center;
+      justify-content: center;
+
+      /* pastel background + subtle shadow */
+      background-color: #e9ecef;
+      box-shadow: 0 2px 4px rgba(0,0,0,0.1);
+      border: none;
+      cursor: pointer;
+      transition: background-color 0.2s, transform 0.2s;
+    }
+
+    .replay-button:hover,
+    .replay-button:active {
+      background-color: #dee2e6;
+      transform: scale(1.05);
+    }
+
+    .replay-button svg {
+      width: 20px;
+      height: 20px;
+      fill: #495057;
+    }
+
+    .reverso-link {
+      margin-top: 10px;
+    }
+
+    .reverso-link a {
+      font-size: 0.9em;
+      color: #007bff;
+      text-decoration: none;
+    }
+
+    .reverso-link a:hover {
+      text-decoration: underline;
+    }
+    `
@@ -3,16 +3,25 @@ package app
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"time"
 
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/anki"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/cache"
 	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/dictionary"
 	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/downloader"
-	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/excel"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/images"
 	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/storage"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/util"
 	free_dictionary "github.com/commedesvlados/anki-flashcards-autogen-cli/pkg/clients/free-dictionary"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/pkg/clients/pexels"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/pkg/clients/pixabay"
 	"github.com/commedesvlados/anki-flashcards-autogen-cli/pkg/clients/unsplash"
 
 	"github.com/schollz/progressbar/v3"
@@ -21,65 +30,253 @@ import (
 
 // ApkgMakerConfig holds application configuration
 type ApkgMakerConfig struct {
-	ProgressBar  bool
-	ExcelFile    string
+	ProgressBar bool
+	InputFile   string
+	// Input layout: format (xlsx, csv, tsv, text; empty or "auto" picks it by extension) and how to read it
+	InputFormat  string
+	Sheet        string            // sheet name or 1-based index, empty for the first sheet
+	Columns      map[string]string // column role -> header name or letter, overriding header detection
+	NoHeader     bool              // read every row as data
+	Delimiter    string            // CSV/TSV field separator
+	NoQuotes     bool              // read CSV/TSV fields literally
+	Encoding     string            // CSV/TSV and text encoding, e.g. windows-1251
 	OutputFile   string
 	DeckName     string
+	Languages    core.LanguagePair // source (known) and target (learned) language of the deck
 	UnsplashKey  string
+	PixabayKey   string
+	PexelsKey    string
 	MediaDir     string
 	EnrichedDir  string
 	NoMediaCache bool
+	// PythonGenanki builds the package with scripts/make_apkg.py instead of the native writer
+	PythonGenanki bool
+	// Enrichment cache settings
+	CacheDir string
+	CacheTTL time.Duration
+	Refresh  []string // words to fetch again even if cached
+	NoCache  bool
+	// Dictionary providers in priority order; defaults to the Free Dictionary API
+	Dictionaries []string
+	GlossaryFile string
+	WiktionaryDB string // store created by `dict import-wiktionary`
+	MaxSenses    int    // dictionary senses kept on a card, see core.RankSenses
+	// Image provider: unsplash, pixabay, pexels, local or none
+	ImageProvider string
+	ImageDir      string // folder searched by the local image provider
+	// Enrichment concurrency and per-provider rate limits (0 = unlimited)
+	Concurrency    int
+	DictionaryRate float64 // requests per second
+	ImageRate      float64 // requests per hour
+	DownloadRate   float64 // downloads per second
+	// HTTP overrides for tests: the transport used by the online providers and media downloads (nil uses the
+	// network), and base URLs of the online providers by provider name
+	HTTPTransport http.RoundTripper
+	BaseURLs      map[string]string
 }
 
 // ApkgMaker is the main application orchestrator
 type ApkgMaker struct {
 	config            *ApkgMakerConfig
 	logger            *zap.Logger
-	excelReader       *excel.Reader
+	wordSource        core.WordSource
 	enrichmentService *core.EnrichmentService
 	jsonExporter      *storage.JSONExporter
+	closers           []io.Closer // local sources opened for the run
 }
 
 // NewApkgMaker creates a new application instance
-func NewApkgMaker(config *ApkgMakerConfig, logger *zap.Logger) *ApkgMaker {
+func NewApkgMaker(config *ApkgMakerConfig, logger *zap.Logger) (*ApkgMaker, error) {
+	if err := config.Languages.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Initialize components
-	excelReader := excel.NewReader(logger)
-	dictionaryAPI := free_dictionary.NewAPI(logger)
-	imageAPI := unsplash.NewAPI(config.UnsplashKey, logger)
+	wordSource, err := newWordSource(config, logger)
+	if err != nil {
+		return nil, err
+	}
 	downloader := downloader.NewDownloader(config.MediaDir, logger)
-	enrichmentService := core.NewEnrichmentService(dictionaryAPI, imageAPI, downloader, logger)
+	downloader.SetRateLimiter(util.NewRateLimiter(config.DownloadRate, time.Second))
+	downloader.SetTransport(config.HTTPTransport)
+	var enrichmentCache core.Cache
+	if !config.NoCache {
+		enrichmentCache = cache.NewFileCache(config.CacheDir, config.CacheTTL, config.Refresh, logger)
+	}
+	dictionaryChain, closers, err := newDictionaryChain(config, enrichmentCache, logger)
+	if err != nil {
+		return nil, err
+	}
+	imageProvider, err := newImageProvider(config, enrichmentCache, logger)
+	if err != nil {
+		closeAll(closers, logger)
+		return nil, err
+	}
+	enrichmentService := core.NewEnrichmentService(dictionaryChain, imageProvider, downloader, logger)
+	enrichmentService.SetSenseOptions(config.Languages.Source, config.MaxSenses)
 	jsonExporter := storage.NewJSONExporter(logger)
 
 	return &ApkgMaker{
 		config:            config,
 		logger:            logger,
-		excelReader:       excelReader,
+		wordSource:        wordSource,
 		enrichmentService: enrichmentService,
 		jsonExporter:      jsonExporter,
+		closers:           closers,
+	}, nil
+}
+
+// Close releases the local dictionary sources
+func (a *ApkgMaker) Close() {
+	closeAll(a.closers, a.logger)
+}
+
+func closeAll(closers []io.Closer, logger *zap.Logger) {
+	for _, c := range closers {
+		if err := c.Close(); err != nil {
+			logger.Warn("Failed to close dictionary source", zap.Error(err))
+		}
+	}
+}
+
+// newDictionaryChain builds the configured dictionary providers in order. Online providers get their own
+// cache entries; local sources are read directly, so edits to them show up on the next run.
+// The returned closers must be closed when the chain is no longer used.
+//
+//nolint:gocyclo
+func newDictionaryChain(config *ApkgMakerConfig, enrichmentCache core.Cache, logger *zap.Logger) (*core.DictionaryChain, []io.Closer, error) {
+	names := config.Dictionaries
+	if len(names) == 0 {
+		names = []string{free_dictionary.ProviderName}
+	}
+
+	providers := make([]core.DictionaryProvider, 0, len(names))
+	var closers []io.Closer
+	for _, name := range names {
+		var provider core.DictionaryProvider
+		switch name {
+		case free_dictionary.ProviderName:
+			if config.Languages.Target != "en" {
+				closeAll(closers, logger)
+				return nil, nil, fmt.Errorf("dictionary %q only has English words, but the deck learns %q", name, config.Languages.Target)
+			}
+			dictionaryAPI := free_dictionary.NewAPI(logger)
+			configureAPI(dictionaryAPI, name, util.NewRateLimiter(config.DictionaryRate, time.Second), config)
+			provider = dictionaryAPI
+			if enrichmentCache != nil {
+				provider = core.NewCachedDictionary(provider, enrichmentCache, logger)
+			}
+		case dictionary.GlossaryProviderName:
+			if config.GlossaryFile == "" {
+				closeAll(closers, logger)
+				return nil, nil, fmt.Errorf("dictionary %q requires a glossary file", name)
+			}
+			glossary, err := dictionary.LoadGlossary(config.GlossaryFile, logger)
+			if err != nil {
+				closeAll(closers, logger)
+				return nil, nil, err
+			}
+			provider = glossary
+		case dictionary.WiktionaryProviderName:
+			wiktionary, err := dictionary.OpenWiktionary(config.WiktionaryDB, logger)
+			if err != nil {
+				closeAll(closers, logger)
+				return nil, nil, err
+			}
+			closers = append(closers, wiktionary)
+			if lang := wiktionary.Language(); lang != "" && lang != config.Languages.Target {
+				closeAll(closers, logger)
+				return nil, nil, fmt.Errorf("wiktionary store %s has %q words, but the deck learns %q (import it with --lang %s)",
+					config.WiktionaryDB, lang, config.Languages.Target, config.Languages.Target)
+			}
+			provider = wiktionary
+		default:
+			closeAll(closers, logger)
+			return nil, nil, fmt.Errorf("unknown dictionary provider %q", name)
+		}
+		providers = append(providers, provider)
+	}
+
+	return core.NewDictionaryChain(providers, logger), closers, nil
+}
+
+// newImageProvider builds the configured image provider; "none" returns nil and disables images
+func newImageProvider(config *ApkgMakerConfig, enrichmentCache core.Cache, logger *zap.Logger) (core.ImageProvider, error) {
+	// An hourly quota refills too slowly to wait for: once it is spent, cards are built without images
+	limiter := util.NewRateLimiter(config.ImageRate, time.Hour)
+	limiter.SetNoWait(true)
+
+	var provider core.ImageProvider
+	switch config.ImageProvider {
+	case "", unsplash.ProviderName:
+		imageAPI := unsplash.NewAPI(config.UnsplashKey, logger)
+		configureAPI(imageAPI, unsplash.ProviderName, limiter, config)
+		provider = imageAPI
+	case pixabay.ProviderName:
+		imageAPI := pixabay.NewAPI(config.PixabayKey, logger)
+		configureAPI(imageAPI, pixabay.ProviderName, limiter, config)
+		provider = imageAPI
+	case pexels.ProviderName:
+		imageAPI := pexels.NewAPI(config.PexelsKey, logger)
+		configureAPI(imageAPI, pexels.ProviderName, limiter, config)
+		provider = imageAPI
+	case images.LocalProviderName:
+		// Local files are cheap to look up and may change between runs, so they are not cached
+		folder, err := images.NewLocalFolder(config.ImageDir, logger)
+		if err != nil {
+			return nil, err
+		}
+		return folder, nil
+	case "none":
+		return nil, nil //nolint:nilnil
+	default:
+		return nil, fmt.Errorf("unknown image provider %q", config.ImageProvider)
+	}
+
+	if enrichmentCache != nil {
+		provider = core.NewCachedImageProvider(provider, enrichmentCache, logger)
+	}
+	return provider, nil
+}
+
+// onlineAPI is implemented by the clients of online dictionary and image services
+type onlineAPI interface {
+	SetRateLimiter(limiter *util.RateLimiter)
+	SetTransport(transport http.RoundTripper)
+	SetBaseURL(baseURL string)
+}
+
+// configureAPI applies the rate limit and the HTTP overrides of the config to the client of provider name
+func configureAPI(api onlineAPI, name string, limiter *util.RateLimiter, config *ApkgMakerConfig) {
+	api.SetRateLimiter(limiter)
+	api.SetTransport(config.HTTPTransport)
+	if baseURL := config.BaseURLs[name]; baseURL != "" {
+		api.SetBaseURL(baseURL)
 	}
 }
 
 // Run executes the complete flashcard generation process
 func (a *ApkgMaker) Run(ctx context.Context) error {
 	a.logger.Info("Starting Anki Flashcard Builder",
-		zap.String("excel_file", a.config.ExcelFile),
+		zap.String("input_file", a.config.InputFile),
+		zap.String("input_format", a.wordSource.Name()),
 		zap.String("output_file", a.config.OutputFile))
 
-	// Step 1: Validate Excel file
-	a.logger.Info("Step 1: Validating Excel file")
-	if err := a.excelReader.ValidateExcelFile(a.config.ExcelFile); err != nil {
-		return fmt.Errorf("Excel validation failed: %w", err) //nolint:stylecheck
+	// Step 1: Validate input file
+	a.logger.Info("Step 1: Validating input file")
+	if err := a.wordSource.Validate(a.config.InputFile); err != nil {
+		return fmt.Errorf("input validation failed: %w", err)
 	}
 
-	// Step 2: Read word pairs from Excel
-	a.logger.Info("Step 2: Reading word pairs from Excel")
-	rawFlashcards, err := a.excelReader.ReadWordPairs(a.config.ExcelFile)
+	// Step 2: Read word pairs from the input file
+	a.logger.Info("Step 2: Reading word pairs")
+	rawFlashcards, err := a.wordSource.ReadWordPairs(a.config.InputFile)
 	if err != nil {
-		return fmt.Errorf("failed to read Excel file: %w", err)
+		return fmt.Errorf("failed to read input file: %w", err)
 	}
 
 	if len(rawFlashcards) == 0 {
-		return fmt.Errorf("no word pairs found in Excel file")
+		return fmt.Errorf("no word pairs found in input file")
 	}
 
 	// Step 3: Enrich flashcards with progress bar
@@ -89,7 +286,7 @@ func (a *ApkgMaker) Run(ctx context.Context) error {
 	if a.config.ProgressBar {
 		enrichedFlashcards, err = a.enrichWithProgress(ctx, rawFlashcards)
 	} else {
-		enrichedFlashcards, err = a.enrichmentService.EnrichFlashcards(ctx, rawFlashcards)
+		enrichedFlashcards, err = a.enrichmentService.EnrichFlashcards(ctx, rawFlashcards, a.config.Concurrency, nil)
 	}
 
 	if err != nil {
@@ -105,7 +302,12 @@ func (a *ApkgMaker) Run(ctx context.Context) error {
 
 	// Step 5: Generate Anki package
 	a.logger.Info("Step 5: Generating Anki package")
-	if err := a.generateAnkiPackage(jsonPath, a.config.OutputFile); err != nil {
+	if a.config.PythonGenanki {
+		err = a.generateAnkiPackageWithPython(jsonPath, a.config.OutputFile)
+	} else {
+		err = a.generateAnkiPackage(ctx, jsonPath, a.config.OutputFile)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to generate Anki package: %w", err)
 	}
 
@@ -127,8 +329,6 @@ func (a *ApkgMaker) Run(ctx context.Context) error {
 
 // enrichWithProgress enriches flashcards with a progress bar
 func (a *ApkgMaker) enrichWithProgress(ctx context.Context, rawFlashcards []*core.RawFlashcard) ([]*core.Flashcard, error) {
-	enriched := make([]*core.Flashcard, 0, len(rawFlashcards))
-
 	bar := progressbar.NewOptions(len(rawFlashcards),
 		progressbar.OptionEnableColorCodes(true),
 		progressbar.OptionShowBytes(false),
@@ -143,37 +343,79 @@ func (a *ApkgMaker) enrichWithProgress(ctx context.Context, rawFlashcards []*cor
 		}),
 	)
 
-	for i, raw := range rawFlashcards {
-		select {
-		case <-ctx.Done():
-			return enriched, ctx.Err()
-		default:
+	return a.enrichmentService.EnrichFlashcards(ctx, rawFlashcards, a.config.Concurrency, func() {
+		if err := bar.Add(1); err != nil {
+			a.logger.Warn("Failed to update progress bar", zap.Error(err))
+		}
+	})
+}
+
+// generateAnkiPackage builds the Anki package natively from the enriched JSON
+func (a *ApkgMaker) generateAnkiPackage(ctx context.Context, jsonPath, outputFile string) error {
+	flashcards, err := a.jsonExporter.LoadFlashcards(jsonPath)
+	if err != nil {
+		return err
+	}
+
+	deck := anki.NewVocabularyDeck(a.config.DeckName, a.config.Languages)
+	pkg := anki.NewPackage(a.logger)
+	pkg.AddDeck(deck)
+	// Flashcards with a deck column go to subdecks of the deck
+	subdecks := make(map[string]*anki.Deck)
+	deckFor := func(name string) *anki.Deck {
+		if name == "" {
+			return deck
 		}
+		subdeck, ok := subdecks[name]
+		if !ok {
+			subdeck = anki.NewVocabularyDeck(a.config.DeckName+"::"+name, a.config.Languages)
+			subdecks[name] = subdeck
+			pkg.AddDeck(subdeck)
+		}
+		return subdeck
+	}
 
-		flashcard, err := a.enrichmentService.EnrichFlashcard(ctx, raw, i+1)
-		if err != nil {
-			a.logger.Error("Failed to enrich flashcard", zap.String("english", raw.English), zap.Error(err))
-			// Create basic flashcard without enrichment
-			flashcard = &core.Flashcard{
-				ID:        i + 1,
-				Russian:   raw.Russian,
-				English:   raw.English,
-				CreatedAt: time.Now(),
-				UpdatedAt: time.Now(),
-			}
+	seenGUIDs := make(map[string]bool)
+	seenMedia := make(map[string]bool)
+	notes := 0
+	for _, flashcard := range flashcards {
+		if flashcard.GUID != "" && seenGUIDs[flashcard.GUID] {
+			a.logger.Warn("Skipping duplicate flashcard",
+				zap.String("target", flashcard.Target),
+				zap.String("source", flashcard.Source),
+				zap.String("part_of_speech", flashcard.PartOfSpeech))
+			continue
 		}
+		seenGUIDs[flashcard.GUID] = true
+		deckFor(flashcard.Deck).AddNote(anki.VocabularyNote(flashcard))
+		notes++
 
-		enriched = append(enriched, flashcard)
-		if err := bar.Add(1); err != nil {
-			a.logger.Warn("Failed to update progress bar", zap.Error(err))
+		for _, name := range anki.MediaFiles(flashcard) {
+			if seenMedia[name] {
+				continue
+			}
+			seenMedia[name] = true
+			path := filepath.Join(a.config.MediaDir, name)
+			if _, err := os.Stat(path); err != nil {
+				a.logger.Warn("Media file referenced by flashcard is missing", zap.String("file", path))
+				continue
+			}
+			pkg.AddMediaFile(path)
 		}
 	}
 
-	return enriched, nil
+	a.logger.Info("Writing Anki package",
+		zap.String("output", outputFile),
+		zap.String("deck_name", a.config.DeckName),
+		zap.Int("subdecks", len(subdecks)),
+		zap.Int("notes", notes),
+		zap.Int("media", len(pkg.MediaFiles)))
+
+	return pkg.WriteToFile(ctx, outputFile)
 }
 
-// generateAnkiPackage calls the Python script to generate the Anki package
-func (a *ApkgMaker) generateAnkiPackage(jsonPath, outputFile string) error {
+// generateAnkiPackageWithPython calls the Python script to generate the Anki package
+func (a *ApkgMaker) generateAnkiPackageWithPython(jsonPath, outputFile string) error {
 	scriptPath := "scripts/make_apkg.py"
 
 	// Check if Python script exists
@@ -187,8 +429,11 @@ func (a *ApkgMaker) generateAnkiPackage(jsonPath, outputFile string) error {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
+	deckID := strconv.FormatInt(anki.DeckID(a.config.DeckName), 10)
+	modelID := strconv.FormatInt(anki.VocabularyModel(a.config.Languages).ID, 10)
 	//nolint:gosec // Acceptable risk: controlled input for exec.Command
-	cmd := exec.Command("./venv/bin/python", scriptPath, jsonPath, a.config.MediaDir, outputFile, a.config.DeckName)
+	cmd := exec.Command("./venv/bin/python", scriptPath, jsonPath, a.config.MediaDir, outputFile, a.config.DeckName, deckID, modelID,
+		a.config.Languages.Source, a.config.Languages.Target)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
@@ -197,7 +442,10 @@ func (a *ApkgMaker) generateAnkiPackage(jsonPath, outputFile string) error {
 		zap.String("json", jsonPath),
 		zap.String("media", a.config.MediaDir),
 		zap.String("output", outputFile),
-		zap.String("deck_name", a.config.DeckName))
+		zap.String("deck_name", a.config.DeckName),
+		zap.String("deck_id", deckID),
+		zap.String("model_id", modelID),
+		zap.Stringer("languages", a.config.Languages))
 
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("Python script failed: %w", err) //nolint:stylecheck
@@ -0,0 +1,163 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/anki"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/replay"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/storage"
+	free_dictionary "github.com/commedesvlados/anki-flashcards-autogen-cli/pkg/clients/free-dictionary"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/pkg/clients/unsplash"
+
+	"go.uber.org/zap"
+)
+
+// TestApkgMaker_Run runs the whole pipeline against a fake server that replays testdata/cassettes. To record
+// the cassette again against the real services, run it with REPLAY_RECORD=1 and UNSPLASH_API_KEY set.
+func TestApkgMaker_Run(t *testing.T) {
+	cassettePath := filepath.Join("testdata", "cassettes", "apkg_maker.json")
+	dir := t.TempDir()
+	input := filepath.Join(dir, "words.csv")
+	if err := os.WriteFile(input, []byte("Russian,English,Part of Speech\nяблоко,apple,noun\nрыба,glorpfish,\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config := &ApkgMakerConfig{
+		InputFile:   input,
+		OutputFile:  filepath.Join(dir, "out", "deck.apkg"),
+		DeckName:    "Test Deck",
+		Languages:   core.DefaultLanguagePair,
+		UnsplashKey: os.Getenv("UNSPLASH_API_KEY"),
+		MediaDir:    filepath.Join(dir, "media"),
+		EnrichedDir: filepath.Join(dir, "enriched"),
+		NoCache:     true,
+		Concurrency: 2,
+	}
+
+	if replay.Recording() {
+		cassette := &replay.Cassette{}
+		config.HTTPTransport = replay.NewRecorder(cassette, nil)
+		t.Cleanup(func() {
+			if err := cassette.Save(cassettePath); err != nil {
+				t.Error(err)
+			}
+		})
+	} else {
+		replayCassette(t, cassettePath, config)
+	}
+
+	maker, err := NewApkgMaker(config, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewApkgMaker: %v", err)
+	}
+	defer maker.Close()
+	if err := maker.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	flashcards, err := storage.NewJSONExporter(zap.NewNop()).LoadFlashcards(filepath.Join(config.EnrichedDir, "enriched.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flashcards) != 2 {
+		t.Fatalf("got %d flashcards, want 2", len(flashcards))
+	}
+	apple, unknown := flashcards[0], flashcards[1]
+	if apple.Definition == "" || apple.IPAUK == "" || apple.AudioUK == "" || apple.AudioUS == "" || apple.ImagePath == "" {
+		t.Errorf("apple is not fully enriched: %+v", apple)
+	}
+	if !slices.Contains(apple.Synonyms, "pome") {
+		t.Errorf("apple synonyms %q, want pome", apple.Synonyms)
+	}
+	if unknown.Definition != "" || unknown.ImagePath != "" || unknown.AudioUK != "" {
+		t.Errorf("a word missing from the dictionary keeps only sheet data: %+v", unknown)
+	}
+	image, err := os.ReadFile(filepath.Join(config.MediaDir, apple.ImagePath))
+	if err != nil || !bytes.HasPrefix(image, []byte{0xff, 0xd8}) {
+		t.Errorf("image %s is not the downloaded JPEG: %v", apple.ImagePath, err)
+	}
+
+	words, err := anki.ReadWords(context.Background(), config.OutputFile, []string{"EN"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(words, []string{"apple", "glorpfish"}) {
+		t.Errorf("package has notes %q", words)
+	}
+}
+
+// TestApkgMaker_Run_ImageQuota runs out of image searches mid-run: the deck is still built, and the cards
+// after the quota was spent have no image
+func TestApkgMaker_Run_ImageQuota(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "words.csv")
+	if err := os.WriteFile(input, []byte("Russian,English,Part of Speech\nяблоко,apple,noun\nяблоня,apple,noun\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config := &ApkgMakerConfig{
+		InputFile:   input,
+		OutputFile:  filepath.Join(dir, "out", "deck.apkg"),
+		DeckName:    "Test Deck",
+		Languages:   core.DefaultLanguagePair,
+		MediaDir:    filepath.Join(dir, "media"),
+		EnrichedDir: filepath.Join(dir, "enriched"),
+		NoCache:     true,
+		Concurrency: 1,
+		ImageRate:   1, // one search per hour
+	}
+	replayCassette(t, filepath.Join("testdata", "cassettes", "apkg_maker.json"), config)
+
+	maker, err := NewApkgMaker(config, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewApkgMaker: %v", err)
+	}
+	defer maker.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := maker.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	flashcards, err := storage.NewJSONExporter(zap.NewNop()).LoadFlashcards(filepath.Join(config.EnrichedDir, "enriched.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flashcards) != 2 || flashcards[0].ImagePath == "" || flashcards[1].ImagePath != "" {
+		t.Fatalf("want an image on the first flashcard only: %+v", flashcards)
+	}
+	if flashcards[1].Definition == "" || flashcards[1].AudioUK == "" {
+		t.Errorf("the flashcard without an image should still be enriched: %+v", flashcards[1])
+	}
+	if _, err := os.Stat(config.OutputFile); err != nil {
+		t.Errorf("deck was not written: %v", err)
+	}
+}
+
+// replayCassette points the online providers of config at a fake server answering from the cassette, and
+// fails the test if a request is missing from it
+func replayCassette(t *testing.T, path string, config *ApkgMakerConfig) {
+	t.Helper()
+	cassette, err := replay.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := replay.NewServer(cassette)
+	t.Cleanup(func() {
+		server.Close()
+		if missing := server.Missing(); len(missing) > 0 {
+			t.Errorf("requests missing from %s: %q", path, missing)
+		}
+	})
+	// API calls go to the server through the base URLs, media downloads through the transport
+	config.BaseURLs = map[string]string{
+		free_dictionary.ProviderName: server.URL + "/api/v2/entries/en",
+		unsplash.ProviderName:        server.URL + "/search/photos",
+	}
+	config.HTTPTransport = server.Transport()
+}
@@ -0,0 +1,148 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/excel"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/highlights"
+
+	"go.uber.org/zap"
+)
+
+// highlightFormats maps file extensions to highlight source names
+var highlightFormats = map[string]string{
+	".epub":               highlights.KOReaderSourceName, // the book next to its .sdr folder
+	".sdr":                highlights.KOReaderSourceName,
+	".lua":                highlights.KOReaderSourceName,
+	".calibre_highlights": highlights.CalibreSourceName,
+	".json":               highlights.CalibreSourceName,
+	".sqlite":             highlights.AppleBooksSourceName,
+	".mrexpt":             highlights.MoonReaderSourceName,
+}
+
+// HighlightExtractorConfig holds CLI flag values for the e-book highlight import
+type HighlightExtractorConfig struct {
+	HighlightsPath string
+	Format         string // auto, koreader, calibre, apple-books or moon-reader
+	// EPUBPath is the book the highlights were made in, read for sentences and chapters.
+	// KOReader books next to their .sdr folder are found automatically.
+	EPUBPath  string
+	Title     string // replaces the book title (and tag) stored with the highlights
+	SheetPath string
+	Languages core.LanguagePair
+	Books     []string // only highlights from books whose title contains one of these
+	MaxWords  int      // longer highlights are skipped; 0 keeps all
+	TranslationConfig
+}
+
+// HighlightExtractor turns e-book reader highlights into the word sheet read by make-apkg
+type HighlightExtractor struct {
+	config *HighlightExtractorConfig
+	logger *zap.Logger
+}
+
+// NewHighlightExtractor creates a new HighlightExtractor instance
+func NewHighlightExtractor(config *HighlightExtractorConfig, logger *zap.Logger) *HighlightExtractor {
+	return &HighlightExtractor{
+		config: config,
+		logger: logger,
+	}
+}
+
+// Run reads the highlights, adds the EPUB context, translates the words and writes the sheet
+func (h *HighlightExtractor) Run(ctx context.Context) error {
+	source, err := newHighlightSource(h.config, h.logger)
+	if err != nil {
+		return err
+	}
+	marks, err := source.ReadHighlights(h.config.HighlightsPath)
+	if err != nil {
+		return err
+	}
+
+	if epubPath := h.epubPath(source); epubPath != "" {
+		book, err := highlights.OpenEPUB(epubPath, h.logger)
+		if err != nil {
+			return err
+		}
+		book.AddContext(marks)
+	}
+	if h.config.Title != "" {
+		for _, mark := range marks {
+			mark.Book = h.config.Title
+		}
+	}
+
+	marks = highlights.FilterBooks(marks, h.config.Books)
+	words, skipped := highlights.ExtractedWords(marks, h.config.MaxWords)
+	if skipped > 0 {
+		h.logger.Info("Skipped long highlights", zap.Int("count", skipped), zap.Int("max_words", h.config.MaxWords))
+	}
+	if len(words) == 0 {
+		return fmt.Errorf("no highlighted words found in %s", h.config.HighlightsPath)
+	}
+	h.logger.Info("Collected highlighted words", zap.Int("highlights", len(marks)), zap.Int("words", len(words)))
+
+	translator, closers, err := newTranslator(&h.config.TranslationConfig, h.config.Languages, h.logger)
+	if err != nil {
+		return err
+	}
+	defer closeAll(closers, h.logger)
+	if err := translateWords(ctx, translator, words, h.config.MinConfidence, h.logger); err != nil {
+		return err
+	}
+
+	if err := excel.NewWriter(h.logger).WriteExtractedWords(words, h.config.Languages, h.config.SheetPath); err != nil {
+		return err
+	}
+	h.logger.Info("Wrote words to Excel", zap.String("output", h.config.SheetPath))
+	return nil
+}
+
+// epubPath returns the book to read context from: EPUBPath, or the book next to a KOReader sidecar
+func (h *HighlightExtractor) epubPath(source core.HighlightSource) string {
+	if h.config.EPUBPath != "" || source.Name() != highlights.KOReaderSourceName {
+		return h.config.EPUBPath
+	}
+	if strings.EqualFold(filepath.Ext(h.config.HighlightsPath), ".epub") {
+		return h.config.HighlightsPath
+	}
+	metadataPath, err := highlights.KOReaderMetadata(h.config.HighlightsPath)
+	if err != nil {
+		return ""
+	}
+	book := highlights.KOReaderBook(metadataPath)
+	if book != "" && !strings.EqualFold(filepath.Ext(book), ".epub") {
+		return ""
+	}
+	return book
+}
+
+// newHighlightSource returns the reader for the highlights file, chosen by Format or the file extension
+func newHighlightSource(config *HighlightExtractorConfig, logger *zap.Logger) (core.HighlightSource, error) {
+	format := config.Format
+	if format == "" || format == "auto" {
+		var ok bool
+		format, ok = highlightFormats[strings.ToLower(filepath.Ext(config.HighlightsPath))]
+		if !ok {
+			return nil, fmt.Errorf("cannot tell the format of %q from its extension, set the format (koreader, calibre, apple-books, moon-reader)", config.HighlightsPath) //nolint:lll
+		}
+	}
+
+	switch format {
+	case highlights.KOReaderSourceName:
+		return highlights.NewKOReader(logger), nil
+	case highlights.CalibreSourceName:
+		return highlights.NewCalibre(logger), nil
+	case highlights.AppleBooksSourceName:
+		return highlights.NewAppleBooks(logger), nil
+	case highlights.MoonReaderSourceName:
+		return highlights.NewMoonReader(logger), nil
+	default:
+		return nil, fmt.Errorf("unknown highlights format %q", format)
+	}
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/excel"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/kindle"
+
+	"go.uber.org/zap"
+)
+
+// KindleImporterConfig holds CLI flag values for the Kindle vocabulary import
+type KindleImporterConfig struct {
+	VocabPath string // vocab.db of the Kindle Vocabulary Builder
+	SheetPath string
+	Languages core.LanguagePair // only books in the target language are imported
+	// Lookup filters: book title substrings and a time range (zero = unbounded, Until is exclusive)
+	Books           []string
+	Since, Until    time.Time
+	IncludeMastered bool
+	UseStems        bool // write the dictionary form ("run") instead of the looked-up form ("running")
+	TranslationConfig
+}
+
+// KindleImporter turns Kindle lookups into the word sheet read by make-apkg
+type KindleImporter struct {
+	config *KindleImporterConfig
+	logger *zap.Logger
+}
+
+// NewKindleImporter creates a new KindleImporter instance
+func NewKindleImporter(config *KindleImporterConfig, logger *zap.Logger) *KindleImporter {
+	return &KindleImporter{
+		config: config,
+		logger: logger,
+	}
+}
+
+// Run reads the lookups, translates the words and writes the sheet
+func (k *KindleImporter) Run(ctx context.Context) error {
+	vocab, err := kindle.OpenVocab(k.config.VocabPath, k.logger)
+	if err != nil {
+		return err
+	}
+	defer vocab.Close()
+
+	lookups, err := vocab.Lookups(ctx, &kindle.Filter{
+		Books:           k.config.Books,
+		Since:           k.config.Since,
+		Until:           k.config.Until,
+		Language:        k.config.Languages.Target,
+		IncludeMastered: k.config.IncludeMastered,
+	})
+	if err != nil {
+		return err
+	}
+	if len(lookups) == 0 {
+		return fmt.Errorf("no lookups match the filters in %s", k.config.VocabPath)
+	}
+	words := kindle.ExtractedWords(lookups, k.config.UseStems)
+	k.logger.Info("Collected Kindle words", zap.Int("lookups", len(lookups)), zap.Int("words", len(words)))
+
+	translator, closers, err := newTranslator(&k.config.TranslationConfig, k.config.Languages, k.logger)
+	if err != nil {
+		return err
+	}
+	defer closeAll(closers, k.logger)
+	if err := translateWords(ctx, translator, words, k.config.MinConfidence, k.logger); err != nil {
+		return err
+	}
+
+	if err := excel.NewWriter(k.logger).WriteExtractedWords(words, k.config.Languages, k.config.SheetPath); err != nil {
+		return err
+	}
+	k.logger.Info("Wrote words to Excel", zap.String("output", k.config.SheetPath))
+	return nil
+}
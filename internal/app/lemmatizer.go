@@ -0,0 +1,28 @@
+package app
+
+import (
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/lexicon"
+
+	"go.uber.org/zap"
+)
+
+// newLemmatizer returns the lemma list, the built-in English rules or no lemmatizer for a language.
+// frequency may be nil; it helps the English rules pick between candidates.
+func newLemmatizer(language, lemmaList string, noLemmas bool, frequency lexicon.Frequency, logger *zap.Logger) (core.Lemmatizer, error) { //nolint:lll
+	if noLemmas {
+		return core.IdentityLemmatizer{}, nil
+	}
+	var rules core.Lemmatizer = core.IdentityLemmatizer{}
+	if language == "en" {
+		rules = lexicon.NewEnglish(frequency)
+	}
+	if lemmaList != "" {
+		return lexicon.ReadLemmaList(lemmaList, rules)
+	}
+	if language != "en" {
+		logger.Warn("No built-in lemmatizer for the language, words are kept as written. Use --lemma-list.",
+			zap.String("language", language))
+	}
+	return rules, nil
+}
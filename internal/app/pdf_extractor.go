@@ -1,16 +1,19 @@
 package app
 
 import (
+	"context"
 	"fmt"
-	"os"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"time"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/excel"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/lexicon"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/pdf"
 
-	"github.com/unidoc/unipdf/v4/common/license"
-	"github.com/unidoc/unipdf/v4/core"
-	"github.com/unidoc/unipdf/v4/extractor"
-	"github.com/unidoc/unipdf/v4/model"
-	"github.com/xuri/excelize/v2"
 	"go.uber.org/zap"
 )
 
@@ -19,8 +22,19 @@ import (
 type PDFExtractorConfig struct {
 	ProgressBar  bool
 	UniPDFAPIKey string
+	Backend      string // PDFBackendAuto or the name of a backend
 	PDFPath      string
 	SheetPath    string
+	Merge        bool              // add new words to an existing SheetPath instead of overwriting it
+	HighlightNew bool              // fill the rows added by Merge
+	Book         string            // Book column of merged rows; empty uses the PDF file name
+	Languages    core.LanguagePair // the book is in the target language
+	LemmaList    string            // "lemma<TAB>form" lines, for languages without built-in rules
+	NoLemmas     bool
+	// FrequencyList ranks words from the most common; it helps the English rules pick the dictionary form
+	FrequencyList string
+	Annotations   AnnotationOptions
+	TranslationConfig
 }
 
 type PDFExtractor struct {
@@ -36,130 +50,154 @@ func NewPDFExtractor(config *PDFExtractorConfig, logger *zap.Logger) *PDFExtract
 	}
 }
 
-// Run executes the PDF extraction and Excel writing
-func (e *PDFExtractor) Run() error {
-	if err := license.SetMeteredKey(e.config.UniPDFAPIKey); err != nil {
-		e.logger.Fatal("License error", zap.Error(err))
+// PDFBackendAuto uses UniPDF when a license key is given and the pure-Go backend otherwise
+const PDFBackendAuto = "auto"
+
+// Run executes the PDF extraction, translation and Excel writing
+func (e *PDFExtractor) Run(ctx context.Context) error {
+	translator, closers, err := newTranslator(&e.config.TranslationConfig, e.config.Languages, e.logger)
+	if err != nil {
+		return err
 	}
+	defer closeAll(closers, e.logger)
 
-	reader := OpenPDF(e.config.PDFPath, e.logger)
-	words := ExtractAnnotatedWords(reader, e.logger)
-	WriteWordsToExcel(words, e.config.SheetPath, e.logger)
-	e.logger.Info("Wrote words to Excel", zap.String("output", e.config.SheetPath))
-	return nil
-}
+	var frequency lexicon.Frequency
+	if e.config.FrequencyList != "" {
+		if frequency, err = lexicon.ReadFrequencyList(e.config.FrequencyList); err != nil {
+			return err
+		}
+		e.logger.Info("Read frequency list", zap.Int("words", len(frequency)))
+	}
+
+	lemmatizer, err := newLemmatizer(e.config.Languages.Target, e.config.LemmaList, e.config.NoLemmas, frequency, e.logger)
+	if err != nil {
+		return err
+	}
 
-// OpenPDF opens and parses the PDF, returns a PdfReader
-func OpenPDF(filename string, logger *zap.Logger) *model.PdfReader {
-	f, err := os.Open(filename)
+	backend, err := newPDFBackend(e.config, e.logger)
 	if err != nil {
-		logger.Fatal("Error opening PDF", zap.Error(err))
+		return err
 	}
-	reader, err := model.NewPdfReader(f)
+	doc, err := backend.Open(e.config.PDFPath)
 	if err != nil {
-		logger.Fatal("Error creating reader", zap.Error(err))
+		return err
 	}
-	return reader
-}
+	defer doc.Close()
+	e.logger.Info("Opened PDF", zap.String("path", e.config.PDFPath), zap.String("backend", backend.Name()))
 
-// ExtractAnnotatedWords extracts all unique words from highlight/underline annotations
-func ExtractAnnotatedWords(reader *model.PdfReader, logger *zap.Logger) []string { //nolint:gocyclo
-	numPages, err := reader.GetNumPages()
+	highlighted, err := ExtractAnnotatedWords(doc, &e.config.Annotations, e.logger)
 	if err != nil {
-		logger.Fatal("Error getting page count", zap.Error(err))
+		return err
+	}
+	words := core.NormalizeWords(highlighted, lemmatizer)
+	sort.Slice(words, func(i, j int) bool { return words[i].Target < words[j].Target })
+	e.logger.Info("Normalized words", zap.Int("highlighted", len(highlighted)), zap.Int("words", len(words)))
+	if err := translateWords(ctx, translator, words, e.config.MinConfidence, e.logger); err != nil {
+		return err
+	}
+
+	writer := excel.NewWriter(e.logger)
+	if e.config.Merge {
+		book := e.config.Book
+		if book == "" {
+			book = strings.TrimSuffix(filepath.Base(e.config.PDFPath), filepath.Ext(e.config.PDFPath))
+		}
+		opts := &excel.MergeOptions{Book: book, Added: time.Now(), Highlight: e.config.HighlightNew, Lemmatizer: lemmatizer}
+		if err := writer.MergeExtractedWords(words, e.config.Languages, e.config.SheetPath, opts); err != nil {
+			return err
+		}
+	} else if err := writer.WriteExtractedWords(words, e.config.Languages, e.config.SheetPath); err != nil {
+		return err
+	}
+	e.logger.Info("Wrote words to Excel", zap.String("output", e.config.SheetPath))
+	return nil
+}
+
+// newPDFBackend returns the backend selected by --pdf-backend
+func newPDFBackend(config *PDFExtractorConfig, logger *zap.Logger) (core.PDFBackend, error) {
+	switch config.Backend {
+	case "", PDFBackendAuto:
+		if config.UniPDFAPIKey != "" {
+			return pdf.NewUniPDF(config.UniPDFAPIKey, logger), nil
+		}
+		return pdf.NewNative(), nil
+	case pdf.UniPDFBackendName:
+		if config.UniPDFAPIKey == "" {
+			return nil, fmt.Errorf("the %s backend needs a license key (--uni-api-key)", pdf.UniPDFBackendName)
+		}
+		return pdf.NewUniPDF(config.UniPDFAPIKey, logger), nil
+	case pdf.NativeBackendName:
+		return pdf.NewNative(), nil
+	default:
+		return nil, fmt.Errorf("unknown PDF backend %q: use %s, %s or %s",
+			config.Backend, PDFBackendAuto, pdf.UniPDFBackendName, pdf.NativeBackendName)
 	}
+}
+
+// ExtractAnnotatedWords extracts the unique text of the annotations selected by opts as written, in reading
+// order, each with the sentence and page of its first occurrence. nil opts selects highlights and underlines.
+func ExtractAnnotatedWords(doc core.PDFDocument, opts *AnnotationOptions, logger *zap.Logger) ([]*core.ExtractedWord, error) { //nolint:lll
+	if opts == nil {
+		opts = &AnnotationOptions{}
+	}
+	numPages := doc.NumPages()
 	logger.Info("Total pages", zap.Int("pages", numPages))
-	wordsSet := make(map[string]struct{})
+	var words []*core.ExtractedWord
+	wordsSet := make(map[string]*core.ExtractedWord)
+	addWord := func(word, sentence string, page int, tag, deck string) {
+		key := core.NormalizeText(word)
+		if key == "" {
+			return
+		}
+		extracted, ok := wordsSet[key]
+		if !ok {
+			extracted = &core.ExtractedWord{Target: word, Example: sentence, Page: page, Deck: deck}
+			wordsSet[key] = extracted
+			words = append(words, extracted)
+		}
+		if tag = core.TagName(tag); tag != "" && !slices.Contains(extracted.Tags, tag) {
+			extracted.Tags = append(extracted.Tags, tag)
+		}
+	}
 
 	for i := 1; i <= numPages; i++ {
-		logger.Info("Scanning page", zap.Int("page", i))
-		page, err := reader.GetPage(i)
-		if err != nil {
-			logger.Fatal("Error loading page", zap.Int("page", i), zap.Error(err))
+		if !opts.Pages.Contains(i) {
+			continue
 		}
-		annots, err := page.GetAnnotations()
+		logger.Info("Scanning page", zap.Int("page", i))
+		annotations, err := doc.Annotations(i)
 		if err != nil {
-			logger.Info("No annotations found on page", zap.Int("page", i), zap.Error(err))
+			logger.Warn("Failed to read annotations", zap.Int("page", i), zap.Error(err))
 			continue
 		}
-		logger.Info("Found annotations", zap.Int("page", i), zap.Int("count", len(annots)))
-		for idx, a := range annots {
-			dictObj := a.GetContainingPdfObject()
-			dict, ok := core.GetDict(dictObj)
-			if !ok {
-				logger.Warn("Annotation missing dictionary", zap.Int("page", i), zap.Int("annotation", idx+1))
-				continue
-			}
-			nameObj, hasSubtype := core.GetName(dict.Get("Subtype"))
-			if !hasSubtype {
-				logger.Warn("Annotation missing subtype", zap.Int("page", i), zap.Int("annotation", idx+1))
-				continue
-			}
-			annotationType := *nameObj
-			content := "EMPTY"
-			if a.Contents != nil {
-				content = a.Contents.String()
+		logger.Info("Found annotations", zap.Int("page", i), zap.Int("count", len(annotations)))
+
+		// The page text is extracted once, for the first annotation that needs it
+		var text *core.PageText
+		getText := func() *core.PageText {
+			if text == nil {
+				if text, err = doc.Text(i); err != nil {
+					logger.Warn("Failed to extract page text", zap.Int("page", i), zap.Error(err))
+					text = &core.PageText{}
+				}
 			}
-			if annotationType == "Highlight" || annotationType == "Underline" {
-				if content != "EMPTY" {
-					wordsSet[content] = struct{}{}
+			return text
+		}
+
+		for idx, annotation := range annotations {
+			if opts.keepsType(annotation.Subtype) && opts.keeps(annotation) {
+				tag, deck := opts.labels(annotation)
+				if annotation.Contents != "" {
+					sentence := core.JoinHyphenated(core.FindSentence(getText().Text, annotation.Contents))
+					addWord(annotation.Contents, sentence, i, tag, deck)
 				} else {
-					rectObj := dict.Get("Rect")
-					if rectObj != nil {
-						arr, ok := rectObj.(*core.PdfObjectArray)
-						if ok && len(arr.Elements()) == 4 {
-							x1, _ := core.GetNumberAsFloat(arr.Elements()[0])
-							y1, _ := core.GetNumberAsFloat(arr.Elements()[1])
-							x2, _ := core.GetNumberAsFloat(arr.Elements()[2])
-							y2, _ := core.GetNumberAsFloat(arr.Elements()[3])
-							rect := struct{ X1, Y1, X2, Y2 float64 }{x1, y1, x2, y2}
-							ext, err := extractor.New(page)
-							if err == nil {
-								pageText, _, _, err := ext.ExtractPageText()
-								if err == nil {
-									var extracted string
-									marks := pageText.Marks()
-									for _, mark := range marks.Elements() { //nolint:gocritic
-										mb := mark.BBox
-										if mb.Llx < rect.X2 && mb.Urx > rect.X1 && mb.Lly < rect.Y2 && mb.Ury > rect.Y1 {
-											extracted += mark.Text
-										}
-									}
-									if len(extracted) > 0 { //nolint:gocritic
-										wordsSet[extracted] = struct{}{}
-									}
-								}
-							}
-						}
-					}
+					extracted, sentence := highlightedText(getText(), annotationQuads(annotation))
+					addWord(extracted, sentence, i, tag, deck)
 				}
 			}
 			logger.Info("Annotation", zap.Int("page", i), zap.Int("annotation", idx+1),
-				zap.String("type", annotationType.String()), zap.String("word", content))
+				zap.String("type", annotation.Subtype), zap.String("word", annotation.Contents))
 		}
 	}
-	words := make([]string, 0, len(wordsSet))
-	for w := range wordsSet {
-		words = append(words, w)
-	}
-	sort.Strings(words)
-	return words
-}
-
-// WriteWordsToExcel writes the words to an .xlsx file in column B with headers
-func WriteWordsToExcel(words []string, filename string, logger *zap.Logger) {
-	fexcel := excelize.NewFile()
-	sheet := "WordsSheet1"
-	fexcel.SetSheetName(fexcel.GetSheetName(0), sheet) //nolint:errcheck
-	fexcel.SetCellValue(sheet, "A1", "Russian")        //nolint:errcheck
-	fexcel.SetCellValue(sheet, "B1", "English")        //nolint:errcheck
-	fexcel.SetCellValue(sheet, "C1", "PartOfSpeech")   //nolint:errcheck
-	for i, w := range words {
-		cell := fmt.Sprintf("B%d", i+2)
-		fexcel.SetCellValue(sheet, cell, strings.ToLower(w)) //nolint:errcheck
-	}
-	err := fexcel.SaveAs(filename)
-	if err != nil {
-		logger.Fatal("Error saving Excel file", zap.Error(err))
-	}
+	return words, nil
 }
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/lexicon"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/pdf"
+
+	"go.uber.org/zap"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the PDF tests")
+
+// TestExtractAnnotatedWords_Golden extracts the highlights of testdata/pdf/*.pdf (written by testdata/pdf/gen)
+// with every backend and compares the normalized words with the .golden file next to each PDF. UniPDF needs a
+// license to extract text, so it is only tested when UNIPDF_API_KEY is set.
+func TestExtractAnnotatedWords_Golden(t *testing.T) {
+	backends := []core.PDFBackend{pdf.NewNative()}
+	if key := os.Getenv("UNIPDF_API_KEY"); key != "" {
+		backends = append(backends, pdf.NewUniPDF(key, zap.NewNop()))
+	}
+
+	paths, err := filepath.Glob(filepath.Join("testdata", "pdf", "*.pdf"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no test PDFs: %v", err)
+	}
+	for _, backend := range backends {
+		for _, path := range paths {
+			t.Run(backend.Name()+"/"+filepath.Base(path), func(t *testing.T) {
+				doc, err := backend.Open(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer doc.Close()
+				highlighted, err := ExtractAnnotatedWords(doc, nil, zap.NewNop())
+				if err != nil {
+					t.Fatal(err)
+				}
+				words := core.NormalizeWords(highlighted, lexicon.NewEnglish(nil))
+
+				var b strings.Builder
+				for _, word := range words {
+					b.WriteString(strings.Join([]string{
+						word.Target, strings.Join(word.Forms, ", "), strconv.Itoa(word.Page), word.Example,
+					}, "\t") + "\n")
+				}
+
+				goldenPath := strings.TrimSuffix(path, ".pdf") + ".golden"
+				if *update {
+					if err := os.WriteFile(goldenPath, []byte(b.String()), 0o600); err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+				want, err := os.ReadFile(goldenPath)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if b.String() != string(want) {
+					t.Errorf("words of %s:\n%s\nwant:\n%s", path, b.String(), want)
+				}
+			})
+		}
+	}
+}
+
+// TestExtractAnnotatedWords_NoWidths documents what the native backend cannot read: text in a standard font
+// written without /Widths runs together, so a highlight finds no word. UniPDF knows the standard metrics.
+func TestExtractAnnotatedWords_NoWidths(t *testing.T) {
+	path := filepath.Join("testdata", "pdf", "limits", "no-widths.pdf")
+
+	doc, err := pdf.NewNative().Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer doc.Close()
+	text, err := doc.Text(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(text.Text, " ") {
+		t.Errorf("native text %q is split into words; update the limitation in the README and the Native doc", text.Text)
+	}
+	highlighted, err := ExtractAnnotatedWords(doc, nil, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(highlighted) != 0 {
+		t.Errorf("native found %d highlighted words, want none", len(highlighted))
+	}
+
+	key := os.Getenv("UNIPDF_API_KEY")
+	if key == "" {
+		return
+	}
+	doc, err = pdf.NewUniPDF(key, zap.NewNop()).Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer doc.Close()
+	highlighted, err = ExtractAnnotatedWords(doc, nil, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if words := core.NormalizeWords(highlighted, lexicon.NewEnglish(nil)); len(words) != 1 || words[0].Target != "brown" {
+		t.Errorf("unipdf words %+v, want brown", words)
+	}
+}
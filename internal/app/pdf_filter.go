@@ -0,0 +1,313 @@
+package app
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+)
+
+// Markup annotation subtypes that mark text, as named in --type
+var annotationTypes = map[string]string{
+	"highlight": "Highlight",
+	"underline": "Underline",
+	"squiggly":  "Squiggly",
+	"strikeout": "StrikeOut",
+}
+
+// defaultAnnotationTypes are extracted when no type is given
+var defaultAnnotationTypes = []string{"highlight", "underline"}
+
+// colorTolerance is how far (in RGB space, channels from 0 to 1) an annotation colour may be from a #rrggbb value
+const colorTolerance = 0.15
+
+// rgb is a colour with channels from 0 to 1
+type rgb struct {
+	r, g, b float64
+}
+
+// namedColor is a colour that can be named in --color, --color-tag and --color-deck
+type namedColor struct {
+	name  string
+	color rgb
+}
+
+// colorNames are the named colours. An annotation has the name of the closest one, so the slightly different
+// yellows of different readers are all "yellow".
+var colorNames = []namedColor{
+	{"yellow", rgb{1, 1, 0}},
+	{"orange", rgb{1, 0.6, 0}},
+	{"red", rgb{1, 0, 0}},
+	{"pink", rgb{1, 0.6, 0.8}},
+	{"purple", rgb{0.6, 0.3, 0.8}},
+	{"blue", rgb{0.3, 0.6, 1}},
+	{"green", rgb{0.3, 0.85, 0.3}},
+	{"gray", rgb{0.6, 0.6, 0.6}},
+}
+
+func (c rgb) distance(other rgb) float64 {
+	return math.Sqrt((c.r-other.r)*(c.r-other.r) + (c.g-other.g)*(c.g-other.g) + (c.b-other.b)*(c.b-other.b))
+}
+
+// name returns the name of the closest named colour
+func (c rgb) name() string {
+	best, bestDistance := "", math.Inf(1)
+	for _, named := range colorNames {
+		if d := c.distance(named.color); d < bestDistance {
+			best, bestDistance = named.name, d
+		}
+	}
+	return best
+}
+
+// matches reports whether the colour is the named colour or within colorTolerance of a #rrggbb value
+func (c rgb) matches(spec string) bool {
+	if hex, ok := parseHexColor(spec); ok {
+		return c.distance(hex) <= colorTolerance
+	}
+	return strings.EqualFold(c.name(), spec)
+}
+
+// parseHexColor parses "#rrggbb"
+func parseHexColor(spec string) (rgb, bool) {
+	if len(spec) != 7 || spec[0] != '#' { //nolint:mnd
+		return rgb{}, false
+	}
+	value, err := strconv.ParseUint(spec[1:], 16, 32)
+	if err != nil {
+		return rgb{}, false
+	}
+	return rgb{float64(value>>16&0xff) / 255, float64(value>>8&0xff) / 255, float64(value&0xff) / 255}, true //nolint:mnd
+}
+
+// ValidateColor checks that a colour is a known name or a #rrggbb value
+func ValidateColor(spec string) error {
+	if _, ok := parseHexColor(spec); ok {
+		return nil
+	}
+	if slices.ContainsFunc(colorNames, func(named namedColor) bool { return strings.EqualFold(named.name, spec) }) {
+		return nil
+	}
+	names := make([]string, len(colorNames))
+	for i, named := range colorNames {
+		names[i] = named.name
+	}
+	return fmt.Errorf("unknown colour %q: use #rrggbb or one of %s", spec, strings.Join(names, ", "))
+}
+
+// annotationColor converts the gray, RGB or CMYK colour of an annotation; ok is false when it has none
+func annotationColor(annotation *core.PDFAnnotation) (color rgb, ok bool) {
+	values := annotation.Color
+	switch len(values) {
+	case 1:
+		return rgb{values[0], values[0], values[0]}, true
+	case 3: //nolint:mnd
+		return rgb{values[0], values[1], values[2]}, true
+	case 4: //nolint:mnd
+		k := 1 - values[3]
+		return rgb{(1 - values[0]) * k, (1 - values[1]) * k, (1 - values[2]) * k}, true
+	default:
+		return rgb{}, false
+	}
+}
+
+// parsePDFDate parses a PDF date string such as "D:20240131235959+01'00'". The offset after the time is "Z" for
+// UTC or +HH'mm' / -HH'mm'; a date without one is in local time.
+func parsePDFDate(value string) (time.Time, bool) {
+	value = strings.TrimPrefix(value, "D:")
+	digits := 0
+	for digits < len(value) && digits < len("20060102150405") && value[digits] >= '0' && value[digits] <= '9' {
+		digits++
+	}
+	if digits < len("2006") || digits%2 != 0 {
+		return time.Time{}, false
+	}
+	location, ok := pdfDateLocation(value[digits:])
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation("20060102150405"[:digits], value[:digits], location)
+	return t, err == nil
+}
+
+// pdfDateLocation returns the zone of the offset of a PDF date: "", "Z", "+01'00'", "-0530" or "+01"
+func pdfDateLocation(offset string) (*time.Location, bool) {
+	if offset == "" {
+		return time.Local, true
+	}
+	sign := 1
+	switch offset[0] {
+	case 'Z', 'z':
+		// Some writers add 00'00' after the Z
+		return time.UTC, true
+	case '-':
+		sign = -1
+	case '+':
+	default:
+		return nil, false
+	}
+	digits := strings.ReplaceAll(offset[1:], "'", "")
+	if len(digits) != 2 && len(digits) != 4 {
+		return nil, false
+	}
+	hours, err := strconv.Atoi(digits[:2])
+	if err != nil || hours > 23 {
+		return nil, false
+	}
+	minutes := 0
+	if len(digits) == 4 {
+		if minutes, err = strconv.Atoi(digits[2:]); err != nil || minutes > 59 {
+			return nil, false
+		}
+	}
+	return time.FixedZone(offset, sign*(hours*3600+minutes*60)), true
+}
+
+// PageRange is an inclusive range of 1-based pages; Last is 0 for a range up to the end
+type PageRange struct {
+	First, Last int
+}
+
+// PageRanges is a set of page ranges; no ranges means all pages
+type PageRanges []PageRange
+
+// ParsePageRanges parses comma-separated pages and ranges: "1-10,15,20-"
+func ParsePageRanges(value string) (PageRanges, error) {
+	var ranges PageRanges
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		first, last, isRange := strings.Cut(part, "-")
+		var r PageRange
+		var err error
+		if r.First, err = strconv.Atoi(strings.TrimSpace(first)); err != nil || r.First < 1 {
+			return nil, fmt.Errorf("invalid page range %q", part)
+		}
+		r.Last = r.First
+		if isRange {
+			r.Last = 0
+			if last = strings.TrimSpace(last); last != "" {
+				if r.Last, err = strconv.Atoi(last); err != nil || r.Last < r.First {
+					return nil, fmt.Errorf("invalid page range %q", part)
+				}
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+// Contains reports whether the page is in one of the ranges
+func (p PageRanges) Contains(page int) bool {
+	if len(p) == 0 {
+		return true
+	}
+	for _, r := range p {
+		if page >= r.First && (r.Last == 0 || page <= r.Last) {
+			return true
+		}
+	}
+	return false
+}
+
+// AnnotationOptions select the PDF annotations words are extracted from and label the words by colour
+type AnnotationOptions struct {
+	Types   []string // keys of annotationTypes; empty extracts highlights and underlines
+	Colors  []string // colour names or #rrggbb values; empty keeps all colours
+	Authors []string // keep annotations whose author (/T) contains one of these, case-insensitive
+	// Since and Until bound the modification date (/M); annotations without a date are skipped when set
+	Since, Until time.Time
+	Pages        PageRanges
+	ColorTags    map[string]string // colour -> tag of the words highlighted in it
+	ColorDecks   map[string]string // colour -> subdeck of the words highlighted in it
+}
+
+// Validate checks the annotation types and colours
+func (o *AnnotationOptions) Validate() error {
+	for _, t := range o.Types {
+		if _, ok := annotationTypes[strings.ToLower(t)]; !ok {
+			return fmt.Errorf("unknown annotation type %q: use highlight, underline, squiggly or strikeout", t)
+		}
+	}
+	for _, colors := range [][]string{o.Colors, slices.Collect(maps.Keys(o.ColorTags)), slices.Collect(maps.Keys(o.ColorDecks))} {
+		for _, color := range colors {
+			if err := ValidateColor(color); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// keepsType reports whether words are extracted from annotations of the subtype ("Highlight", "StrikeOut", ...)
+func (o *AnnotationOptions) keepsType(subtype string) bool {
+	types := o.Types
+	if len(types) == 0 {
+		types = defaultAnnotationTypes
+	}
+	return slices.ContainsFunc(types, func(t string) bool { return annotationTypes[strings.ToLower(t)] == subtype })
+}
+
+// keeps reports whether an annotation passes the colour, author and date filters
+func (o *AnnotationOptions) keeps(annotation *core.PDFAnnotation) bool {
+	if len(o.Colors) > 0 {
+		color, ok := annotationColor(annotation)
+		if !ok || !slices.ContainsFunc(o.Colors, color.matches) {
+			return false
+		}
+	}
+	if len(o.Authors) > 0 {
+		author := strings.ToLower(annotation.Author)
+		if !slices.ContainsFunc(o.Authors, func(a string) bool { return strings.Contains(author, strings.ToLower(a)) }) {
+			return false
+		}
+	}
+	if !o.Since.IsZero() || !o.Until.IsZero() {
+		modified, ok := parsePDFDate(annotation.Modified)
+		if !ok || (!o.Since.IsZero() && modified.Before(o.Since)) || (!o.Until.IsZero() && !modified.Before(o.Until)) {
+			return false
+		}
+	}
+	return true
+}
+
+// labels returns the tag and subdeck of the words of an annotation, from its colour
+func (o *AnnotationOptions) labels(annotation *core.PDFAnnotation) (tag, deck string) {
+	if len(o.ColorTags) == 0 && len(o.ColorDecks) == 0 {
+		return "", ""
+	}
+	color, ok := annotationColor(annotation)
+	if !ok {
+		return "", ""
+	}
+	return colorLabel(o.ColorTags, color), colorLabel(o.ColorDecks, color)
+}
+
+// colorLabel returns the value of the first key matching the colour, trying #rrggbb keys before names
+func colorLabel(labels map[string]string, color rgb) string {
+	keys := slices.Collect(maps.Keys(labels))
+	slices.SortFunc(keys, func(a, b string) int {
+		_, aHex := parseHexColor(a)
+		_, bHex := parseHexColor(b)
+		if aHex != bHex {
+			if aHex {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+	for _, key := range keys {
+		if color.matches(key) {
+			return labels[key]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+)
+
+func TestParsePageRanges(t *testing.T) {
+	ranges, err := ParsePageRanges("1-3, 7,10-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for page, want := range map[int]bool{1: true, 3: true, 4: false, 7: true, 8: false, 10: true, 500: true} {
+		if got := ranges.Contains(page); got != want {
+			t.Errorf("Contains(%d) = %v, want %v", page, got, want)
+		}
+	}
+	for _, invalid := range []string{"0", "5-2", "a-b", "-3"} {
+		if _, err := ParsePageRanges(invalid); err == nil {
+			t.Errorf("ParsePageRanges(%q) succeeded", invalid)
+		}
+	}
+}
+
+func TestAnnotationOptions(t *testing.T) {
+	// Acrobat's yellow and a reader's light green
+	yellow := &core.PDFAnnotation{Subtype: "Highlight", Color: []float64{1, 0.82, 0}, Author: "Anna Petrova", Modified: "D:20240315101500+01'00'"}
+	green := &core.PDFAnnotation{Subtype: "Highlight", Color: []float64{0.49, 0.87, 0.3}, Author: "Boris", Modified: "D:20240101"}
+
+	opts := &AnnotationOptions{
+		Colors:     []string{"yellow"},
+		ColorTags:  map[string]string{"yellow": "vocabulary", "#7ddd4d": "quotes"},
+		ColorDecks: map[string]string{"green": "Quotes"},
+	}
+	if err := opts.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if !opts.keeps(yellow) || opts.keeps(green) {
+		t.Error("colour filter: want yellow kept and green skipped")
+	}
+	if tag, deck := opts.labels(yellow); tag != "vocabulary" || deck != "" {
+		t.Errorf("labels(yellow) = %q, %q", tag, deck)
+	}
+	if tag, deck := opts.labels(green); tag != "quotes" || deck != "Quotes" {
+		t.Errorf("labels(green) = %q, %q", tag, deck)
+	}
+
+	opts = &AnnotationOptions{Authors: []string{"anna"}, Since: time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)}
+	if !opts.keeps(yellow) || opts.keeps(green) {
+		t.Error("author and date filter: want yellow kept and green skipped")
+	}
+
+	if !opts.keepsType("Underline") || opts.keepsType("StrikeOut") {
+		t.Error("default types: want highlights and underlines only")
+	}
+	if err := (&AnnotationOptions{Types: []string{"ink"}}).Validate(); err == nil {
+		t.Error("Validate accepted an unknown type")
+	}
+	if err := (&AnnotationOptions{Colors: []string{"teal"}}).Validate(); err == nil {
+		t.Error("Validate accepted an unknown colour")
+	}
+}
+
+func TestParsePDFDate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		// Late on 29 February in New York is already 1 March in UTC
+		{"D:20240229233000-05'00'", time.Date(2024, 3, 1, 4, 30, 0, 0, time.UTC)},
+		{"D:20240301003000+01'00'", time.Date(2024, 2, 29, 23, 30, 0, 0, time.UTC)},
+		{"D:20240301003000+0530", time.Date(2024, 2, 29, 19, 0, 0, 0, time.UTC)},
+		{"D:20240301003000Z", time.Date(2024, 3, 1, 0, 30, 0, 0, time.UTC)},
+		{"D:20240301003000Z00'00'", time.Date(2024, 3, 1, 0, 30, 0, 0, time.UTC)},
+		{"20240301", time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		if got, ok := parsePDFDate(tt.value); !ok || !got.Equal(tt.want) {
+			t.Errorf("parsePDFDate(%q) = %v, %v; want %v", tt.value, got, ok, tt.want)
+		}
+	}
+	for _, invalid := range []string{"", "D:202", "D:20240301003000+1", "D:20240301003000+25'00'", "D:20240301003000 GMT"} {
+		if _, ok := parsePDFDate(invalid); ok {
+			t.Errorf("parsePDFDate(%q) succeeded", invalid)
+		}
+	}
+
+	// The filter compares the moments, whatever the zone of the machine
+	opts := &AnnotationOptions{Since: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+	if !opts.keeps(&core.PDFAnnotation{Subtype: "Highlight", Modified: "D:20240229233000-05'00'"}) {
+		t.Error("an annotation made after the start of 1 March UTC was skipped")
+	}
+	if opts.keeps(&core.PDFAnnotation{Subtype: "Highlight", Modified: "D:20240301003000+01'00'"}) {
+		t.Error("an annotation made before the start of 1 March UTC was kept")
+	}
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+)
+
+// quadPointCount is the number of coordinates of one quadrilateral in /QuadPoints
+const quadPointCount = 8
+
+// annotationQuads returns the areas covered by a markup annotation, usually one per highlighted line.
+// They come from /QuadPoints, or from /Rect when there are none.
+func annotationQuads(annotation *core.PDFAnnotation) []core.Rect {
+	var quads []core.Rect
+	for i := 0; i+quadPointCount <= len(annotation.QuadPoints); i += quadPointCount {
+		quads = append(quads, boundingRect(annotation.QuadPoints[i:i+quadPointCount]))
+	}
+	if len(quads) == 0 && annotation.Rect != (core.Rect{}) {
+		quads = append(quads, annotation.Rect)
+	}
+	return quads
+}
+
+// boundingRect returns the rectangle around the (x, y) points, whatever their order
+func boundingRect(points []float64) core.Rect {
+	rect := core.Rect{Llx: math.Inf(1), Lly: math.Inf(1), Urx: math.Inf(-1), Ury: math.Inf(-1)}
+	for i := 0; i+1 < len(points); i += 2 {
+		rect.Llx, rect.Urx = min(rect.Llx, points[i]), max(rect.Urx, points[i])
+		rect.Lly, rect.Ury = min(rect.Lly, points[i+1]), max(rect.Ury, points[i+1])
+	}
+	return rect
+}
+
+// highlightedText returns the text highlighted by the quads and the sentence around it. A character is highlighted
+// when its centre is inside a quad, so neighbouring lines and words that only touch the highlight are left
+// out. Lines are put in the reading order of the page text, spaces are kept between words and lines, and
+// words cut by the ends of the highlight are completed.
+func highlightedText(text *core.PageText, quads []core.Rect) (extracted, sentence string) {
+	var lines [][]core.TextMark
+	for _, quad := range quads {
+		var line []core.TextMark
+		for _, mark := range text.Marks {
+			x, y := (mark.BBox.Llx+mark.BBox.Urx)/2, (mark.BBox.Lly+mark.BBox.Ury)/2 //nolint:mnd
+			if x >= quad.Llx && x <= quad.Urx && y >= quad.Lly && y <= quad.Ury && mark.Text != "" {
+				line = append(line, mark)
+			}
+		}
+		if len(line) == 0 {
+			continue
+		}
+		sort.Slice(line, func(i, j int) bool { return line[i].Offset < line[j].Offset })
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return "", ""
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i][0].Offset < lines[j][0].Offset })
+
+	parts := make([]string, 0, len(lines))
+	for _, line := range lines {
+		var b strings.Builder
+		for i, mark := range line {
+			if i > 0 && mark.Offset > line[i-1].Offset+len(line[i-1].Text) {
+				// Text between the marks, a space or a line break, was skipped
+				b.WriteByte(' ')
+			}
+			b.WriteString(mark.Text)
+		}
+		parts = append(parts, b.String())
+	}
+
+	last := lines[len(lines)-1]
+	start, end := lines[0][0].Offset, last[len(last)-1].Offset+len(last[len(last)-1].Text)
+	prefix, suffix := wordAround(text.Text, start, end)
+	extracted = prefix + strings.Join(parts, " ") + suffix
+	return strings.Join(strings.Fields(extracted), " "), core.JoinHyphenated(core.SentenceAround(text.Text, start, end))
+}
+
+// wordAround returns the rest of the words of text cut by the start and end of a highlight
+func wordAround(text string, start, end int) (prefix, suffix string) {
+	if start >= end || end > len(text) {
+		return "", ""
+	}
+	from := start
+	if r, _ := utf8.DecodeRuneInString(text[start:]); unicode.IsLetter(r) {
+		for from > 0 {
+			r, size := utf8.DecodeLastRuneInString(text[:from])
+			if !unicode.IsLetter(r) {
+				break
+			}
+			from -= size
+		}
+	}
+	to := end
+	if r, _ := utf8.DecodeLastRuneInString(text[:end]); unicode.IsLetter(r) {
+		for to < len(text) {
+			r, size := utf8.DecodeRuneInString(text[to:])
+			if !unicode.IsLetter(r) {
+				break
+			}
+			to += size
+		}
+	}
+	return text[from:start], text[end:to]
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+)
+
+// Layout of layoutText: monospaced characters on lines from the top of the page
+const (
+	testLeft       = 72.0
+	testTop        = 700.0
+	testCharWidth  = 6.0
+	testLineHeight = 14.0
+)
+
+// layoutText lays lines out like the extractor does: line breaks become spaces without marks of their own
+func layoutText(lines ...string) *core.PageText {
+	p := &core.PageText{}
+	for i, line := range lines {
+		if i > 0 {
+			p.Text += " "
+		}
+		y := testTop - float64(i)*testLineHeight
+		for j, r := range line {
+			x := testLeft + float64(j)*testCharWidth
+			p.Marks = append(p.Marks, core.TextMark{
+				Text:   string(r),
+				Offset: len(p.Text),
+				BBox:   core.Rect{Llx: x, Lly: y - 2, Urx: x + testCharWidth, Ury: y + 8},
+			})
+			p.Text += string(r)
+		}
+	}
+	return p
+}
+
+// quad covers characters [from, to) of a line, reaching a little into the neighbouring characters and lines
+func quad(line, from, to int) core.Rect {
+	y := testTop - float64(line)*testLineHeight
+	return core.Rect{
+		Llx: testLeft + float64(from)*testCharWidth - 2,
+		Lly: y - 5,
+		Urx: testLeft + float64(to)*testCharWidth + 2,
+		Ury: y + 11,
+	}
+}
+
+func TestHighlightedText(t *testing.T) {
+	text := layoutText(
+		"The storm was ter-",
+		"rifying, so the plane took",
+		"off late that night.",
+	)
+	tests := []struct {
+		name          string
+		quads         []core.Rect
+		want, example string
+	}{
+		{"word", []core.Rect{quad(1, 16, 21)}, "plane", "The storm was terrifying, so the plane took off late that night."},
+		{"phrase over two lines", []core.Rect{quad(1, 22, 26), quad(2, 0, 3)}, "took off", ""},
+		{"quads out of order", []core.Rect{quad(2, 0, 3), quad(1, 22, 26)}, "took off", ""},
+		{"hyphenated word", []core.Rect{quad(0, 14, 18), quad(1, 0, 7)}, "ter- rifying", ""},
+		{"middle of a word", []core.Rect{quad(0, 5, 8)}, "storm", ""},
+		{"two words", []core.Rect{quad(2, 4, 13)}, "late that", ""},
+		{"nothing", []core.Rect{quad(5, 0, 3)}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, example := highlightedText(text, tt.quads)
+			if got != tt.want {
+				t.Errorf("highlightedText() = %q, want %q", got, tt.want)
+			}
+			if tt.example != "" && example != tt.example {
+				t.Errorf("highlightedText() example = %q, want %q", example, tt.example)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/anki"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/excel"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/lexicon"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/subtitles"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/wordlist"
+
+	"go.uber.org/zap"
+)
+
+// capitalizedNouns are languages that capitalize all nouns, so capitalized words are not names
+var capitalizedNouns = map[string]bool{"de": true, "lb": true}
+
+// SubtitleMinerConfig holds CLI flag values for mining vocabulary from subtitles
+type SubtitleMinerConfig struct {
+	SubtitlePaths []string // .srt or .vtt files, e.g. the episodes of a season
+	Encoding      string
+	SheetPath     string
+	Languages     core.LanguagePair // the subtitles are in the target language
+	// FrequencyList ranks words from the most common; the MaxRank most common are skipped
+	FrequencyList string
+	MaxRank       int
+	// KnownPaths are .apkg decks, word lists read by make-apkg (.xlsx, .csv, .tsv) or
+	// text files with one known word per line
+	KnownPaths []string
+	LemmaList  string // "lemma<TAB>form" lines, for languages without built-in rules
+	NoLemmas   bool
+	MinLength  int
+	TranslationConfig
+}
+
+// SubtitleMiner turns subtitles into a sheet of candidate words read by make-apkg
+type SubtitleMiner struct {
+	config *SubtitleMinerConfig
+	logger *zap.Logger
+}
+
+// NewSubtitleMiner creates a new SubtitleMiner instance
+func NewSubtitleMiner(config *SubtitleMinerConfig, logger *zap.Logger) *SubtitleMiner {
+	return &SubtitleMiner{
+		config: config,
+		logger: logger,
+	}
+}
+
+// Run mines the subtitles, translates the candidates and writes the sheet
+func (s *SubtitleMiner) Run(ctx context.Context) error {
+	var frequency lexicon.Frequency
+	if s.config.FrequencyList != "" {
+		var err error
+		if frequency, err = lexicon.ReadFrequencyList(s.config.FrequencyList); err != nil {
+			return err
+		}
+		s.logger.Info("Read frequency list", zap.Int("words", len(frequency)), zap.Int("skipping_top", s.config.MaxRank))
+	} else {
+		s.logger.Warn("No frequency list: common words are not filtered out. Use --frequency-list.")
+	}
+
+	lemmatizer, err := newLemmatizer(s.config.Languages.Target, s.config.LemmaList, s.config.NoLemmas, frequency, s.logger)
+	if err != nil {
+		return err
+	}
+	known, err := s.knownWords(ctx, lemmatizer)
+	if err != nil {
+		return err
+	}
+
+	miner := subtitles.NewMiner(&subtitles.Options{
+		Lemmatizer: lemmatizer,
+		Frequency:  frequency,
+		MaxRank:    s.config.MaxRank,
+		Known:      known,
+		MinLength:  s.config.MinLength,
+		SkipNames:  !capitalizedNouns[s.config.Languages.Target],
+	})
+	for _, path := range s.config.SubtitlePaths {
+		cues, err := subtitles.ReadFile(path, s.config.Encoding)
+		if err != nil {
+			return err
+		}
+		miner.Add(cues, strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+		s.logger.Info("Read subtitles", zap.String("path", path), zap.Int("cues", len(cues)))
+	}
+
+	words, stats := miner.Words()
+	s.logger.Info("Mined subtitles",
+		zap.Int("tokens", stats.Tokens),
+		zap.Int("common", stats.Common),
+		zap.Int("known", stats.Known),
+		zap.Int("names", stats.Names),
+		zap.Int("short", stats.Short),
+		zap.Int("candidates", len(words)))
+	if len(words) == 0 {
+		return fmt.Errorf("no new words found in the subtitles")
+	}
+
+	translator, closers, err := newTranslator(&s.config.TranslationConfig, s.config.Languages, s.logger)
+	if err != nil {
+		return err
+	}
+	defer closeAll(closers, s.logger)
+	if err := translateWords(ctx, translator, words, s.config.MinConfidence, s.logger); err != nil {
+		return err
+	}
+
+	if err := excel.NewWriter(s.logger).WriteExtractedWords(words, s.config.Languages, s.config.SheetPath); err != nil {
+		return err
+	}
+	s.logger.Info("Wrote words to Excel", zap.String("output", s.config.SheetPath))
+	return nil
+}
+
+// knownWords reads the known words and their lemmas
+func (s *SubtitleMiner) knownWords(ctx context.Context, lemmatizer core.Lemmatizer) (lexicon.KnownWords, error) {
+	known := make(lexicon.KnownWords)
+	for _, path := range s.config.KnownPaths {
+		words := make(lexicon.KnownWords)
+		switch ext := strings.ToLower(filepath.Ext(path)); {
+		case ext == ".apkg":
+			// Our note type names the word field after the language code
+			fieldNames := []string{strings.ToUpper(s.config.Languages.Target), "Word", "Front", "Expression", "Vocabulary"}
+			deckWords, err := anki.ReadWords(ctx, path, fieldNames)
+			if err != nil {
+				return nil, err
+			}
+			words.Add(deckWords...)
+		case inputFormats[ext] != "" && inputFormats[ext] != wordlist.TextSourceName:
+			source, err := newWordSource(&ApkgMakerConfig{InputFile: path, Languages: s.config.Languages}, s.logger)
+			if err != nil {
+				return nil, err
+			}
+			wordPairs, err := source.ReadWordPairs(path)
+			if err != nil {
+				return nil, err
+			}
+			for _, wordPair := range wordPairs {
+				words.Add(wordPair.Target)
+			}
+		default:
+			if err := words.ReadKnownWordsList(path); err != nil {
+				return nil, err
+			}
+		}
+		s.logger.Info("Read known words", zap.String("path", path), zap.Int("count", len(words)))
+		for word := range words {
+			known.Add(word, lemmatizer.Lemma(word))
+		}
+	}
+	return known, nil
+}
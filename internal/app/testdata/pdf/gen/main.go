@@ -0,0 +1,183 @@
+// Command gen writes the PDFs of the extract-pdf golden tests: Courier text, where every character is
+// 7.2pt wide at 12pt, so highlights can be placed on exact characters. The PDFs in testdata/pdf/limits show
+// what a backend cannot read and have no .golden file. Run it from internal/app:
+//
+//	go run ./testdata/pdf/gen
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	left       = 72.0
+	top        = 700.0
+	lineHeight = 14.0
+	charWidth  = 7.2
+	fontSize   = 12
+)
+
+// highlight covers characters [from, to) of each listed line; rect overrides the /Rect of the annotation
+type highlight struct {
+	spans    []span
+	rect     []float64
+	contents string
+	noQuads  bool
+}
+
+type span struct {
+	line, from, to int
+}
+
+type page struct {
+	lines      []string
+	highlights []highlight
+}
+
+func main() {
+	corpus := map[string][]page{
+		// A wide /Rect touching the neighbouring words; the quad covers "brown" only
+		"single-word.pdf": {{
+			lines: []string{"The quick brown fox jumps over the lazy dog."},
+			highlights: []highlight{{
+				spans: []span{{0, 10, 15}},
+				rect:  []float64{left + 7*charWidth, top - 4, left + 18*charWidth, top + 12},
+			}},
+		}},
+		// A word hyphenated across two lines and a phrasal verb across a line break
+		"multi-line.pdf": {{
+			lines: []string{
+				"The storm was ter-",
+				"rifying, so the plane took",
+				"off late that night.",
+			},
+			highlights: []highlight{
+				{spans: []span{{0, 14, 18}, {1, 0, 7}}},
+				{spans: []span{{1, 22, 26}, {2, 0, 3}}},
+			},
+		}},
+		// A highlight covering only the middle of a word, and one with /Rect only
+		"partial-word.pdf": {{
+			lines: []string{"It was pure serendipity that we met."},
+			highlights: []highlight{
+				{spans: []span{{0, 14, 20}}},
+				{spans: []span{{0, 3, 6}}, noQuads: true},
+			},
+		}},
+		// The highlighted text is stored in /Contents
+		"contents.pdf": {{
+			lines:      []string{"Children's books are often wiser than adults think."},
+			highlights: []highlight{{spans: []span{{0, 0, 10}}, contents: "Children's"}},
+		}},
+		// The same word on two pages, inflected differently
+		"two-pages.pdf": {
+			{
+				lines:      []string{"He was running late again."},
+				highlights: []highlight{{spans: []span{{0, 7, 14}}}},
+			},
+			{
+				lines:      []string{"They ran home in the rain."},
+				highlights: []highlight{{spans: []span{{0, 5, 8}}}},
+			},
+		},
+	}
+	for name, pages := range corpus {
+		if err := os.WriteFile(filepath.Join("testdata", "pdf", name), build(pages, true), 0o600); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// The font has no /Widths, as the standard 14 fonts may be written: readers are expected to know them
+	noWidths := build([]page{{
+		lines:      []string{"The quick brown fox jumps over the lazy dog."},
+		highlights: []highlight{{spans: []span{{0, 10, 15}}}},
+	}}, false)
+	if err := os.WriteFile(filepath.Join("testdata", "pdf", "limits", "no-widths.pdf"), noWidths, 0o600); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// build writes a PDF with one Courier font, one content stream per page and Highlight annotations. Without
+// widths, the font is left to the standard metrics of Courier.
+func build(pages []page, widths bool) []byte {
+	var objects []string
+	add := func(object string) int {
+		objects = append(objects, object)
+		return len(objects)
+	}
+	add("<< /Type /Catalog /Pages 2 0 R >>")
+	add("") // pages, once the kids are known
+	// The widths are those of the standard Courier; some parsers need them to place the characters
+	font := add("<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>")
+	if widths {
+		objects[font-1] = "<< /Type /Font /Subtype /Type1 /BaseFont /Courier /FirstChar 32 /LastChar 126 /Widths [" +
+			strings.TrimSpace(strings.Repeat("600 ", 126-32+1)) + "] >>"
+	}
+
+	var kids []string
+	for _, p := range pages {
+		var content strings.Builder
+		fmt.Fprintf(&content, "BT /F1 %d Tf\n", fontSize)
+		for i, line := range p.lines {
+			escaped := strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(line)
+			fmt.Fprintf(&content, "1 0 0 1 %g %g Tm (%s) Tj\n", left, top-float64(i)*lineHeight, escaped)
+		}
+		content.WriteString("ET\n")
+		stream := add(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+
+		var annots []string
+		for _, h := range p.highlights {
+			annots = append(annots, fmt.Sprintf("%d 0 R", add(annotation(h))))
+		}
+		kids = append(kids, fmt.Sprintf("%d 0 R", add(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 %d 0 R >> >> "+
+				"/Contents %d 0 R /Annots [%s] >>", font, stream, strings.Join(annots, " ")))))
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+// annotation returns a Highlight annotation with one quad per span, in the usual upper-left, upper-right,
+// lower-left, lower-right order
+func annotation(h highlight) string {
+	var quads []string
+	llx, lly, urx, ury := 1e9, 1e9, -1e9, -1e9
+	for _, s := range h.spans {
+		x1, x2 := left+float64(s.from)*charWidth, left+float64(s.to)*charWidth
+		y1 := top - float64(s.line)*lineHeight - 3
+		y2 := y1 + fontSize
+		quads = append(quads, fmt.Sprintf("%g %g %g %g %g %g %g %g", x1, y2, x2, y2, x1, y1, x2, y1))
+		llx, lly, urx, ury = min(llx, x1), min(lly, y1), max(urx, x2), max(ury, y2)
+	}
+	rect := []float64{llx, lly, urx, ury}
+	if h.rect != nil {
+		rect = h.rect
+	}
+	dict := fmt.Sprintf("<< /Type /Annot /Subtype /Highlight /Rect [%g %g %g %g] /C [1 1 0]", rect[0], rect[1], rect[2], rect[3])
+	if !h.noQuads {
+		dict += fmt.Sprintf(" /QuadPoints [%s]", strings.Join(quads, " "))
+	}
+	if h.contents != "" {
+		dict += fmt.Sprintf(" /Contents (%s)", h.contents)
+	}
+	return dict + " >>"
+}
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/dictionary"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/pkg/clients/libretranslate"
+
+	"go.uber.org/zap"
+)
+
+// TranslationConfig selects the translators that fill the source language column of extracted words
+type TranslationConfig struct {
+	// Translators in priority order: file, wiktionary, libretranslate. Without translators, the offline ones that are
+	// configured are used: file when TranslationFile is set and wiktionary when WiktionaryDB exists. With none of
+	// those the column is left empty.
+	Translators     []string
+	TranslationFile string  // bilingual TSV/CSV for the file translator
+	WiktionaryDB    string  // store created by `dict import-wiktionary`
+	TranslationURL  string  // LibreTranslate server
+	TranslationKey  string  // LibreTranslate API key, optional
+	MinConfidence   float64 // translations below this are marked for review
+}
+
+// translateWords fills the source language column of extracted words when a translator is set
+//
+//nolint:lll
+func translateWords(
+	ctx context.Context, translator core.Translator, words []*core.ExtractedWord, minConfidence float64, logger *zap.Logger,
+) error {
+	if translator == nil {
+		logger.Warn("No translator configured: the source language column is left empty and make-apkg skips rows without it. " +
+			"Fill it in by hand, use --translator or run 'anki-builder dict import-wiktionary'.")
+		return nil
+	}
+
+	logger.Info("Translating words", zap.String("translator", translator.Name()), zap.Int("words", len(words)))
+	review, err := core.FillTranslations(ctx, translator, words, minConfidence, logger)
+	if err != nil {
+		return fmt.Errorf("failed to translate words: %w", err)
+	}
+	if review > 0 {
+		logger.Warn("Some translations need review (highlighted in the Review column)", zap.Int("words", review))
+	}
+	return nil
+}
+
+// newTranslator builds the configured translators in order; nil if none are configured.
+// The returned closers must be closed when the translator is no longer used.
+func newTranslator(config *TranslationConfig, langs core.LanguagePair, logger *zap.Logger) (core.Translator, []io.Closer, error) {
+	names, explicit := config.Translators, len(config.Translators) > 0
+	if !explicit {
+		names = defaultTranslators(config)
+		if len(names) == 0 {
+			return nil, nil, nil
+		}
+		logger.Info("No --translator given, using the offline translators that are set up", zap.Strings("translators", names))
+	}
+
+	translators := make([]core.Translator, 0, len(names))
+	var closers []io.Closer
+	for _, name := range names {
+		switch name {
+		case dictionary.TranslationFileName:
+			if config.TranslationFile == "" {
+				closeAll(closers, logger)
+				return nil, nil, fmt.Errorf("translator %q requires a translation file", name)
+			}
+			file, err := dictionary.LoadTranslationFile(config.TranslationFile, logger)
+			if err != nil {
+				closeAll(closers, logger)
+				return nil, nil, err
+			}
+			translators = append(translators, file)
+		case dictionary.WiktionaryProviderName:
+			wiktionary, err := dictionary.OpenWiktionary(config.WiktionaryDB, logger)
+			if err != nil {
+				closeAll(closers, logger)
+				return nil, nil, err
+			}
+			if lang := wiktionary.Language(); lang != "" && lang != langs.Target {
+				wiktionary.Close()
+				if explicit {
+					closeAll(closers, logger)
+					return nil, nil, fmt.Errorf("wiktionary store %s has %q words, but the words are %q (import it with --lang %s)",
+						config.WiktionaryDB, lang, langs.Target, langs.Target)
+				}
+				logger.Warn("Not translating with the wiktionary store, its words are in another language",
+					zap.String("store", config.WiktionaryDB), zap.String("store_language", lang), zap.String("language", langs.Target))
+				continue
+			}
+			closers = append(closers, wiktionary)
+			wiktionary.SetTranslationLanguage(langs.Source)
+			translators = append(translators, wiktionary)
+		case libretranslate.ProviderName:
+			translators = append(translators, libretranslate.NewAPI(config.TranslationURL, config.TranslationKey, langs, logger))
+		default:
+			closeAll(closers, logger)
+			return nil, nil, fmt.Errorf("unknown translator %q", name)
+		}
+	}
+	if len(translators) == 0 {
+		return nil, nil, nil
+	}
+	return core.NewTranslatorChain(translators, logger), closers, nil
+}
+
+// defaultTranslators returns the offline translators that can be used without --translator: the translation file
+// when one is given and the Wiktionary store when it was imported. newTranslator skips a store of another language.
+func defaultTranslators(config *TranslationConfig) []string {
+	var names []string
+	if config.TranslationFile != "" {
+		names = append(names, dictionary.TranslationFileName)
+	}
+	if config.WiktionaryDB != "" {
+		if _, err := os.Stat(config.WiktionaryDB); err == nil {
+			names = append(names, dictionary.WiktionaryProviderName)
+		}
+	}
+	return names
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/dictionary"
+
+	"go.uber.org/zap"
+)
+
+func TestDefaultTranslators(t *testing.T) {
+	dir := t.TempDir()
+	store := filepath.Join(dir, "wiktionary.db")
+
+	tests := []struct {
+		config TranslationConfig
+		want   []string
+	}{
+		{TranslationConfig{WiktionaryDB: store}, nil},
+		{TranslationConfig{TranslationFile: "en-ru.tsv", WiktionaryDB: store}, []string{"file"}},
+	}
+	for _, tt := range tests {
+		if got := defaultTranslators(&tt.config); !slices.Equal(got, tt.want) {
+			t.Errorf("defaultTranslators(%+v) = %q, want %q", tt.config, got, tt.want)
+		}
+	}
+
+	// Once the store is imported, it is used as well
+	if err := os.WriteFile(store, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	config := &TranslationConfig{TranslationFile: "en-ru.tsv", WiktionaryDB: store}
+	if got := defaultTranslators(config); !slices.Equal(got, []string{"file", "wiktionary"}) {
+		t.Errorf("with the store imported: %q", got)
+	}
+}
+
+// TestNewTranslator_WiktionaryLanguage refuses a store imported for another language when it is asked for,
+// and leaves it out when it would only be used by default
+func TestNewTranslator_WiktionaryLanguage(t *testing.T) {
+	store := filepath.Join(t.TempDir(), "wiktionary.db")
+	_, err := dictionary.ImportWiktionary(context.Background(), filepath.Join("..", "dictionary", "testdata", "kaikki.jsonl"), store,
+		dictionary.WiktionaryImportOptions{Language: "de", TranslationLanguages: []string{"ru"}}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &TranslationConfig{Translators: []string{"wiktionary"}, WiktionaryDB: store}
+	if _, _, err := newTranslator(config, core.DefaultLanguagePair, zap.NewNop()); err == nil {
+		t.Error("want an error for a German store with English words")
+	}
+
+	config.Translators = nil
+	translator, closers, err := newTranslator(config, core.DefaultLanguagePair, zap.NewNop())
+	closeAll(closers, zap.NewNop())
+	if err != nil || translator != nil {
+		t.Errorf("default translators: got %v, %v; want none", translator, err)
+	}
+
+	translator, closers, err = newTranslator(config, core.LanguagePair{Source: "ru", Target: "de"}, zap.NewNop())
+	closeAll(closers, zap.NewNop())
+	if err != nil || translator == nil {
+		t.Errorf("default translators for German words: got %v, %v", translator, err)
+	}
+}
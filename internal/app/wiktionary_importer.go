@@ -0,0 +1,55 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/dictionary"
+
+	"go.uber.org/zap"
+)
+
+// WiktionaryImporterConfig holds CLI flag values for the wiktionary import
+type WiktionaryImporterConfig struct {
+	DumpFile             string
+	DBPath               string
+	Language             string
+	TranslationLanguages []string
+}
+
+// WiktionaryImporter builds the local wiktionary store from a Wiktextract dump
+type WiktionaryImporter struct {
+	config *WiktionaryImporterConfig
+	logger *zap.Logger
+}
+
+// NewWiktionaryImporter creates a new WiktionaryImporter instance
+func NewWiktionaryImporter(config *WiktionaryImporterConfig, logger *zap.Logger) *WiktionaryImporter {
+	return &WiktionaryImporter{
+		config: config,
+		logger: logger,
+	}
+}
+
+// Run imports the dump, replacing the existing store
+func (w *WiktionaryImporter) Run(ctx context.Context) error {
+	w.logger.Info("Importing wiktionary dump",
+		zap.String("dump", w.config.DumpFile),
+		zap.String("db", w.config.DBPath),
+		zap.String("language", w.config.Language),
+		zap.Strings("translations", w.config.TranslationLanguages))
+
+	imported, err := dictionary.ImportWiktionary(ctx, w.config.DumpFile, w.config.DBPath, dictionary.WiktionaryImportOptions{
+		Language:             w.config.Language,
+		TranslationLanguages: w.config.TranslationLanguages,
+	}, w.logger)
+	if err != nil {
+		return fmt.Errorf("failed to import wiktionary dump: %w", err)
+	}
+	if imported == 0 {
+		return fmt.Errorf("no %q entries found in %s", w.config.Language, w.config.DumpFile)
+	}
+
+	w.logger.Info("Imported wiktionary dump", zap.String("db", w.config.DBPath), zap.Int("entries", imported))
+	return nil
+}
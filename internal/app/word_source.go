@@ -0,0 +1,79 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/excel"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/wordlist"
+
+	"go.uber.org/zap"
+)
+
+// inputFormats maps file extensions to word source names
+var inputFormats = map[string]string{
+	".xlsx": excel.SourceName,
+	".xlsm": excel.SourceName,
+	".csv":  wordlist.CSVSourceName,
+	".tsv":  wordlist.TSVSourceName,
+	".tab":  wordlist.TSVSourceName,
+	".txt":  wordlist.TextSourceName,
+	".text": wordlist.TextSourceName,
+}
+
+// newWordSource returns the reader for the input file, chosen by InputFormat or the file extension
+func newWordSource(config *ApkgMakerConfig, logger *zap.Logger) (core.WordSource, error) {
+	format := config.InputFormat
+	if format == "" || format == "auto" {
+		var ok bool
+		format, ok = inputFormats[strings.ToLower(filepath.Ext(config.InputFile))]
+		if !ok {
+			return nil, fmt.Errorf("cannot tell the format of %q from its extension, set the input format (xlsx, csv, tsv, text)", config.InputFile)
+		}
+	}
+
+	delimiter, err := parseDelimiter(config.Delimiter)
+	if err != nil {
+		return nil, err
+	}
+	opts := &wordlist.Options{
+		Sheet:     config.Sheet,
+		Columns:   config.Columns,
+		NoHeader:  config.NoHeader,
+		Languages: config.Languages,
+		Delimiter: delimiter,
+		NoQuotes:  config.NoQuotes,
+		Encoding:  config.Encoding,
+	}
+
+	switch format {
+	case excel.SourceName:
+		return excel.NewReader(opts, logger), nil
+	case wordlist.CSVSourceName:
+		return wordlist.NewCSV(opts, logger), nil
+	case wordlist.TSVSourceName:
+		return wordlist.NewTSV(opts, logger), nil
+	case wordlist.TextSourceName:
+		return wordlist.NewText(opts, logger), nil
+	default:
+		return nil, fmt.Errorf("unknown input format %q", format)
+	}
+}
+
+// parseDelimiter accepts a single character, or "tab"/`\t` for a tab; empty keeps the format default
+func parseDelimiter(s string) (rune, error) {
+	switch s {
+	case "":
+		return 0, nil
+	case "tab", `\t`:
+		return '\t', nil
+	}
+	if utf8.RuneCountInString(s) != 1 {
+		return 0, fmt.Errorf("delimiter must be a single character, got %q", s)
+	}
+	r, _ := utf8.DecodeRuneInString(s)
+	return r, nil
+}
@@ -0,0 +1,129 @@
+// Package cache provides an on-disk store for enrichment provider responses.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"go.uber.org/zap"
+)
+
+// entry is the on-disk representation of a cached value
+type entry struct {
+	Word         string          `json:"word"`
+	PartOfSpeech string          `json:"part_of_speech"`
+	Provider     string          `json:"provider"`
+	StoredAt     time.Time       `json:"stored_at"`
+	Value        json.RawMessage `json:"value"`
+}
+
+// FileCache stores one JSON file per entry under <dir>/<provider>/
+type FileCache struct {
+	dir     string
+	ttl     time.Duration
+	refresh map[string]bool
+	logger  *zap.Logger
+}
+
+// NewFileCache creates a file cache. Entries older than ttl are treated as missing (0 disables expiry);
+// words listed in refresh are always fetched again and their entries overwritten.
+func NewFileCache(dir string, ttl time.Duration, refresh []string, logger *zap.Logger) *FileCache {
+	refreshSet := make(map[string]bool, len(refresh))
+	for _, word := range refresh {
+		refreshSet[core.NormalizeText(word)] = true
+	}
+	return &FileCache{
+		dir:     dir,
+		ttl:     ttl,
+		refresh: refreshSet,
+		logger:  logger,
+	}
+}
+
+// Get loads a cached value into value and reports whether a fresh entry was found
+func (c *FileCache) Get(key core.CacheKey, value any) (bool, error) {
+	if c.refresh[key.Word] {
+		c.logger.Debug("Cache refresh requested", zap.String("word", key.Word), zap.String("provider", key.Provider))
+		return false, nil
+	}
+
+	data, err := os.ReadFile(c.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		c.logger.Warn("Ignoring corrupt cache entry", zap.String("path", c.path(key)), zap.Error(err))
+		return false, nil
+	}
+	if c.ttl > 0 && time.Since(e.StoredAt) > c.ttl {
+		c.logger.Debug("Cache entry expired", zap.String("word", key.Word), zap.String("provider", key.Provider))
+		return false, nil
+	}
+
+	if err := json.Unmarshal(e.Value, value); err != nil {
+		return false, fmt.Errorf("failed to decode cache entry: %w", err)
+	}
+	c.logger.Debug("Cache hit", zap.String("word", key.Word), zap.String("provider", key.Provider))
+	return true, nil
+}
+
+// Set stores value under key, replacing any previous entry
+func (c *FileCache) Set(key core.CacheKey, value any) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache value: %w", err)
+	}
+	data, err := json.MarshalIndent(entry{
+		Word:         key.Word,
+		PartOfSpeech: key.PartOfSpeech,
+		Provider:     key.Provider,
+		StoredAt:     time.Now(),
+		Value:        raw,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil { //nolint:mnd
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// Write to a temp file and rename, so an interrupted run never leaves a half-written entry
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".entry-*")
+	if err != nil {
+		return fmt.Errorf("failed to create cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store cache entry: %w", err)
+	}
+	return nil
+}
+
+// path returns the entry file for a key
+func (c *FileCache) path(key core.CacheKey) string {
+	sum := sha256.Sum256([]byte(key.Word + "\x1f" + key.PartOfSpeech))
+	return filepath.Join(c.dir, key.Provider, hex.EncodeToString(sum[:16])+".json")
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+)
+
+type cachedValue struct {
+	URL string `json:"url"`
+}
+
+func TestFileCache(t *testing.T) {
+	dir := t.TempDir()
+	key := core.NewCacheKey("Apple ", "noun", "unsplash")
+
+	c := NewFileCache(dir, time.Hour, nil, zap.NewNop())
+	var got cachedValue
+	if found, err := c.Get(key, &got); err != nil || found {
+		t.Fatalf("Expected miss on empty cache, got found=%v err=%v", found, err)
+	}
+
+	if err := c.Set(key, cachedValue{URL: "https://example.com/apple.jpg"}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	// Same word with different case/spacing maps to the same entry
+	found, err := c.Get(core.NewCacheKey("apple", "Noun", "unsplash"), &got)
+	if err != nil || !found {
+		t.Fatalf("Expected hit, got found=%v err=%v", found, err)
+	}
+	if got.URL != "https://example.com/apple.jpg" {
+		t.Errorf("Expected cached URL, got %q", got.URL)
+	}
+
+	// Other providers and parts of speech are separate entries
+	if found, _ := c.Get(core.NewCacheKey("apple", "noun", "free-dictionary"), &got); found {
+		t.Errorf("Expected miss for another provider")
+	}
+	if found, _ := c.Get(core.NewCacheKey("apple", "verb", "unsplash"), &got); found {
+		t.Errorf("Expected miss for another part of speech")
+	}
+
+	// Refreshed words are always missing
+	refreshing := NewFileCache(dir, time.Hour, []string{"APPLE"}, zap.NewNop())
+	if found, _ := refreshing.Get(key, &got); found {
+		t.Errorf("Expected miss for refreshed word")
+	}
+
+	// Expired entries are missing
+	expiring := NewFileCache(dir, time.Nanosecond, nil, zap.NewNop())
+	time.Sleep(time.Millisecond)
+	if found, _ := expiring.Get(key, &got); found {
+		t.Errorf("Expected miss for expired entry")
+	}
+}
@@ -0,0 +1,38 @@
+package core
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// CacheKey identifies a cached provider response
+type CacheKey struct {
+	Word         string
+	PartOfSpeech string
+	Provider     string
+}
+
+// NewCacheKey builds a cache key from the normalized word and part of speech, so "n.", "noun" and "Noun" share
+// an entry
+func NewCacheKey(word, partOfSpeech, provider string) CacheKey {
+	return CacheKey{
+		Word:         NormalizeText(word),
+		PartOfSpeech: NormalizePartOfSpeech(partOfSpeech),
+		Provider:     provider,
+	}
+}
+
+// Cache stores provider responses between runs so unchanged words are not fetched again
+type Cache interface {
+	// Get loads the cached value into value and reports whether a fresh entry was found
+	Get(key CacheKey, value any) (bool, error)
+	// Set stores value under key
+	Set(key CacheKey, value any) error
+}
+
+// NormalizeText lowercases the text, applies Unicode NFC and collapses whitespace
+func NormalizeText(s string) string {
+	s = norm.NFC.String(s)
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxSentenceLength caps example sentences cut from running text; longer ones are shortened around the word
+const maxSentenceLength = 300
+
+// sentenceEnds are the runes that end a sentence when followed by a space or the end of the text
+const sentenceEnds = ".!?…"
+
+// FindSentence returns the sentence of text that contains phrase, matched case-insensitively on word
+// boundaries, or "" if the phrase does not occur
+func FindSentence(text, phrase string) string {
+	phrase = strings.TrimSpace(phrase)
+	if phrase == "" {
+		return ""
+	}
+	lowerText, lowerPhrase := strings.ToLower(text), strings.ToLower(phrase)
+	if len(lowerText) != len(text) {
+		// Lowercasing changed byte offsets; fall back to an exact match
+		lowerText, lowerPhrase = text, phrase
+	}
+	for offset := 0; offset < len(lowerText); {
+		i := strings.Index(lowerText[offset:], lowerPhrase)
+		if i < 0 {
+			return ""
+		}
+		start, end := offset+i, offset+i+len(lowerPhrase)
+		if isWordBoundary(text, start, end) {
+			return SentenceAround(text, start, end)
+		}
+		_, size := utf8.DecodeRuneInString(lowerText[start:])
+		offset = start + size
+	}
+	return ""
+}
+
+// SentenceAround returns the sentence of text containing the bytes [start, end), with whitespace collapsed
+func SentenceAround(text string, start, end int) string {
+	if start < 0 || end > len(text) || start > end {
+		return ""
+	}
+	from := strings.LastIndexFunc(text[:start], func(r rune) bool { return r == '\n' })
+	for i := start; i > from+1; {
+		r, size := utf8.DecodeLastRuneInString(text[:i])
+		if strings.ContainsRune(sentenceEnds, r) && i < len(text) && unicode.IsSpace(rune(text[i])) {
+			from = i - 1
+			break
+		}
+		i -= size
+	}
+	to := len(text)
+	if n := strings.IndexByte(text[end:], '\n'); n >= 0 {
+		to = end + n
+	}
+	for i := end; i < to; i++ {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if strings.ContainsRune(sentenceEnds, r) && (i+size == len(text) || unicode.IsSpace(rune(text[i+size]))) {
+			to = i + size
+			break
+		}
+	}
+	return shortenSentence(strings.Join(strings.Fields(text[from+1:to]), " "), text[start:end])
+}
+
+// shortenSentence keeps at most maxSentenceLength characters of sentence, centered on phrase
+func shortenSentence(sentence, phrase string) string {
+	runes := []rune(sentence)
+	if len(runes) <= maxSentenceLength {
+		return sentence
+	}
+	at := utf8.RuneCountInString(sentence[:max(strings.Index(sentence, strings.Join(strings.Fields(phrase), " ")), 0)])
+	from := max(at-maxSentenceLength/2, 0)
+	to := min(from+maxSentenceLength, len(runes))
+	from = max(to-maxSentenceLength, 0)
+	shortened := strings.TrimSpace(string(runes[from:to]))
+	if from > 0 {
+		shortened = "…" + shortened
+	}
+	if to < len(runes) {
+		shortened += "…"
+	}
+	return shortened
+}
+
+// isWordBoundary reports whether text[start:end] is not part of a longer word
+func isWordBoundary(text string, start, end int) bool {
+	if start > 0 {
+		r, _ := utf8.DecodeLastRuneInString(text[:start])
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return false
+		}
+	}
+	if end < len(text) {
+		r, _ := utf8.DecodeRuneInString(text[end:])
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
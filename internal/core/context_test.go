@@ -0,0 +1,32 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindSentence(t *testing.T) {
+	text := "It was a bright cold day in April. The clocks were striking thirteen! Winston Smith slipped quickly…\n" +
+		"A new paragraph without an end"
+	tests := []struct {
+		phrase, want string
+	}{
+		{"clocks", "The clocks were striking thirteen!"},
+		{"Bright  cold", "It was a bright cold day in April."},
+		{"slipped", "Winston Smith slipped quickly…"},
+		{"paragraph", "A new paragraph without an end"},
+		{"lock", ""}, // inside "clocks"
+		{"missing", ""},
+	}
+	for _, tt := range tests {
+		if got := FindSentence(text, strings.Join(strings.Fields(tt.phrase), " ")); got != tt.want {
+			t.Errorf("FindSentence(%q) = %q, want %q", tt.phrase, got, tt.want)
+		}
+	}
+
+	long := strings.Repeat("word ", 100) + "needle " + strings.Repeat("word ", 100)
+	got := FindSentence(long, "needle")
+	if !strings.Contains(got, "needle") || !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") {
+		t.Errorf("long sentence not shortened around the phrase: %q", got)
+	}
+}
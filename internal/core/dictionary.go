@@ -0,0 +1,196 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// ErrWordNotFound is returned by dictionary providers that have no entry for a word
+var ErrWordNotFound = errors.New("word not found")
+
+// Sense is a single meaning of a word
+type Sense struct {
+	PartOfSpeech string   `json:"part_of_speech"`
+	Definition   string   `json:"definition"`
+	Example      string   `json:"example,omitempty"`
+	Synonyms     []string `json:"synonyms,omitempty"`
+	Antonyms     []string `json:"antonyms,omitempty"`
+	// Translations of this sense by language code, used to pick the sense meant by the sheet translation
+	Translations map[string][]string `json:"translations,omitempty"`
+}
+
+// DictionaryEntry is dictionary data for a word, independent of where it came from
+type DictionaryEntry struct {
+	Word    string  `json:"word"`
+	Senses  []Sense `json:"senses"`
+	IPAUK   string  `json:"ipa_uk"`
+	IPAUS   string  `json:"ipa_us"`
+	AudioUK string  `json:"audio_uk"` // URL
+	AudioUS string  `json:"audio_us"` // URL
+	// Translations by language code, e.g. "ru": ["яблоко"]
+	Translations map[string][]string `json:"translations,omitempty"`
+}
+
+// DictionaryProvider looks up words in a dictionary source.
+// partOfSpeech is a hint from the spreadsheet and may be empty.
+type DictionaryProvider interface {
+	Name() string
+	Lookup(ctx context.Context, word, partOfSpeech string) (*DictionaryEntry, error)
+}
+
+// PartOfSpeechAgnostic is implemented by dictionary providers whose answer does not depend on the part of speech
+// hint; their answers are cached once per word
+type PartOfSpeechAgnostic interface {
+	IgnoresPartOfSpeech() bool
+}
+
+// DictionaryChain queries providers in order and merges their answers field by field:
+// each field is taken from the first provider that has it
+type DictionaryChain struct {
+	providers []DictionaryProvider
+	logger    *zap.Logger
+}
+
+// NewDictionaryChain creates a chain of dictionary providers, highest priority first
+func NewDictionaryChain(providers []DictionaryProvider, logger *zap.Logger) *DictionaryChain {
+	return &DictionaryChain{
+		providers: providers,
+		logger:    logger,
+	}
+}
+
+// Name returns the names of the chained providers
+func (c *DictionaryChain) Name() string {
+	names := make([]string, len(c.providers))
+	for i, p := range c.providers {
+		names[i] = p.Name()
+	}
+	return strings.Join(names, "+")
+}
+
+// Lookup asks every provider and merges the results
+func (c *DictionaryChain) Lookup(ctx context.Context, word, partOfSpeech string) (*DictionaryEntry, error) {
+	var merged *DictionaryEntry
+	var lastErr error
+
+	for _, provider := range c.providers {
+		entry, err := provider.Lookup(ctx, word, partOfSpeech)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			if !errors.Is(err, ErrWordNotFound) {
+				c.logger.Warn("Dictionary provider failed",
+					zap.String("provider", provider.Name()), zap.String("word", word), zap.Error(err))
+				lastErr = err
+			}
+			continue
+		}
+
+		c.logger.Debug("Dictionary provider found word", zap.String("provider", provider.Name()), zap.String("word", word))
+		if merged == nil {
+			merged = &DictionaryEntry{Word: entry.Word}
+		}
+		mergeDictionaryEntry(merged, entry)
+		if merged.complete() {
+			break
+		}
+	}
+
+	if merged == nil {
+		if lastErr != nil {
+			return nil, lastErr
+		}
+		return nil, fmt.Errorf("word '%s': %w", word, ErrWordNotFound)
+	}
+	return merged, nil
+}
+
+// mergeDictionaryEntry fills the empty fields of dst from src
+func mergeDictionaryEntry(dst, src *DictionaryEntry) {
+	if len(dst.Senses) == 0 {
+		dst.Senses = src.Senses
+	}
+	fillEmpty(&dst.IPAUK, src.IPAUK)
+	fillEmpty(&dst.IPAUS, src.IPAUS)
+	fillEmpty(&dst.AudioUK, src.AudioUK)
+	fillEmpty(&dst.AudioUS, src.AudioUS)
+	if len(dst.Translations) == 0 {
+		dst.Translations = src.Translations
+	}
+}
+
+// complete reports whether no later provider could add anything
+func (d *DictionaryEntry) complete() bool {
+	return len(d.Senses) > 0 && d.IPAUK != "" && d.IPAUS != "" && d.AudioUK != "" && d.AudioUS != ""
+}
+
+func fillEmpty(dst *string, src string) {
+	if *dst == "" {
+		*dst = src
+	}
+}
+
+// cachedDictionary wraps a provider with the enrichment cache
+type cachedDictionary struct {
+	provider DictionaryProvider
+	cache    Cache
+	logger   *zap.Logger
+}
+
+// dictionaryCacheEntry is the cached result of a dictionary lookup, including "not found" answers
+type dictionaryCacheEntry struct {
+	Entry    *DictionaryEntry `json:"entry"`
+	NotFound bool             `json:"not_found"`
+}
+
+// NewCachedDictionary returns a provider that answers from cache when possible and stores fresh answers.
+// Transient failures are not cached.
+func NewCachedDictionary(provider DictionaryProvider, cache Cache, logger *zap.Logger) DictionaryProvider {
+	return &cachedDictionary{
+		provider: provider,
+		cache:    cache,
+		logger:   logger,
+	}
+}
+
+// Name returns the name of the wrapped provider
+func (c *cachedDictionary) Name() string {
+	return c.provider.Name()
+}
+
+// Lookup consults the cache before the wrapped provider
+func (c *cachedDictionary) Lookup(ctx context.Context, word, partOfSpeech string) (*DictionaryEntry, error) {
+	key := NewCacheKey(word, partOfSpeech, c.provider.Name())
+	if agnostic, ok := c.provider.(PartOfSpeechAgnostic); ok && agnostic.IgnoresPartOfSpeech() {
+		key.PartOfSpeech = ""
+	}
+
+	var cached dictionaryCacheEntry
+	found, err := c.cache.Get(key, &cached)
+	if err != nil {
+		c.logger.Warn("Failed to read enrichment cache", zap.String("word", word), zap.Error(err))
+	} else if found {
+		if cached.NotFound {
+			return nil, fmt.Errorf("word '%s' (cached): %w", word, ErrWordNotFound)
+		}
+		// Entries written in an older format have no entry; fetch them again
+		if cached.Entry != nil {
+			return cached.Entry, nil
+		}
+	}
+
+	entry, err := c.provider.Lookup(ctx, word, partOfSpeech)
+	if err != nil && !errors.Is(err, ErrWordNotFound) {
+		return nil, err
+	}
+
+	if cacheErr := c.cache.Set(key, dictionaryCacheEntry{Entry: entry, NotFound: err != nil}); cacheErr != nil {
+		c.logger.Warn("Failed to write enrichment cache", zap.String("word", word), zap.Error(cacheErr))
+	}
+	return entry, err
+}
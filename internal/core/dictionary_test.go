@@ -0,0 +1,138 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+type fakeDictionary struct {
+	name    string
+	entries map[string]*DictionaryEntry
+	err     error
+	calls   int
+}
+
+func (f *fakeDictionary) Name() string { return f.name }
+
+func (f *fakeDictionary) Lookup(_ context.Context, word, _ string) (*DictionaryEntry, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	entry, ok := f.entries[word]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", f.name, ErrWordNotFound)
+	}
+	return entry, nil
+}
+
+func TestDictionaryChain_MergesFieldByField(t *testing.T) {
+	online := &fakeDictionary{name: "online", entries: map[string]*DictionaryEntry{
+		"apple": {Word: "apple", AudioUS: "https://example.com/apple-us.mp3", IPAUS: "/ˈæp.əl/"},
+	}}
+	broken := &fakeDictionary{name: "broken", err: errors.New("connection refused")}
+	glossary := &fakeDictionary{name: "glossary", entries: map[string]*DictionaryEntry{
+		"apple": {Word: "apple", Senses: []Sense{{PartOfSpeech: "noun", Definition: "A fruit"}}, IPAUS: "/other/"},
+	}}
+
+	chain := NewDictionaryChain([]DictionaryProvider{online, broken, glossary}, zap.NewNop())
+	entry, err := chain.Lookup(context.Background(), "apple", "noun")
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+
+	if entry.AudioUS != "https://example.com/apple-us.mp3" {
+		t.Errorf("Expected audio from first provider, got %q", entry.AudioUS)
+	}
+	if entry.IPAUS != "/ˈæp.əl/" {
+		t.Errorf("Expected IPA from first provider, got %q", entry.IPAUS)
+	}
+	if len(entry.Senses) != 1 || entry.Senses[0].Definition != "A fruit" {
+		t.Errorf("Expected definition from glossary, got %+v", entry.Senses)
+	}
+	if chain.Name() != "online+broken+glossary" {
+		t.Errorf("Unexpected chain name %q", chain.Name())
+	}
+}
+
+func TestDictionaryChain_NotFound(t *testing.T) {
+	chain := NewDictionaryChain([]DictionaryProvider{
+		&fakeDictionary{name: "a"},
+		&fakeDictionary{name: "b"},
+	}, zap.NewNop())
+
+	_, err := chain.Lookup(context.Background(), "qwerty", "")
+	if !errors.Is(err, ErrWordNotFound) {
+		t.Errorf("Expected ErrWordNotFound, got %v", err)
+	}
+}
+
+type memoryCache map[CacheKey][]byte
+
+func (m memoryCache) Get(key CacheKey, value any) (bool, error) {
+	data, ok := m[key]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, value)
+}
+
+func (m memoryCache) Set(key CacheKey, value any) error {
+	data, err := json.Marshal(value)
+	m[key] = data
+	return err
+}
+
+func TestCachedDictionary(t *testing.T) {
+	provider := &fakeDictionary{name: "online", entries: map[string]*DictionaryEntry{
+		"apple": {Word: "apple", IPAUK: "/ˈæp.əl/"},
+	}}
+	cached := NewCachedDictionary(provider, memoryCache{}, zap.NewNop())
+
+	for i := 0; i < 2; i++ {
+		entry, err := cached.Lookup(context.Background(), "apple", "noun")
+		if err != nil || entry.IPAUK != "/ˈæp.əl/" {
+			t.Fatalf("Unexpected result %+v, %v", entry, err)
+		}
+		if _, err := cached.Lookup(context.Background(), "qwerty", ""); !errors.Is(err, ErrWordNotFound) {
+			t.Fatalf("Expected ErrWordNotFound, got %v", err)
+		}
+	}
+	if provider.calls != 2 {
+		t.Errorf("Expected 2 provider calls (hit and miss cached), got %d", provider.calls)
+	}
+}
+
+// agnosticDictionary answers the same whatever the part of speech, like the Free Dictionary API
+type agnosticDictionary struct {
+	*fakeDictionary
+}
+
+func (agnosticDictionary) IgnoresPartOfSpeech() bool { return true }
+
+func TestCachedDictionary_PartOfSpeech(t *testing.T) {
+	entries := map[string]*DictionaryEntry{"apple": {Word: "apple"}}
+	provider := &fakeDictionary{name: "wiktionary", entries: entries}
+	agnostic := agnosticDictionary{&fakeDictionary{name: "online", entries: entries}}
+	cache := memoryCache{}
+	for _, pos := range []string{"noun", "n.", "Noun", "verb", ""} {
+		for _, dictionary := range []DictionaryProvider{NewCachedDictionary(provider, cache, zap.NewNop()),
+			NewCachedDictionary(agnostic, cache, zap.NewNop())} {
+			if _, err := dictionary.Lookup(context.Background(), "apple", pos); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// The spellings of noun share an entry; verb and no part of speech get their own
+	if provider.calls != 3 {
+		t.Errorf("got %d calls of a provider that uses the part of speech, want 3", provider.calls)
+	}
+	if agnostic.calls != 1 {
+		t.Errorf("got %d calls of a provider that ignores the part of speech, want 1", agnostic.calls)
+	}
+}
@@ -2,161 +2,144 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/downloader"
-	free_dictionary "github.com/commedesvlados/anki-flashcards-autogen-cli/pkg/clients/free-dictionary"
-	"github.com/commedesvlados/anki-flashcards-autogen-cli/pkg/clients/unsplash"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/util"
 
 	"go.uber.org/zap"
 )
 
 // EnrichmentService orchestrates the enrichment process
 type EnrichmentService struct {
-	dictionaryAPI *free_dictionary.API
-	imageAPI      *unsplash.API
-	downloader    *downloader.Downloader
-	logger        *zap.Logger
+	dictionary DictionaryProvider
+	images     ImageProvider // optional
+	downloader *downloader.Downloader
+	language   string // language of the sheet translations, matched against sense translations
+	maxSenses  int
+	logger     *zap.Logger
 }
 
-// NewEnrichmentService creates a new enrichment service
+// NewEnrichmentService creates a new enrichment service; images may be nil to skip the image step.
+// Caching is done per provider, see NewCachedDictionary and NewCachedImageProvider.
 //
 //nolint:lll
-func NewEnrichmentService(dictionaryAPI *free_dictionary.API, imageAPI *unsplash.API, downloader *downloader.Downloader, logger *zap.Logger) *EnrichmentService {
+func NewEnrichmentService(dictionary DictionaryProvider, images ImageProvider, downloader *downloader.Downloader, logger *zap.Logger) *EnrichmentService {
 	return &EnrichmentService{
-		dictionaryAPI: dictionaryAPI,
-		imageAPI:      imageAPI,
-		downloader:    downloader,
-		logger:        logger,
+		dictionary: dictionary,
+		images:     images,
+		downloader: downloader,
+		maxSenses:  DefaultMaxSenses,
+		logger:     logger,
 	}
 }
 
+// SetSenseOptions sets the language of the sheet translations, used to pick the sense they translate, and how
+// many ranked senses a flashcard keeps (at least one)
+func (e *EnrichmentService) SetSenseOptions(language string, maxSenses int) {
+	e.language = language
+	e.maxSenses = max(maxSenses, 1)
+}
+
 // EnrichFlashcard enriches a single flashcard with dictionary data and media
 //
 //nolint:gocyclo
 func (e *EnrichmentService) EnrichFlashcard(ctx context.Context, raw *RawFlashcard, id int) (*Flashcard, error) {
-	e.logger.Info("Enriching flashcard", zap.String("english", raw.English), zap.String("russian", raw.Russian))
+	e.logger.Info("Enriching flashcard", zap.String("target", raw.Target), zap.String("source", raw.Source))
 
-	// Check if phrase or not found in dictionary
-	isPhrase := isMultiWordPhrase(raw.English)
-	dictionaryData, err := e.dictionaryAPI.GetWordInfo(ctx, raw.English)
+	// Phrases are only enriched from an exact dictionary entry (e.g. idioms in the wiktionary store),
+	// never from the entry of their main word
+	isPhrase := isMultiWordPhrase(raw.Target)
+	entry, err := e.dictionary.Lookup(ctx, raw.Target, raw.PartOfSpeech)
 	if err != nil {
-		e.logger.Warn("Failed to get dictionary data", zap.String("word", raw.English), zap.Error(err))
+		e.logger.Warn("Failed to get dictionary data", zap.String("word", raw.Target), zap.Error(err))
 		if isPhrase {
-			dictionaryData = nil
+			entry = nil
 		} else {
-			mainWord := extractMainWord(raw.English)
-			if mainWord != raw.English {
+			mainWord := extractMainWord(raw.Target)
+			if mainWord != raw.Target {
 				e.logger.Info("Trying to get dictionary data for main word", zap.String("main_word", mainWord))
-				dictionaryData, err = e.dictionaryAPI.GetWordInfo(ctx, mainWord)
+				entry, err = e.dictionary.Lookup(ctx, mainWord, raw.PartOfSpeech)
 				if err != nil {
 					e.logger.Warn("Failed to get dictionary data for main word", zap.String("main_word", mainWord), zap.Error(err))
-					dictionaryData = nil
+					entry = nil
 				} else {
 					e.logger.Info("Successfully got dictionary data for main word", zap.String("main_word", mainWord))
 				}
 			}
 		}
 	} else {
-		e.logger.Debug("Successfully got dictionary data", zap.String("word", raw.English))
+		e.logger.Debug("Successfully got dictionary data", zap.String("word", raw.Target))
 	}
 
-	flashcard := &Flashcard{
-		ID:        id,
-		Russian:   raw.Russian,
-		English:   raw.English,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-	}
+	flashcard := newFlashcard(raw, id)
 
-	if dictionaryData != nil && !isPhrase {
-		// Use the first entry for meaning/definition (most common usage)
-		if len(dictionaryData) > 0 {
-			firstEntry := dictionaryData[0]
-			if len(firstEntry.Meanings) > 0 {
-				meaning := firstEntry.Meanings[0]
-				flashcard.PartOfSpeech = meaning.PartOfSpeech
-				if len(meaning.Definitions) > 0 {
-					flashcard.Definition = meaning.Definitions[0].Definition
-				}
+	if entry != nil {
+		// The senses of the sheet's part of speech and translation come first; the sheet's part of speech is kept
+		senses := RankSenses(entry.Senses, raw.PartOfSpeech, raw.Source, e.language)
+		flashcard.PartOfSpeech = raw.PartOfSpeech
+		if len(senses) > 0 {
+			if flashcard.PartOfSpeech == "" {
+				flashcard.PartOfSpeech = senses[0].PartOfSpeech
 			}
+			flashcard.Definition = senses[0].Definition
+			flashcard.Senses = senses[:min(len(senses), e.maxSenses)]
+			flashcard.Synonyms = relatedWords(senses, func(sense *Sense) []string { return sense.Synonyms })
+			flashcard.Antonyms = relatedWords(senses, func(sense *Sense) []string { return sense.Antonyms })
 		}
-
-		// Find UK and US audio from all entries
-		var ukAudio, usAudio, ukIPA, usIPA string
-
-		for _, entry := range dictionaryData { //nolint:gocritic
-			for _, phonetic := range entry.Phonetics {
-				if phonetic.Audio != "" {
-					// Parse audio URL to determine region
-					if strings.Contains(phonetic.Audio, "-uk.mp3") {
-						if ukAudio == "" {
-							ukAudio = phonetic.Audio
-							ukIPA = phonetic.Text
-						}
-					} else if strings.Contains(phonetic.Audio, "-us.mp3") {
-						if usAudio == "" {
-							usAudio = phonetic.Audio
-							usIPA = phonetic.Text
-						}
-					}
-				}
-			}
-			// If we found both UK and US audio, we can stop searching
-			if ukAudio != "" && usAudio != "" {
-				break
-			}
+		// The sentence from the sheet (where the word was met) wins over the dictionary example
+		if flashcard.Example == "" {
+			flashcard.Example = senseExample(senses)
 		}
 
 		// Set IPA (use first available)
-		if ukIPA != "" {
-			flashcard.IPAUK = ukIPA
-		} else if usIPA != "" {
-			flashcard.IPAUK = usIPA
+		if entry.IPAUK != "" {
+			flashcard.IPAUK = entry.IPAUK
+		} else if entry.IPAUS != "" {
+			flashcard.IPAUK = entry.IPAUS
 		}
-		if usIPA != "" {
-			flashcard.IPAUS = usIPA
-		} else if ukIPA != "" {
-			flashcard.IPAUS = ukIPA
+		if entry.IPAUS != "" {
+			flashcard.IPAUS = entry.IPAUS
+		} else if entry.IPAUK != "" {
+			flashcard.IPAUS = entry.IPAUK
 		}
 
 		// Download UK audio
-		if ukAudio != "" {
-			audioUKPath, err := e.downloader.DownloadAudio(ctx, ukAudio, fmt.Sprintf("%d_%s_uk.mp3", id, raw.English))
+		if entry.AudioUK != "" {
+			audioUKPath, err := e.downloader.DownloadAudio(ctx, entry.AudioUK, fmt.Sprintf("%d_%s_uk.mp3", id, raw.Target))
 			if err == nil {
 				flashcard.AudioUK = audioUKPath
 			}
 		}
 
 		// Download US audio
-		if usAudio != "" {
-			audioUSPath, err := e.downloader.DownloadAudio(ctx, usAudio, fmt.Sprintf("%d_%s_us.mp3", id, raw.English))
+		if entry.AudioUS != "" {
+			audioUSPath, err := e.downloader.DownloadAudio(ctx, entry.AudioUS, fmt.Sprintf("%d_%s_us.mp3", id, raw.Target))
 			if err == nil {
 				flashcard.AudioUS = audioUSPath
 			}
 		}
 		// Image
-		imageWord := raw.English
-		if isMultiWordPhrase(raw.English) {
-			mainWord := extractMainWord(raw.English)
-			if mainWord != raw.English {
-				imageWord = mainWord
-			}
-		}
-		imageURL, err := e.imageAPI.GetImageURL(ctx, imageWord)
-		if err == nil {
-			imagePath, err := e.downloader.DownloadImage(ctx, imageURL, fmt.Sprintf("%d_%s.jpg", id, raw.English))
-			if err == nil {
+		if e.images != nil {
+			if imagePath, err := e.findImage(ctx, raw.Target, id); err == nil {
 				flashcard.ImagePath = imagePath
+			} else if errors.Is(err, util.ErrRateLimited) {
+				e.logger.Warn("Image quota spent, building the flashcard without an image",
+					zap.String("target", raw.Target), zap.Error(err))
+			} else {
+				e.logger.Debug("No image for flashcard", zap.String("target", raw.Target), zap.Error(err))
 			}
 		}
 	} else {
-		// Not found or phrase: use only sheet data
+		// Not found: use only sheet data
 		flashcard.PartOfSpeech = raw.PartOfSpeech
 		flashcard.Definition = ""
-		flashcard.Example = ""
 		flashcard.IPAUK = ""
 		flashcard.IPAUS = ""
 		flashcard.AudioUK = ""
@@ -168,6 +151,68 @@ func (e *EnrichmentService) EnrichFlashcard(ctx context.Context, raw *RawFlashca
 	return flashcard, nil
 }
 
+// findImage searches an image for the word (for phrases, the whole phrase first and then its main word)
+// and stores it in the media directory
+func (e *EnrichmentService) findImage(ctx context.Context, word string, id int) (string, error) {
+	queries := []string{word}
+	if isMultiWordPhrase(word) {
+		if mainWord := extractMainWord(word); mainWord != word {
+			queries = append(queries, mainWord)
+		}
+	}
+
+	var image *Image
+	var err error
+	for _, query := range queries {
+		image, err = e.images.FindImage(ctx, query)
+		if err == nil || !errors.Is(err, ErrNoImage) {
+			break
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if image.Path != "" {
+		return e.downloader.CopyFile(image.Path, fmt.Sprintf("%d_%s%s", id, word, filepath.Ext(image.Path)))
+	}
+	return e.downloader.DownloadImage(ctx, image.URL, fmt.Sprintf("%d_%s.jpg", id, word))
+}
+
+// senseExample returns the example of the first sense that has one, among the senses of the part of speech
+// of the first sense: a verb example does not fit a noun card
+func senseExample(senses []Sense) string {
+	for _, sense := range senses {
+		if sense.Example != "" && sense.PartOfSpeech == senses[0].PartOfSpeech {
+			return sense.Example
+		}
+	}
+	return ""
+}
+
+// maxRelatedWords is how many synonyms and antonyms a flashcard keeps
+const maxRelatedWords = 5
+
+// relatedWords collects the synonyms or antonyms of the senses of the part of speech of the first sense, best
+// sense first, without duplicates
+func relatedWords(senses []Sense, words func(sense *Sense) []string) []string {
+	var related []string
+	for i := range senses {
+		if senses[i].PartOfSpeech != senses[0].PartOfSpeech {
+			continue
+		}
+		for _, word := range words(&senses[i]) {
+			if len(related) == maxRelatedWords {
+				return related
+			}
+			if word != "" && !slices.Contains(related, word) {
+				related = append(related, word)
+			}
+		}
+	}
+	return related
+}
+
 // isMultiWordPhrase checks if the given string contains multiple words
 func isMultiWordPhrase(phrase string) bool {
 	words := strings.Fields(phrase)
@@ -203,32 +248,81 @@ func extractMainWord(phrase string) string {
 	return strings.ToLower(words[0])
 }
 
-// EnrichFlashcards enriches multiple flashcards with progress tracking
-func (e *EnrichmentService) EnrichFlashcards(ctx context.Context, rawFlashcards []*RawFlashcard) ([]*Flashcard, error) {
-	enriched := make([]*Flashcard, 0, len(rawFlashcards))
+// EnrichFlashcards enriches multiple flashcards with a bounded pool of workers.
+// Results keep the input order and IDs; onDone, if set, is called once per finished flashcard.
+//
+//nolint:lll
+func (e *EnrichmentService) EnrichFlashcards(ctx context.Context, rawFlashcards []*RawFlashcard, workers int, onDone func()) ([]*Flashcard, error) {
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]*Flashcard, len(rawFlashcards))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var doneMu sync.Mutex
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = e.enrichOrBasic(ctx, rawFlashcards[i], i+1)
+				if onDone != nil {
+					doneMu.Lock()
+					onDone()
+					doneMu.Unlock()
+				}
+			}
+		}()
+	}
 
-	for i, raw := range rawFlashcards {
+feed:
+	for i := range rawFlashcards {
 		select {
 		case <-ctx.Done():
-			return enriched, ctx.Err()
-		default:
-		}
-
-		flashcard, err := e.EnrichFlashcard(ctx, raw, i+1)
-		if err != nil {
-			e.logger.Error("Failed to enrich flashcard", zap.String("english", raw.English), zap.Error(err))
-			// Create basic flashcard without enrichment
-			flashcard = &Flashcard{
-				ID:        i + 1,
-				Russian:   raw.Russian,
-				English:   raw.English,
-				CreatedAt: time.Now(),
-				UpdatedAt: time.Now(),
+			break feed
+		case jobs <- i:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		enriched := make([]*Flashcard, 0, len(results))
+		for _, flashcard := range results {
+			if flashcard != nil {
+				enriched = append(enriched, flashcard)
 			}
 		}
+		return enriched, err
+	}
+	return results, nil
+}
 
-		enriched = append(enriched, flashcard)
+// enrichOrBasic enriches a flashcard, falling back to a basic flashcard with only the sheet data on failure
+func (e *EnrichmentService) enrichOrBasic(ctx context.Context, raw *RawFlashcard, id int) *Flashcard {
+	flashcard, err := e.EnrichFlashcard(ctx, raw, id)
+	if err != nil {
+		e.logger.Error("Failed to enrich flashcard", zap.String("target", raw.Target), zap.Error(err))
+		// Create basic flashcard without enrichment
+		flashcard = newFlashcard(raw, id)
 	}
+	return flashcard
+}
 
-	return enriched, nil
+// newFlashcard creates a flashcard with only the sheet data
+func newFlashcard(raw *RawFlashcard, id int) *Flashcard {
+	return &Flashcard{
+		ID:        id,
+		GUID:      raw.GUID(),
+		Source:    raw.Source,
+		Target:    raw.Target,
+		Example:   raw.Example,
+		Tags:      raw.Tags,
+		Notes:     raw.Notes,
+		Extra:     raw.Extra,
+		Deck:      raw.Deck,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
 }
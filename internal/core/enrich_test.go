@@ -0,0 +1,92 @@
+package core
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/downloader"
+
+	"go.uber.org/zap"
+)
+
+func TestEnrichFlashcard_Example(t *testing.T) {
+	dictionary := &fakeDictionary{name: "glossary", entries: map[string]*DictionaryEntry{
+		"serendipity": {Word: "serendipity", Senses: []Sense{
+			{PartOfSpeech: "noun", Definition: "A happy accident"},
+			{PartOfSpeech: "noun", Definition: "Luck", Example: "It was pure serendipity."},
+		}},
+	}}
+	service := NewEnrichmentService(dictionary, nil, downloader.NewDownloader(t.TempDir(), zap.NewNop()), zap.NewNop())
+
+	tests := []struct {
+		sheetExample, want string
+	}{
+		{"By pure serendipity, we met again.", "By pure serendipity, we met again."},
+		{"", "It was pure serendipity."},
+	}
+	for _, tt := range tests {
+		raw := &RawFlashcard{Source: "счастливая случайность", Target: "serendipity", Example: tt.sheetExample}
+		flashcard, err := service.EnrichFlashcard(context.Background(), raw, 1)
+		if err != nil {
+			t.Fatalf("EnrichFlashcard: %v", err)
+		}
+		if flashcard.Example != tt.want {
+			t.Errorf("sheet example %q: got %q, want %q", tt.sheetExample, flashcard.Example, tt.want)
+		}
+	}
+}
+
+func TestEnrichFlashcard_Senses(t *testing.T) {
+	ru := func(words ...string) map[string][]string { return map[string][]string{"ru": words} }
+	dictionary := &fakeDictionary{name: "wiktionary", entries: map[string]*DictionaryEntry{
+		"book": {Word: "book", Senses: []Sense{
+			{PartOfSpeech: "noun", Definition: "A collection of sheets of paper bound together", Translations: ru("книга")},
+			{PartOfSpeech: "noun", Definition: "A record of bets", Translations: ru("букмекерская книга")},
+			{
+				PartOfSpeech: "verb", Definition: "To record the name of a person who has broken the rules",
+				Synonyms: []string{"caution", "reserve"}, Translations: ru("штрафовать"),
+			},
+			{
+				PartOfSpeech: "verb", Definition: "To reserve", Example: "I booked a table.",
+				Synonyms: []string{"reserve"}, Antonyms: []string{"cancel"}, Translations: ru("бронировать", "заказывать"),
+			},
+		}},
+	}}
+	service := NewEnrichmentService(dictionary, nil, downloader.NewDownloader(t.TempDir(), zap.NewNop()), zap.NewNop())
+	service.SetSenseOptions("ru", 2)
+
+	tests := []struct {
+		source, pos      string
+		wantPOS, wantDef string
+		wantSenses       int
+		wantExample      string
+	}{
+		// The sheet's part of speech and translation pick the sense; the sheet's part of speech is kept
+		{"заказывать, бронировать", "v.", "v.", "To reserve", 2, "I booked a table."},
+		{"книга", "", "noun", "A collection of sheets of paper bound together", 2, ""},
+	}
+	for _, tt := range tests {
+		raw := &RawFlashcard{Source: tt.source, Target: "book", PartOfSpeech: tt.pos}
+		flashcard, err := service.EnrichFlashcard(context.Background(), raw, 1)
+		if err != nil {
+			t.Fatalf("EnrichFlashcard: %v", err)
+		}
+		if flashcard.PartOfSpeech != tt.wantPOS || flashcard.Definition != tt.wantDef || len(flashcard.Senses) != tt.wantSenses {
+			t.Errorf("%s (%s): got %q, %q, %d senses", tt.source, tt.pos, flashcard.PartOfSpeech, flashcard.Definition, len(flashcard.Senses))
+		}
+		if flashcard.Example != tt.wantExample {
+			t.Errorf("%s (%s): example %q, want %q", tt.source, tt.pos, flashcard.Example, tt.wantExample)
+		}
+	}
+
+	// Synonyms and antonyms come from the senses of the best sense's part of speech, best sense first
+	raw := &RawFlashcard{Source: "бронировать", Target: "book", PartOfSpeech: "verb"}
+	flashcard, err := service.EnrichFlashcard(context.Background(), raw, 1)
+	if err != nil {
+		t.Fatalf("EnrichFlashcard: %v", err)
+	}
+	if !slices.Equal(flashcard.Synonyms, []string{"reserve", "caution"}) || !slices.Equal(flashcard.Antonyms, []string{"cancel"}) {
+		t.Errorf("synonyms %q, antonyms %q", flashcard.Synonyms, flashcard.Antonyms)
+	}
+}
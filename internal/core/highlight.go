@@ -0,0 +1,18 @@
+package core
+
+// Highlight is a passage marked in an e-book reader
+type Highlight struct {
+	Text     string // the highlighted text
+	Sentence string // the sentence around it; empty when the reader does not store it
+	Chapter  string
+	Book     string // book title
+	Note     string
+	// Spine is the 0-based index of the EPUB content document the highlight is in, -1 if unknown
+	Spine int
+}
+
+// HighlightSource reads the highlights exported or stored by an e-book reader
+type HighlightSource interface {
+	Name() string
+	ReadHighlights(path string) ([]*Highlight, error)
+}
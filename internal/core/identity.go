@@ -0,0 +1,28 @@
+package core
+
+import (
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/pkg/common"
+)
+
+// NoteKey returns the normalized (target word, source word, part of speech) key that identifies a note across runs.
+// Case, Unicode form and surrounding/repeated whitespace do not change the key.
+// For an en→ru pair the key equals the (English, Russian, part of speech) key used before language pairs,
+// keeping existing note GUIDs stable.
+func NoteKey(target, source, partOfSpeech string) []string {
+	return []string{
+		NormalizeText(target),
+		NormalizeText(source),
+		NormalizeText(partOfSpeech),
+	}
+}
+
+// NoteGUID derives a stable Anki note GUID from the note key,
+// so re-importing a regenerated deck updates existing notes instead of duplicating them
+func NoteGUID(target, source, partOfSpeech string) string {
+	return common.GUIDFor(NoteKey(target, source, partOfSpeech)...)
+}
+
+// GUID returns the stable note GUID for the word pair
+func (r *RawFlashcard) GUID() string {
+	return NoteGUID(r.Target, r.Source, r.PartOfSpeech)
+}
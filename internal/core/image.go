@@ -0,0 +1,84 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// ErrNoImage is returned by image providers that found nothing for a query
+var ErrNoImage = errors.New("no image found")
+
+// Image is an image found by a provider: a remote URL to download or a local file to copy
+type Image struct {
+	URL  string `json:"url,omitempty"`
+	Path string `json:"path,omitempty"`
+}
+
+// ImageProvider finds an illustration for a word
+type ImageProvider interface {
+	Name() string
+	FindImage(ctx context.Context, query string) (*Image, error)
+}
+
+// cachedImageProvider wraps a provider with the enrichment cache
+type cachedImageProvider struct {
+	provider ImageProvider
+	cache    Cache
+	logger   *zap.Logger
+}
+
+// imageCacheEntry is the cached result of an image search, including "no image" answers
+type imageCacheEntry struct {
+	URL      string `json:"url"`
+	Path     string `json:"path,omitempty"`
+	NotFound bool   `json:"not_found"`
+}
+
+// NewCachedImageProvider returns a provider that answers from cache when possible and stores fresh answers.
+// Transient failures are not cached.
+func NewCachedImageProvider(provider ImageProvider, cache Cache, logger *zap.Logger) ImageProvider {
+	return &cachedImageProvider{
+		provider: provider,
+		cache:    cache,
+		logger:   logger,
+	}
+}
+
+// Name returns the name of the wrapped provider
+func (c *cachedImageProvider) Name() string {
+	return c.provider.Name()
+}
+
+// FindImage consults the cache before the wrapped provider
+func (c *cachedImageProvider) FindImage(ctx context.Context, query string) (*Image, error) {
+	key := NewCacheKey(query, "", c.provider.Name())
+
+	var cached imageCacheEntry
+	found, err := c.cache.Get(key, &cached)
+	if err != nil {
+		c.logger.Warn("Failed to read enrichment cache", zap.String("query", query), zap.Error(err))
+	} else if found {
+		if cached.NotFound {
+			return nil, fmt.Errorf("query '%s' (cached): %w", query, ErrNoImage)
+		}
+		return &Image{URL: cached.URL, Path: cached.Path}, nil
+	}
+
+	image, err := c.provider.FindImage(ctx, query)
+	if err != nil && !errors.Is(err, ErrNoImage) {
+		return nil, err
+	}
+
+	cached = imageCacheEntry{NotFound: err != nil}
+	if image != nil {
+		cached.URL = image.URL
+		cached.Path = image.Path
+	}
+	if cacheErr := c.cache.Set(key, cached); cacheErr != nil {
+		c.logger.Warn("Failed to write enrichment cache", zap.String("query", query), zap.Error(cacheErr))
+	}
+	return image, err
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LanguagePair describes a deck: the language the learner already knows (source)
+// and the language being learned (target), as ISO 639-1 codes
+type LanguagePair struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// DefaultLanguagePair is the Russian → English pair the tool started with
+var DefaultLanguagePair = LanguagePair{Source: "ru", Target: "en"}
+
+// languageNames are the English names of common languages, used for column headers and links
+var languageNames = map[string]string{
+	"ar": "arabic",
+	"de": "german",
+	"en": "english",
+	"es": "spanish",
+	"fr": "french",
+	"he": "hebrew",
+	"it": "italian",
+	"ja": "japanese",
+	"nl": "dutch",
+	"pl": "polish",
+	"pt": "portuguese",
+	"ro": "romanian",
+	"ru": "russian",
+	"sv": "swedish",
+	"tr": "turkish",
+	"uk": "ukrainian",
+	"zh": "chinese",
+}
+
+// Validate checks that both languages are two-letter codes and differ
+func (p LanguagePair) Validate() error {
+	for _, code := range []string{p.Source, p.Target} {
+		if len(code) != 2 || strings.ToLower(code) != code || strings.Trim(code, "abcdefghijklmnopqrstuvwxyz") != "" {
+			return fmt.Errorf("invalid language code %q: expected a lowercase ISO 639-1 code such as \"en\"", code)
+		}
+	}
+	if p.Source == p.Target {
+		return fmt.Errorf("source and target language are both %q", p.Source)
+	}
+	return nil
+}
+
+// String returns the pair as "SOURCE-TARGET", e.g. "RU-EN"
+func (p LanguagePair) String() string {
+	return strings.ToUpper(p.Source) + "-" + strings.ToUpper(p.Target)
+}
+
+// LanguageName returns the English name of a language ("russian" for "ru"), or "" if it is not known
+func LanguageName(code string) string {
+	return languageNames[code]
+}
+
+// LanguageTitle returns the capitalized language name for headers ("Russian"), falling back to the code ("KO")
+func LanguageTitle(code string) string {
+	name := LanguageName(code)
+	if name == "" {
+		return strings.ToUpper(code)
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
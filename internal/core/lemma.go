@@ -0,0 +1,15 @@
+package core
+
+// Lemmatizer maps an inflected word to its dictionary form: "running" -> "run", "mice" -> "mouse"
+type Lemmatizer interface {
+	// Lemma returns the dictionary form of a lowercase word, or the word itself when it is unknown
+	Lemma(word string) string
+}
+
+// IdentityLemmatizer keeps words as they are, for languages without a lemmatizer
+type IdentityLemmatizer struct{}
+
+// Lemma implements Lemmatizer
+func (IdentityLemmatizer) Lemma(word string) string {
+	return word
+}
@@ -1,59 +1,113 @@
 package core
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
-// Flashcard represents a single flashcard with Russian-English word pair
+// Flashcard represents a single flashcard with a word in the source language (e.g. Russian)
+// and its translation in the target language being learned (e.g. English)
 type Flashcard struct {
-	ID           int       `json:"id"`
-	Russian      string    `json:"russian"`
-	English      string    `json:"english"`
-	PartOfSpeech string    `json:"part_of_speech"`
-	Definition   string    `json:"definition"`
-	Example      string    `json:"example"`
-	IPAUK        string    `json:"ipa_uk"`
-	IPAUS        string    `json:"ipa_us"`
-	AudioUK      string    `json:"audio_uk"` // e.g., "uk.mp3"
-	AudioUS      string    `json:"audio_us"` // e.g., "us.mp3"
-	ImagePath    string    `json:"image"`    // e.g., "apple.jpg"
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           int               `json:"id"`
+	GUID         string            `json:"guid"` // stable note GUID, see NoteGUID
+	Source       string            `json:"source"`
+	Target       string            `json:"target"`
+	PartOfSpeech string            `json:"part_of_speech"`
+	Definition   string            `json:"definition"`
+	Example      string            `json:"example"`
+	Senses       []Sense           `json:"senses,omitempty"` // ranked, best first; Definition is the first one
+	Synonyms     []string          `json:"synonyms,omitempty"`
+	Antonyms     []string          `json:"antonyms,omitempty"`
+	IPAUK        string            `json:"ipa_uk"`
+	IPAUS        string            `json:"ipa_us"`
+	AudioUK      string            `json:"audio_uk"`        // e.g., "uk.mp3"
+	AudioUS      string            `json:"audio_us"`        // e.g., "us.mp3"
+	ImagePath    string            `json:"image"`           // e.g., "apple.jpg"
+	Tags         []string          `json:"tags,omitempty"`  // from the spreadsheet
+	Notes        string            `json:"notes,omitempty"` // from the spreadsheet
+	Extra        map[string]string `json:"extra,omitempty"` // other spreadsheet columns by header
+	Deck         string            `json:"deck,omitempty"`  // subdeck of the deck, from the spreadsheet
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
 }
 
 // ExportFlash is the struct used for genanki export
 type ExportFlash struct {
-	ID           int    `json:"id"`
-	Russian      string `json:"russian"`
-	English      string `json:"english"`
-	PartOfSpeech string `json:"part_of_speech"`
-	Definition   string `json:"definition"`
-	Example      string `json:"example"`
-	IPAUK        string `json:"ipa_uk"`
-	IPAUS        string `json:"ipa_us"`
-	AudioUK      string `json:"audio_uk"` // e.g., "uk.mp3"
-	AudioUS      string `json:"audio_us"` // e.g., "us.mp3"
-	ImagePath    string `json:"image"`    // e.g., "apple.jpg"
+	ID           int               `json:"id"`
+	GUID         string            `json:"guid"`
+	Source       string            `json:"source"`
+	Target       string            `json:"target"`
+	PartOfSpeech string            `json:"part_of_speech"`
+	Definition   string            `json:"definition"`
+	Example      string            `json:"example"`
+	Senses       []Sense           `json:"senses,omitempty"`
+	Synonyms     []string          `json:"synonyms,omitempty"`
+	Antonyms     []string          `json:"antonyms,omitempty"`
+	IPAUK        string            `json:"ipa_uk"`
+	IPAUS        string            `json:"ipa_us"`
+	AudioUK      string            `json:"audio_uk"` // e.g., "uk.mp3"
+	AudioUS      string            `json:"audio_us"` // e.g., "us.mp3"
+	ImagePath    string            `json:"image"`    // e.g., "apple.jpg"
+	Tags         []string          `json:"tags,omitempty"`
+	Notes        string            `json:"notes,omitempty"`
+	Extra        map[string]string `json:"extra,omitempty"`
+	Deck         string            `json:"deck,omitempty"`
 }
 
 // ToExportFlash converts Flashcard to ExportFlash
 func (f *Flashcard) ToExportFlash() *ExportFlash {
 	return &ExportFlash{
 		ID:           f.ID,
-		Russian:      f.Russian,
-		English:      f.English,
+		GUID:         f.GUID,
+		Source:       f.Source,
+		Target:       f.Target,
 		PartOfSpeech: f.PartOfSpeech,
 		Definition:   f.Definition,
 		Example:      f.Example,
+		Senses:       f.Senses,
+		Synonyms:     f.Synonyms,
+		Antonyms:     f.Antonyms,
 		IPAUK:        f.IPAUK,
 		IPAUS:        f.IPAUS,
 		AudioUK:      f.AudioUK,
 		AudioUS:      f.AudioUS,
 		ImagePath:    f.ImagePath,
+		Tags:         f.Tags,
+		Notes:        f.Notes,
+		Extra:        f.Extra,
+		Deck:         f.Deck,
 	}
 }
 
+// UnmarshalJSON also reads enriched.json files written before language pairs,
+// which have "russian"/"english" keys instead of "source"/"target"
+func (f *ExportFlash) UnmarshalJSON(data []byte) error {
+	type exportFlash ExportFlash
+	aux := struct {
+		*exportFlash
+		Russian string `json:"russian"`
+		English string `json:"english"`
+	}{exportFlash: (*exportFlash)(f)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if f.Source == "" {
+		f.Source = aux.Russian
+	}
+	if f.Target == "" {
+		f.Target = aux.English
+	}
+	return nil
+}
+
 // RawFlashcard represents the basic word pair from Excel
 type RawFlashcard struct {
-	Russian      string `json:"russian"`
-	English      string `json:"english"`
-	PartOfSpeech string `json:"part_of_speech"`
+	Source       string            `json:"source"`
+	Target       string            `json:"target"`
+	PartOfSpeech string            `json:"part_of_speech"`
+	Tags         []string          `json:"tags,omitempty"`
+	Example      string            `json:"example,omitempty"` // used instead of the dictionary example
+	Notes        string            `json:"notes,omitempty"`
+	Extra        map[string]string `json:"extra,omitempty"` // other columns by header
+	Deck         string            `json:"deck,omitempty"`  // subdeck the note goes to
 }
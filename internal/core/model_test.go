@@ -1,16 +1,20 @@
 package core
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/pkg/common"
 )
 
 func TestFlashcard_ToExportFlash(t *testing.T) {
 	now := time.Now()
 	flashcard := &Flashcard{
 		ID:           1,
-		Russian:      "яблоко",
-		English:      "apple",
+		GUID:         NoteGUID("apple", "яблоко", "noun"),
+		Source:       "яблоко",
+		Target:       "apple",
 		PartOfSpeech: "noun",
 		Definition:   "A round fruit with red or green skin",
 		Example:      "I eat an apple every day",
@@ -29,11 +33,14 @@ func TestFlashcard_ToExportFlash(t *testing.T) {
 	if export.ID != flashcard.ID {
 		t.Errorf("Expected ID %d, got %d", flashcard.ID, export.ID)
 	}
-	if export.Russian != flashcard.Russian {
-		t.Errorf("Expected Russian %s, got %s", flashcard.Russian, export.Russian)
+	if export.GUID != flashcard.GUID {
+		t.Errorf("Expected GUID %s, got %s", flashcard.GUID, export.GUID)
+	}
+	if export.Source != flashcard.Source {
+		t.Errorf("Expected Source %s, got %s", flashcard.Source, export.Source)
 	}
-	if export.English != flashcard.English {
-		t.Errorf("Expected English %s, got %s", flashcard.English, export.English)
+	if export.Target != flashcard.Target {
+		t.Errorf("Expected Target %s, got %s", flashcard.Target, export.Target)
 	}
 	if export.PartOfSpeech != flashcard.PartOfSpeech {
 		t.Errorf("Expected PartOfSpeech %s, got %s", flashcard.PartOfSpeech, export.PartOfSpeech)
@@ -63,14 +70,69 @@ func TestFlashcard_ToExportFlash(t *testing.T) {
 
 func TestRawFlashcard(t *testing.T) {
 	raw := &RawFlashcard{
-		Russian: "дом",
-		English: "house",
+		Source: "дом",
+		Target: "house",
+	}
+
+	if raw.Source != "дом" {
+		t.Errorf("Expected Source 'дом', got %s", raw.Source)
+	}
+	if raw.Target != "house" {
+		t.Errorf("Expected Target 'house', got %s", raw.Target)
+	}
+	if raw.GUID() != NoteGUID("house", "дом", "") {
+		t.Errorf("Expected GUID keyed by target word first, got %s", raw.GUID())
+	}
+}
+
+func TestExportFlash_UnmarshalLegacyJSON(t *testing.T) {
+	var flashcards []*ExportFlash
+	data := `[{"id": 1, "russian": "яблоко", "english": "apple"}, {"id": 2, "source": "Haus", "target": "house"}]`
+	if err := json.Unmarshal([]byte(data), &flashcards); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if flashcards[0].ID != 1 || flashcards[0].Source != "яблоко" || flashcards[0].Target != "apple" {
+		t.Errorf("legacy keys not mapped: %+v", flashcards[0])
+	}
+	if flashcards[1].Source != "Haus" || flashcards[1].Target != "house" {
+		t.Errorf("unexpected flashcard: %+v", flashcards[1])
+	}
+}
+
+func TestNoteGUID(t *testing.T) {
+	base := NoteGUID("keep in mind", "иметь ввиду", "phrase")
+
+	tests := []struct {
+		name                         string
+		target, source, partOfSpeech string
+		same                         bool
+	}{
+		{"identical", "keep in mind", "иметь ввиду", "phrase", true},
+		{"case and spacing", "  Keep  in Mind ", "Иметь ввиду", "Phrase", true},
+		{"different part of speech", "keep in mind", "иметь ввиду", "verb", false},
+		{"different translation", "keep in mind", "помнить", "phrase", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NoteGUID(tt.target, tt.source, tt.partOfSpeech)
+			if (got == base) != tt.same {
+				t.Errorf("NoteGUID(%q, %q, %q) = %q, base %q, expected same=%v",
+					tt.target, tt.source, tt.partOfSpeech, got, base, tt.same)
+			}
+		})
 	}
+}
+
+func TestNoteGUID_EnglishRussianStable(t *testing.T) {
+	// GUID of the (English, Russian, part of speech) key used before language pairs
+	const legacy = "gCIiB8a$],"
 
-	if raw.Russian != "дом" {
-		t.Errorf("Expected Russian 'дом', got %s", raw.Russian)
+	if got := NoteGUID("Keep in mind", "иметь ввиду", "Phrase"); got != legacy {
+		t.Errorf("NoteGUID for an en→ru pair = %q, expected the pre-language-pair GUID %q", got, legacy)
 	}
-	if raw.English != "house" {
-		t.Errorf("Expected English 'house', got %s", raw.English)
+	if got := common.GUIDFor("keep in mind", "иметь ввиду", "phrase"); got != legacy {
+		t.Errorf("GUIDFor(english, russian, part of speech) = %q, expected %q", got, legacy)
 	}
 }
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+var (
+	// lineBreakHyphen matches a word hyphenated across a line break once the break became a space: "run- ning"
+	lineBreakHyphen = regexp.MustCompile(`(\p{L})[-\x{2010}]\s+(\p{Ll})`)
+	// possessive matches a final "'s": "children's"
+	possessive = regexp.MustCompile(`'[sS]$`)
+)
+
+// JoinHyphenated repairs words hyphenated across line breaks and removes soft hyphens
+func JoinHyphenated(text string) string {
+	text = strings.ReplaceAll(text, "\u00ad", "")
+	return lineBreakHyphen.ReplaceAllString(text, "$1$2")
+}
+
+// CleanWord turns highlighted text into a dictionary lookup: hyphenation is repaired, whitespace collapsed,
+// apostrophes straightened and punctuation, quotes and possessive endings dropped ("Children’s," -> "Children")
+func CleanWord(text string) string {
+	text = strings.Join(strings.Fields(JoinHyphenated(text)), " ")
+	text = strings.NewReplacer("’", "'", "‘", "'", "ʼ", "'").Replace(text)
+	text = strings.TrimFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+	// A trailing quote is a closing quote or a plural possessive ("dogs'"), both dropped
+	return strings.Trim(possessive.ReplaceAllString(text, ""), "'")
+}
+
+// LemmatizePhrase returns the dictionary form of a word. In phrases only the first word is lemmatized,
+// which covers phrasal verbs: "took off" -> "take off".
+func LemmatizePhrase(lemmatizer Lemmatizer, text string) string {
+	words := strings.Fields(strings.ToLower(text))
+	if len(words) == 0 {
+		return ""
+	}
+	words[0] = lemmatizer.Lemma(words[0])
+	return strings.Join(words, " ")
+}
+
+// NormalizeWords cleans and lemmatizes extracted words and merges the ones with the same lemma, keeping
+// the order of first appearance. The surface forms that differ from the lemma are kept in Forms; the first
+// example, chapter and page found are kept and tags are merged.
+func NormalizeWords(words []*ExtractedWord, lemmatizer Lemmatizer) []*ExtractedWord {
+	if lemmatizer == nil {
+		lemmatizer = IdentityLemmatizer{}
+	}
+	byLemma := make(map[string]*ExtractedWord)
+	var normalized []*ExtractedWord
+	for _, word := range words {
+		surface := strings.ToLower(CleanWord(word.Target))
+		lemma := LemmatizePhrase(lemmatizer, surface)
+		key := NormalizeText(lemma)
+		if key == "" {
+			continue
+		}
+
+		merged, ok := byLemma[key]
+		if !ok {
+			merged = word
+			merged.Target = lemma
+			byLemma[key] = merged
+			normalized = append(normalized, merged)
+		} else {
+			if merged.Example == "" {
+				merged.Example, merged.Chapter, merged.Page = word.Example, word.Chapter, word.Page
+			}
+			for _, tag := range word.Tags {
+				if !slices.Contains(merged.Tags, tag) {
+					merged.Tags = append(merged.Tags, tag)
+				}
+			}
+		}
+		if surface != lemma && !slices.Contains(merged.Forms, surface) {
+			merged.Forms = append(merged.Forms, surface)
+		}
+	}
+	return normalized
+}
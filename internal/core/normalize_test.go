@@ -0,0 +1,62 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// mapLemmatizer looks words up in a map
+type mapLemmatizer map[string]string
+
+func (m mapLemmatizer) Lemma(word string) string {
+	if lemma, ok := m[word]; ok {
+		return lemma
+	}
+	return word
+}
+
+func TestCleanWord(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"running,", "running"},
+		{"“Children’s”", "Children"},
+		{"dogs'", "dogs"},
+		{"run- ning", "running"},
+		{"un\u00adder", "under"},
+		{"well-known", "well-known"},
+		{"  took\noff. ", "took off"},
+		{"don't", "don't"},
+		{"...", ""},
+	}
+	for _, tt := range tests {
+		if got := CleanWord(tt.text); got != tt.want {
+			t.Errorf("CleanWord(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeWords(t *testing.T) {
+	lemmatizer := mapLemmatizer{"running": "run", "ran": "run", "took": "take", "children": "child"}
+	words := []*ExtractedWord{
+		{Target: "Running,", Page: 1, Tags: []string{"a"}},
+		{Target: "took off", Example: "The plane took off.", Page: 2},
+		{Target: "ran", Example: "She ran.", Page: 3, Tags: []string{"b"}},
+		{Target: "Children’s"},
+		{Target: "run"},
+		{Target: "“”"},
+	}
+
+	got := NormalizeWords(words, lemmatizer)
+	want := []*ExtractedWord{
+		{Target: "run", Forms: []string{"running", "ran"}, Example: "She ran.", Page: 3, Tags: []string{"a", "b"}},
+		{Target: "take off", Forms: []string{"took off"}, Example: "The plane took off.", Page: 2},
+		{Target: "child", Forms: []string{"children"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		for _, w := range got {
+			t.Logf("%+v", *w)
+		}
+		t.Errorf("NormalizeWords returned %d words, want %+v", len(got), want)
+	}
+}
@@ -0,0 +1,44 @@
+package core
+
+// Rect is a rectangle in PDF user space: points from the bottom left corner of the page
+type Rect struct {
+	Llx, Lly, Urx, Ury float64
+}
+
+// PDFAnnotation is an annotation of a PDF page, with the entries used to select and read markup annotations
+type PDFAnnotation struct {
+	Subtype    string // e.g. "Highlight", "Underline", "Link"
+	Contents   string // text stored with the annotation, usually empty for highlights
+	Rect       Rect
+	QuadPoints []float64 // 8 numbers per marked area, usually one area per line
+	Color      []float64 // /C: 1 (gray), 3 (RGB) or 4 (CMYK) numbers from 0 to 1, empty when transparent
+	Author     string    // /T
+	Modified   string    // /M, a PDF date such as "D:20240131235959+01'00'"
+}
+
+// TextMark is a piece of the page text, usually one character, and where it is drawn
+type TextMark struct {
+	Text   string
+	Offset int // byte offset in PageText.Text
+	BBox   Rect
+}
+
+// PageText is the text of a page and the position of its characters
+type PageText struct {
+	Text  string // line breaks are replaced by spaces, so sentences can span lines
+	Marks []TextMark
+}
+
+// PDFDocument is an open PDF file. Pages are numbered from 1.
+type PDFDocument interface {
+	NumPages() int
+	Annotations(page int) ([]*PDFAnnotation, error)
+	Text(page int) (*PageText, error)
+	Close() error
+}
+
+// PDFBackend opens PDF files with a PDF library
+type PDFBackend interface {
+	Name() string
+	Open(path string) (PDFDocument, error)
+}
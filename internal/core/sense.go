@@ -0,0 +1,67 @@
+package core
+
+import (
+	"slices"
+	"strings"
+)
+
+// DefaultMaxSenses is how many senses a flashcard keeps by default
+const DefaultMaxSenses = 3
+
+// partOfSpeechAliases maps abbreviations found in spreadsheets and dictionaries to full names
+var partOfSpeechAliases = map[string]string{
+	"n":      "noun",
+	"v":      "verb",
+	"vb":     "verb",
+	"adj":    "adjective",
+	"adv":    "adverb",
+	"prep":   "preposition",
+	"conj":   "conjunction",
+	"pron":   "pronoun",
+	"intj":   "interjection",
+	"interj": "interjection",
+	"det":    "determiner",
+	"num":    "numeral",
+	"phr":    "phrase",
+}
+
+// NormalizePartOfSpeech lowercases a part of speech and expands abbreviations: "Adj." -> "adjective"
+func NormalizePartOfSpeech(pos string) string {
+	pos = strings.TrimSuffix(NormalizeText(pos), ".")
+	if name, ok := partOfSpeechAliases[pos]; ok {
+		return name
+	}
+	return pos
+}
+
+// RankSenses orders senses for a flashcard, best first: senses of the part of speech from the sheet, then
+// senses whose translations into language share a word with the sheet translation. Senses that rank the same
+// keep the dictionary order, which usually puts the most common meaning first. senses is not modified.
+func RankSenses(senses []Sense, partOfSpeech, translation, language string) []Sense {
+	pos := NormalizePartOfSpeech(partOfSpeech)
+	words := translationWords(translation)
+	score := func(sense *Sense) int {
+		s := 0
+		if pos != "" && NormalizePartOfSpeech(sense.PartOfSpeech) == pos {
+			s += 2
+		}
+		if slices.ContainsFunc(sense.Translations[language], func(t string) bool { return words[NormalizeText(t)] }) {
+			s++
+		}
+		return s
+	}
+	ranked := slices.Clone(senses)
+	slices.SortStableFunc(ranked, func(a, b Sense) int { return score(&b) - score(&a) })
+	return ranked
+}
+
+// translationWords returns the normalized alternatives of a translation cell: "книга; книжка" -> книга, книжка
+func translationWords(translation string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(translation, func(r rune) bool { return strings.ContainsRune(",;/", r) }) {
+		if word = NormalizeText(word); word != "" {
+			words[word] = true
+		}
+	}
+	return words
+}
@@ -0,0 +1,161 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"go.uber.org/zap"
+)
+
+// ErrNoTranslation is returned by translators that have no translation for a word
+var ErrNoTranslation = errors.New("no translation found")
+
+// Translation is a translated word with the backend's confidence in it, from 0 to 1
+type Translation struct {
+	Text       string
+	Confidence float64
+}
+
+// Translator translates words and phrases from the target language of a deck back to its source language
+type Translator interface {
+	Name() string
+	Translate(ctx context.Context, text string) (*Translation, error)
+}
+
+// ExtractedWord is a word extracted from a book in the target language, before it becomes a spreadsheet row
+type ExtractedWord struct {
+	Source       string
+	Target       string
+	Forms        []string // the inflected forms found in the book when Target is their lemma
+	PartOfSpeech string
+	// Review explains why the row should be checked by hand; empty when the translation is trusted
+	Review  string
+	Example string   // the sentence the word was found in
+	Chapter string   // the chapter the word was found in
+	Page    int      // the page the word was found on, 0 if unknown
+	Tags    []string // e.g. the book title
+	Deck    string   // subdeck the word should go to
+}
+
+// TagName turns free text such as a book title into an Anki tag: spaces become underscores and
+// characters other than letters, digits, '-', '_' and ':' are dropped
+func TagName(text string) string {
+	var b strings.Builder
+	for _, word := range strings.Fields(text) {
+		if b.Len() > 0 {
+			b.WriteByte('_')
+		}
+		for _, r := range word {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == ':' {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return strings.Trim(b.String(), "_")
+}
+
+// maxTranslationCandidates is how many alternative translations are put in one cell
+const maxTranslationCandidates = 3
+
+// CandidatesTranslation joins alternative translations into one cell. The confidence is shared
+// between them, so a word with a single known translation gets 1 and an ambiguous one gets less.
+func CandidatesTranslation(candidates []string) (*Translation, error) {
+	if len(candidates) == 0 {
+		return nil, ErrNoTranslation
+	}
+	shown := candidates
+	if len(shown) > maxTranslationCandidates {
+		shown = shown[:maxTranslationCandidates]
+	}
+	return &Translation{
+		Text:       strings.Join(shown, ", "),
+		Confidence: 1 / float64(len(candidates)),
+	}, nil
+}
+
+// TranslatorChain asks translators in order and returns the first translation found
+type TranslatorChain struct {
+	translators []Translator
+	logger      *zap.Logger
+}
+
+// NewTranslatorChain creates a chain of translators, highest priority first
+func NewTranslatorChain(translators []Translator, logger *zap.Logger) *TranslatorChain {
+	return &TranslatorChain{
+		translators: translators,
+		logger:      logger,
+	}
+}
+
+// Name returns the names of the chained translators
+func (c *TranslatorChain) Name() string {
+	names := make([]string, len(c.translators))
+	for i, t := range c.translators {
+		names[i] = t.Name()
+	}
+	return strings.Join(names, "+")
+}
+
+// Translate returns the translation of the first translator that has one
+func (c *TranslatorChain) Translate(ctx context.Context, text string) (*Translation, error) {
+	var lastErr error
+	for _, translator := range c.translators {
+		translation, err := translator.Translate(ctx, text)
+		if err == nil {
+			return translation, nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if !errors.Is(err, ErrNoTranslation) {
+			c.logger.Warn("Translator failed", zap.String("translator", translator.Name()), zap.String("text", text), zap.Error(err))
+			lastErr = err
+		}
+	}
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, fmt.Errorf("text '%s': %w", text, ErrNoTranslation)
+}
+
+// FillTranslations fills the source language column of words that do not have one yet. Translations below
+// minConfidence and words without a translation are kept and marked for review. It returns the
+// number of words marked for review.
+//
+//nolint:lll
+func FillTranslations(
+	ctx context.Context, translator Translator, words []*ExtractedWord, minConfidence float64, logger *zap.Logger,
+) (int, error) {
+	review := 0
+	for _, word := range words {
+		if word.Source != "" {
+			continue
+		}
+
+		translation, err := translator.Translate(ctx, word.Target)
+		switch {
+		case err != nil:
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return review, ctxErr
+			}
+			if errors.Is(err, ErrNoTranslation) {
+				word.Review = "no translation"
+			} else {
+				word.Review = "translation failed"
+			}
+			logger.Debug("No translation", zap.String("target", word.Target), zap.Error(err))
+		case translation.Confidence < minConfidence:
+			word.Source = translation.Text
+			word.Review = fmt.Sprintf("low confidence %.2f", translation.Confidence)
+		default:
+			word.Source = translation.Text
+		}
+		if word.Review != "" {
+			review++
+		}
+	}
+	return review, nil
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+type fakeTranslator map[string][]string
+
+func (f fakeTranslator) Name() string { return "fake" }
+
+func (f fakeTranslator) Translate(_ context.Context, text string) (*Translation, error) {
+	translation, err := CandidatesTranslation(f[text])
+	if err != nil {
+		return nil, fmt.Errorf("text '%s': %w", text, err)
+	}
+	return translation, nil
+}
+
+func TestFillTranslations(t *testing.T) {
+	words := []*ExtractedWord{
+		{Target: "apple"},
+		{Target: "key"},
+		{Target: "serendipity"},
+		{Target: "book", Source: "книжка"},
+	}
+	translator := fakeTranslator{
+		"apple": {"яблоко"},
+		"key":   {"ключ", "клавиша"},
+		"book":  {"книга"},
+	}
+
+	review, err := FillTranslations(context.Background(), translator, words, 0.7, zap.NewNop())
+	if err != nil {
+		t.Fatalf("FillTranslations: %v", err)
+	}
+	if review != 2 {
+		t.Errorf("review = %d, want 2", review)
+	}
+
+	want := []struct{ source, review string }{
+		{"яблоко", ""},
+		{"ключ, клавиша", "low confidence 0.50"},
+		{"", "no translation"},
+		{"книжка", ""}, // existing translations are kept
+	}
+	for i, w := range want {
+		if words[i].Source != w.source || words[i].Review != w.review {
+			t.Errorf("%s: got (%q, %q), want (%q, %q)", words[i].Target, words[i].Source, words[i].Review, w.source, w.review)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+package core
+
+// WordSource reads the word pairs a deck is built from, e.g. an Excel workbook or a CSV export
+type WordSource interface {
+	Name() string
+	// Validate checks that the file has a usable layout and at least one word pair
+	Validate(path string) error
+	ReadWordPairs(path string) ([]*RawFlashcard, error)
+}
@@ -0,0 +1,113 @@
+// Package dictionary provides local dictionary sources that work without network access.
+package dictionary
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"go.uber.org/zap"
+)
+
+// GlossaryProviderName identifies the user glossary in provider chains and the enrichment cache
+const GlossaryProviderName = "glossary"
+
+// GlossaryEntry is a single entry of a user glossary file
+type GlossaryEntry struct {
+	Word         string `json:"word"`
+	PartOfSpeech string `json:"part_of_speech,omitempty"`
+	Definition   string `json:"definition,omitempty"`
+	Example      string `json:"example,omitempty"`
+	IPAUK        string `json:"ipa_uk,omitempty"`
+	IPAUS        string `json:"ipa_us,omitempty"`
+	AudioUK      string `json:"audio_uk,omitempty"` // URL
+	AudioUS      string `json:"audio_us,omitempty"` // URL
+}
+
+// Glossary answers lookups from a JSON file maintained by the user, e.g. for idioms
+// or domain words that online dictionaries do not know
+type Glossary struct {
+	entries map[string][]GlossaryEntry
+	logger  *zap.Logger
+}
+
+// LoadGlossary reads a glossary file containing a JSON array of GlossaryEntry
+func LoadGlossary(path string, logger *zap.Logger) (*Glossary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read glossary: %w", err)
+	}
+
+	var entries []GlossaryEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse glossary: %w", err)
+	}
+
+	g := &Glossary{
+		entries: make(map[string][]GlossaryEntry, len(entries)),
+		logger:  logger,
+	}
+	for _, entry := range entries { //nolint:gocritic
+		if entry.Word == "" {
+			continue
+		}
+		key := core.NormalizeText(entry.Word)
+		g.entries[key] = append(g.entries[key], entry)
+	}
+
+	logger.Info("Loaded glossary", zap.String("path", path), zap.Int("words", len(g.entries)))
+	return g, nil
+}
+
+// Name implements core.DictionaryProvider
+func (g *Glossary) Name() string {
+	return GlossaryProviderName
+}
+
+// Lookup implements core.DictionaryProvider. Entries matching the part of speech come first.
+func (g *Glossary) Lookup(_ context.Context, word, partOfSpeech string) (*core.DictionaryEntry, error) {
+	entries := g.entries[core.NormalizeText(word)]
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%s: word '%s': %w", GlossaryProviderName, word, core.ErrWordNotFound)
+	}
+
+	pos := core.NormalizePartOfSpeech(partOfSpeech)
+	ordered := make([]GlossaryEntry, 0, len(entries))
+	for _, entry := range entries { //nolint:gocritic
+		if pos != "" && core.NormalizePartOfSpeech(entry.PartOfSpeech) == pos {
+			ordered = append(ordered, entry)
+		}
+	}
+	for _, entry := range entries { //nolint:gocritic
+		if pos == "" || core.NormalizePartOfSpeech(entry.PartOfSpeech) != pos {
+			ordered = append(ordered, entry)
+		}
+	}
+
+	result := &core.DictionaryEntry{Word: ordered[0].Word}
+	for _, entry := range ordered { //nolint:gocritic
+		if entry.Definition != "" {
+			result.Senses = append(result.Senses, core.Sense{
+				PartOfSpeech: entry.PartOfSpeech,
+				Definition:   entry.Definition,
+				Example:      entry.Example,
+			})
+		}
+		if result.IPAUK == "" {
+			result.IPAUK = entry.IPAUK
+		}
+		if result.IPAUS == "" {
+			result.IPAUS = entry.IPAUS
+		}
+		if result.AudioUK == "" {
+			result.AudioUK = entry.AudioUK
+		}
+		if result.AudioUS == "" {
+			result.AudioUS = entry.AudioUS
+		}
+	}
+	return result, nil
+}
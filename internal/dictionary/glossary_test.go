@@ -0,0 +1,46 @@
+package dictionary
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestGlossary_LookupPartOfSpeech(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "glossary.json")
+	data := `[
+		{"word": "run", "part_of_speech": "verb", "definition": "To move quickly on foot"},
+		{"word": "run", "part_of_speech": "Noun", "definition": "An act of running"},
+		{"word": "fast", "part_of_speech": "adj", "definition": "Moving quickly"},
+		{"word": "fast", "part_of_speech": "verb", "definition": "To go without food"}
+	]`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	g, err := LoadGlossary(path, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Parts of speech are compared like core.RankSenses does: abbreviations and case do not matter
+	tests := []struct {
+		word, pos, want string
+	}{
+		{"run", "n.", "An act of running"},
+		{"run", "", "To move quickly on foot"},
+		{"fast", "Adjective", "Moving quickly"},
+		{"fast", "v", "To go without food"},
+	}
+	for _, tt := range tests {
+		entry, err := g.Lookup(context.Background(), tt.word, tt.pos)
+		if err != nil {
+			t.Fatalf("Lookup(%s): %v", tt.word, err)
+		}
+		if entry.Senses[0].Definition != tt.want {
+			t.Errorf("Lookup(%s, %q): first sense %q, want %q", tt.word, tt.pos, entry.Senses[0].Definition, tt.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package dictionary
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"go.uber.org/zap"
+)
+
+// TranslationFileName identifies the bilingual word list in --translator
+const TranslationFileName = "file"
+
+// TranslationFile translates words from a bilingual word list: a TSV (or .csv) file with the word in the
+// target language (e.g. English) in the first column and its translation in the source language (e.g. Russian)
+// in the second. A word may appear on several lines.
+type TranslationFile struct {
+	translations map[string][]string
+	logger       *zap.Logger
+}
+
+// LoadTranslationFile reads a bilingual word list; empty lines and lines starting with '#' are ignored
+func LoadTranslationFile(path string, logger *zap.Logger) (*TranslationFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open translation file: %w", err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.Comma = '\t'
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		reader.Comma = ','
+	}
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	t := &TranslationFile{
+		translations: make(map[string][]string),
+		logger:       logger,
+	}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse translation file: %w", err)
+		}
+		if len(record) < 2 { //nolint:mnd
+			continue
+		}
+		word, translation := core.NormalizeText(record[0]), strings.TrimSpace(record[1])
+		if word == "" || translation == "" {
+			continue
+		}
+		t.translations[word] = appendUnique(t.translations[word], translation)
+	}
+
+	logger.Info("Loaded translation file", zap.String("path", path), zap.Int("words", len(t.translations)))
+	return t, nil
+}
+
+// Name implements core.Translator
+func (t *TranslationFile) Name() string {
+	return TranslationFileName
+}
+
+// Translate implements core.Translator
+func (t *TranslationFile) Translate(_ context.Context, text string) (*core.Translation, error) {
+	translation, err := core.CandidatesTranslation(t.translations[core.NormalizeText(text)])
+	if err != nil {
+		return nil, fmt.Errorf("%s: text '%s': %w", TranslationFileName, text, err)
+	}
+	return translation, nil
+}
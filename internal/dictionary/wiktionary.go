@@ -0,0 +1,194 @@
+package dictionary
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite" // pure-Go SQLite driver
+)
+
+// WiktionaryProviderName identifies the imported Wiktionary dump in provider chains
+const WiktionaryProviderName = "wiktionary"
+
+// wiktionarySchema is the layout of the local store built by ImportWiktionary
+const wiktionarySchema = `
+CREATE TABLE entries (
+    word_key TEXT NOT NULL,
+    pos      TEXT NOT NULL,
+    data     TEXT NOT NULL
+);
+CREATE TABLE info (
+    key   TEXT PRIMARY KEY,
+    value TEXT NOT NULL
+);
+`
+
+// wiktionaryIndex is created after the bulk insert, which is much faster than maintaining it row by row
+const wiktionaryIndex = `CREATE INDEX entries_word_key ON entries (word_key);`
+
+// wiktionaryRecord is one dictionary entry (word + part of speech) as stored in the data column
+type wiktionaryRecord struct {
+	Word         string              `json:"word"`
+	PartOfSpeech string              `json:"part_of_speech"`
+	Senses       []core.Sense        `json:"senses"`
+	IPAUK        string              `json:"ipa_uk,omitempty"`
+	IPAUS        string              `json:"ipa_us,omitempty"`
+	AudioUK      string              `json:"audio_uk,omitempty"` // URL
+	AudioUS      string              `json:"audio_us,omitempty"` // URL
+	Translations map[string][]string `json:"translations,omitempty"`
+}
+
+// Wiktionary answers lookups from a local store imported from a Wiktextract (kaikki.org) dump.
+// It covers phrasal verbs and idioms and works without network access.
+type Wiktionary struct {
+	db          *sql.DB
+	language    string // language of the entries
+	translateTo string // language of translations returned by Translate
+	logger      *zap.Logger
+}
+
+// OpenWiktionary opens a store created with `anki-builder dict import-wiktionary`
+func OpenWiktionary(path string, logger *zap.Logger) (*Wiktionary, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to open wiktionary store (run 'anki-builder dict import-wiktionary' first): %w", err)
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open wiktionary store: %w", err)
+	}
+
+	var entries, language string
+	err = db.QueryRow(`SELECT (SELECT value FROM info WHERE key = 'entries'), (SELECT value FROM info WHERE key = 'language')`).
+		Scan(&entries, &language)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to read wiktionary store: %w", err)
+	}
+
+	logger.Info("Opened wiktionary store", zap.String("path", path), zap.String("language", language), zap.String("entries", entries))
+	return &Wiktionary{
+		db:          db,
+		language:    language,
+		translateTo: core.DefaultLanguagePair.Source,
+		logger:      logger,
+	}, nil
+}
+
+// Language returns the language code of the imported entries
+func (w *Wiktionary) Language() string {
+	return w.language
+}
+
+// SetTranslationLanguage selects the language of translations returned by Translate (default "ru")
+func (w *Wiktionary) SetTranslationLanguage(language string) {
+	w.translateTo = language
+}
+
+// Close closes the underlying database
+func (w *Wiktionary) Close() error {
+	return w.db.Close()
+}
+
+// Name implements core.DictionaryProvider
+func (w *Wiktionary) Name() string {
+	return WiktionaryProviderName
+}
+
+// Lookup implements core.DictionaryProvider. Entries matching the part of speech come first.
+func (w *Wiktionary) Lookup(ctx context.Context, word, partOfSpeech string) (*core.DictionaryEntry, error) {
+	rows, err := w.db.QueryContext(ctx, `SELECT data FROM entries WHERE word_key = ? ORDER BY rowid`, core.NormalizeText(word))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query wiktionary store: %w", err)
+	}
+	defer rows.Close()
+
+	var records []wiktionaryRecord
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read wiktionary entry: %w", err)
+		}
+		var record wiktionaryRecord
+		if err := json.Unmarshal([]byte(data), &record); err != nil {
+			return nil, fmt.Errorf("failed to decode wiktionary entry: %w", err)
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query wiktionary store: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%s: word '%s': %w", WiktionaryProviderName, word, core.ErrWordNotFound)
+	}
+
+	pos := core.NormalizePartOfSpeech(partOfSpeech)
+	ordered := make([]wiktionaryRecord, 0, len(records))
+	for _, record := range records { //nolint:gocritic
+		if pos != "" && core.NormalizePartOfSpeech(record.PartOfSpeech) == pos {
+			ordered = append(ordered, record)
+		}
+	}
+	for _, record := range records { //nolint:gocritic
+		if pos == "" || core.NormalizePartOfSpeech(record.PartOfSpeech) != pos {
+			ordered = append(ordered, record)
+		}
+	}
+
+	result := &core.DictionaryEntry{Word: ordered[0].Word}
+	for _, record := range ordered { //nolint:gocritic
+		result.Senses = append(result.Senses, record.Senses...)
+		if result.IPAUK == "" {
+			result.IPAUK = record.IPAUK
+		}
+		if result.IPAUS == "" {
+			result.IPAUS = record.IPAUS
+		}
+		if result.AudioUK == "" {
+			result.AudioUK = record.AudioUK
+		}
+		if result.AudioUS == "" {
+			result.AudioUS = record.AudioUS
+		}
+		for lang, words := range record.Translations {
+			if result.Translations == nil {
+				result.Translations = make(map[string][]string)
+			}
+			result.Translations[lang] = appendUnique(result.Translations[lang], words...)
+		}
+	}
+	return result, nil
+}
+
+// Translate implements core.Translator with the translations stored for the word
+func (w *Wiktionary) Translate(ctx context.Context, text string) (*core.Translation, error) {
+	entry, err := w.Lookup(ctx, text, "")
+	if err != nil {
+		if errors.Is(err, core.ErrWordNotFound) {
+			return nil, fmt.Errorf("%s: text '%s': %w", WiktionaryProviderName, text, core.ErrNoTranslation)
+		}
+		return nil, err
+	}
+	translation, err := core.CandidatesTranslation(entry.Translations[w.translateTo])
+	if err != nil {
+		return nil, fmt.Errorf("%s: text '%s': %w", WiktionaryProviderName, text, err)
+	}
+	return translation, nil
+}
+
+// appendUnique appends values that are not in list yet
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		if !slices.Contains(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -0,0 +1,366 @@
+package dictionary
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"go.uber.org/zap"
+)
+
+// kaikkiEntry is one line of a Wiktextract JSONL dump (https://kaikki.org/dictionary/rawdata.html),
+// limited to the fields used for flashcards
+type kaikkiEntry struct {
+	Word         string              `json:"word"`
+	Pos          string              `json:"pos"`
+	Lang         string              `json:"lang"`
+	LangCode     string              `json:"lang_code"`
+	Senses       []kaikkiSense       `json:"senses"`
+	Sounds       []kaikkiSound       `json:"sounds"`
+	Translations []kaikkiTranslation `json:"translations"`
+}
+
+type kaikkiSense struct {
+	Glosses  []string `json:"glosses"`
+	Examples []struct {
+		Text string `json:"text"`
+		Type string `json:"type"`
+	} `json:"examples"`
+	Tags         []string            `json:"tags"`
+	Translations []kaikkiTranslation `json:"translations"`
+	Synonyms     []kaikkiLinkage     `json:"synonyms"`
+	Antonyms     []kaikkiLinkage     `json:"antonyms"`
+}
+
+// kaikkiLinkage is a related word, e.g. a synonym
+type kaikkiLinkage struct {
+	Word string `json:"word"`
+}
+
+type kaikkiSound struct {
+	IPA    string   `json:"ipa"`
+	Audio  string   `json:"audio"`
+	MP3URL string   `json:"mp3_url"`
+	Tags   []string `json:"tags"`
+}
+
+type kaikkiTranslation struct {
+	Code     string `json:"code"`
+	LangCode string `json:"lang_code"`
+	Word     string `json:"word"`
+	Sense    string `json:"sense"` // gloss of the sense translated by an entry-level translation
+}
+
+// WiktionaryImportOptions selects what is kept from a dump
+type WiktionaryImportOptions struct {
+	// Language is the language code of the entries to import, e.g. "en"
+	Language string
+	// TranslationLanguages are the language codes of translations to keep, e.g. ["ru"]
+	TranslationLanguages []string
+}
+
+// kaikkiPartsOfSpeech maps the Wiktextract part of speech codes that core.NormalizePartOfSpeech does not
+// know to the names used in spreadsheets
+var kaikkiPartsOfSpeech = map[string]string{
+	"name":        "proper noun",
+	"prep_phrase": "phrase",
+}
+
+// importProgressEvery is how often (in dump lines) import progress is logged
+const importProgressEvery = 100000
+
+// ImportWiktionary reads a Wiktextract JSONL dump (optionally gzip-compressed) and writes a store
+// for OpenWiktionary to dbPath, replacing any previous store. It returns the number of imported entries.
+//
+//nolint:gocyclo
+func ImportWiktionary(ctx context.Context, dumpPath, dbPath string, opts WiktionaryImportOptions, logger *zap.Logger) (int, error) {
+	dump, err := openDump(dumpPath)
+	if err != nil {
+		return 0, err
+	}
+	defer dump.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil { //nolint:mnd
+		return 0, fmt.Errorf("failed to create store directory: %w", err)
+	}
+	// Build the store next to the target and rename it at the end, so a failed import keeps the old store
+	tmpPath := dbPath + ".tmp"
+	os.Remove(tmpPath)
+	defer os.Remove(tmpPath)
+
+	db, err := sql.Open("sqlite", tmpPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create wiktionary store: %w", err)
+	}
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, `PRAGMA journal_mode = OFF; PRAGMA synchronous = OFF;`); err != nil {
+		return 0, fmt.Errorf("failed to configure wiktionary store: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, wiktionarySchema); err != nil {
+		return 0, fmt.Errorf("failed to create wiktionary schema: %w", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO entries (word_key, pos, data) VALUES (?, ?, ?)`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	reader := bufio.NewReaderSize(dump, 1<<20) //nolint:mnd
+	lines, imported, skipped := 0, 0, 0
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if len(strings.TrimSpace(string(line))) > 0 {
+			lines++
+			if lines%importProgressEvery == 0 {
+				if err := ctx.Err(); err != nil {
+					return 0, err
+				}
+				logger.Info("Importing wiktionary dump", zap.Int("lines", lines), zap.Int("entries", imported))
+			}
+
+			var entry kaikkiEntry
+			if err := json.Unmarshal(line, &entry); err != nil {
+				skipped++
+				logger.Debug("Skipping malformed dump line", zap.Int("line", lines), zap.Error(err))
+			} else if record := toWiktionaryRecord(&entry, opts); record != nil {
+				data, err := json.Marshal(record)
+				if err != nil {
+					return 0, fmt.Errorf("failed to encode wiktionary entry: %w", err)
+				}
+				if _, err := stmt.ExecContext(ctx, core.NormalizeText(record.Word), record.PartOfSpeech, string(data)); err != nil {
+					return 0, fmt.Errorf("failed to insert wiktionary entry: %w", err)
+				}
+				imported++
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return 0, fmt.Errorf("failed to read dump: %w", readErr)
+		}
+	}
+
+	info := map[string]string{
+		"source":      filepath.Base(dumpPath),
+		"language":    opts.Language,
+		"entries":     strconv.Itoa(imported),
+		"imported_at": time.Now().UTC().Format(time.RFC3339),
+	}
+	for key, value := range info {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO info (key, value) VALUES (?, ?)`, key, value); err != nil {
+			return 0, fmt.Errorf("failed to write store info: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit wiktionary store: %w", err)
+	}
+
+	logger.Info("Indexing wiktionary store", zap.Int("entries", imported))
+	if _, err := db.ExecContext(ctx, wiktionaryIndex); err != nil {
+		return 0, fmt.Errorf("failed to index wiktionary store: %w", err)
+	}
+	if err := db.Close(); err != nil {
+		return 0, fmt.Errorf("failed to close wiktionary store: %w", err)
+	}
+	if err := os.Rename(tmpPath, dbPath); err != nil {
+		return 0, fmt.Errorf("failed to replace wiktionary store: %w", err)
+	}
+
+	if skipped > 0 {
+		logger.Warn("Skipped malformed dump lines", zap.Int("lines", skipped))
+	}
+	return imported, nil
+}
+
+// openDump opens a dump file, decompressing .gz files
+func openDump(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dump: %w", err)
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return f, nil
+	}
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to decompress dump: %w", err)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{gz, f}, nil
+}
+
+// toWiktionaryRecord converts a dump entry; it returns nil for entries in other languages or without definitions
+func toWiktionaryRecord(entry *kaikkiEntry, opts WiktionaryImportOptions) *wiktionaryRecord {
+	if entry.Word == "" || (opts.Language != "" && entry.LangCode != "" && entry.LangCode != opts.Language) {
+		return nil
+	}
+
+	record := &wiktionaryRecord{
+		Word:         entry.Word,
+		PartOfSpeech: kaikkiPartOfSpeech(entry.Pos),
+	}
+
+	translations := entry.Translations
+	for _, sense := range entry.Senses { //nolint:gocritic
+		translations = append(translations, sense.Translations...)
+		if len(sense.Glosses) == 0 {
+			continue
+		}
+		definition := sense.Glosses[len(sense.Glosses)-1] // the most specific gloss of nested senses
+		record.Senses = append(record.Senses, core.Sense{
+			PartOfSpeech: record.PartOfSpeech,
+			Definition:   definition,
+			Example:      senseExample(&sense),
+			Synonyms:     linkageWords(sense.Synonyms),
+			Antonyms:     linkageWords(sense.Antonyms),
+			Translations: senseTranslations(definition, sense.Translations, entry.Translations, opts.TranslationLanguages),
+		})
+	}
+	if len(record.Senses) == 0 {
+		return nil
+	}
+
+	var ipa string
+	for _, sound := range entry.Sounds { //nolint:gocritic
+		switch soundRegion(&sound) {
+		case "uk":
+			fillEmptyString(&record.IPAUK, sound.IPA)
+			fillEmptyString(&record.AudioUK, sound.MP3URL)
+		case "us":
+			fillEmptyString(&record.IPAUS, sound.IPA)
+			fillEmptyString(&record.AudioUS, sound.MP3URL)
+		default:
+			fillEmptyString(&ipa, sound.IPA)
+		}
+	}
+	// Untagged pronunciations are shared by both accents
+	fillEmptyString(&record.IPAUK, ipa)
+	fillEmptyString(&record.IPAUS, ipa)
+
+	for _, t := range translations { //nolint:gocritic
+		code := t.Code
+		if code == "" {
+			code = t.LangCode
+		}
+		if t.Word == "" || !slices.Contains(opts.TranslationLanguages, code) {
+			continue
+		}
+		if record.Translations == nil {
+			record.Translations = make(map[string][]string)
+		}
+		record.Translations[code] = appendUnique(record.Translations[code], t.Word)
+	}
+
+	return record
+}
+
+// senseTranslations returns the translations of a sense: its own, and the entry-level ones whose sense gloss
+// starts the definition (translation tables are headed by a short gloss of the sense)
+func senseTranslations(definition string, own, entry []kaikkiTranslation, languages []string) map[string][]string {
+	gloss := core.NormalizeText(definition)
+	var result map[string][]string
+	add := func(t *kaikkiTranslation) {
+		code := t.Code
+		if code == "" {
+			code = t.LangCode
+		}
+		if t.Word == "" || !slices.Contains(languages, code) {
+			return
+		}
+		if result == nil {
+			result = make(map[string][]string)
+		}
+		result[code] = appendUnique(result[code], t.Word)
+	}
+	for i := range own {
+		add(&own[i])
+	}
+	for i := range entry {
+		if sense := core.NormalizeText(entry[i].Sense); sense != "" && strings.HasPrefix(gloss, sense) {
+			add(&entry[i])
+		}
+	}
+	return result
+}
+
+// linkageWords returns the words of related-word lists
+func linkageWords(linkages []kaikkiLinkage) []string {
+	var words []string
+	for _, linkage := range linkages {
+		if linkage.Word != "" {
+			words = appendUnique(words, linkage.Word)
+		}
+	}
+	return words
+}
+
+// senseExample returns the first usage example of a sense, preferring short examples over quotations
+func senseExample(sense *kaikkiSense) string {
+	for _, example := range sense.Examples {
+		if example.Type != "quotation" && example.Text != "" {
+			return example.Text
+		}
+	}
+	return ""
+}
+
+// soundRegion classifies a pronunciation as "uk", "us" or "" (unknown) by its tags or audio file name
+func soundRegion(sound *kaikkiSound) string {
+	for _, tag := range sound.Tags {
+		switch tag {
+		case "UK", "Received-Pronunciation", "British":
+			return "uk"
+		case "US", "General-American", "American":
+			return "us"
+		}
+	}
+	audio := strings.ToLower(sound.Audio)
+	switch {
+	case strings.HasPrefix(audio, "en-uk-"):
+		return "uk"
+	case strings.HasPrefix(audio, "en-us-"):
+		return "us"
+	}
+	return ""
+}
+
+// kaikkiPartOfSpeech maps a Wiktextract part of speech like "adj" or "name" to the full name used in
+// spreadsheets
+func kaikkiPartOfSpeech(pos string) string {
+	if name, ok := kaikkiPartsOfSpeech[core.NormalizeText(pos)]; ok {
+		return name
+	}
+	return core.NormalizePartOfSpeech(pos)
+}
+
+func fillEmptyString(dst *string, src string) {
+	if *dst == "" {
+		*dst = src
+	}
+}
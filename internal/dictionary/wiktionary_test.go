@@ -0,0 +1,83 @@
+package dictionary
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"go.uber.org/zap"
+)
+
+func TestWiktionary_ImportAndLookup(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "wiktionary.db")
+
+	imported, err := ImportWiktionary(ctx, filepath.Join("testdata", "kaikki.jsonl"), dbPath, WiktionaryImportOptions{
+		Language:             "en",
+		TranslationLanguages: []string{"ru"},
+	}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("ImportWiktionary: %v", err)
+	}
+	if imported != 4 {
+		t.Errorf("imported %d entries, want 4 (German entry and malformed line skipped)", imported)
+	}
+
+	w, err := OpenWiktionary(dbPath, zap.NewNop())
+	if err != nil {
+		t.Fatalf("OpenWiktionary: %v", err)
+	}
+	defer w.Close()
+
+	entry, err := w.Lookup(ctx, "Apple", "noun")
+	if err != nil {
+		t.Fatalf("Lookup(apple): %v", err)
+	}
+	if entry.IPAUK != "/ˈæp.əl/" || entry.AudioUS != "https://upload.wikimedia.org/en-us-apple.ogg.mp3" {
+		t.Errorf("unexpected pronunciation: %+v", entry)
+	}
+	if entry.Senses[0].Example != "She ate an apple for lunch." {
+		t.Errorf("unexpected example: %q", entry.Senses[0].Example)
+	}
+	if got := entry.Translations["ru"]; len(got) != 1 || got[0] != "яблоко" {
+		t.Errorf("unexpected translations: %v", entry.Translations)
+	}
+	if _, ok := entry.Translations["de"]; ok {
+		t.Error("translations to languages not selected for import should be dropped")
+	}
+
+	entry, err = w.Lookup(ctx, "keep in mind", "phrase")
+	if err != nil {
+		t.Fatalf("Lookup(keep in mind): %v", err)
+	}
+	if entry.Translations["ru"][0] != "иметь в виду" {
+		t.Errorf("expected sense-level translation, got %v", entry.Translations)
+	}
+	if got := entry.Senses[0].Translations["ru"]; len(got) != 1 || got[0] != "иметь в виду" {
+		t.Errorf("expected the translation on its sense, got %v", entry.Senses[0].Translations)
+	}
+
+	// The part of speech hint puts matching entries first, abbreviated like in sheets; "adj" is stored as "adjective"
+	entry, err = w.Lookup(ctx, "fast", "V.")
+	if err != nil {
+		t.Fatalf("Lookup(fast): %v", err)
+	}
+	if len(entry.Senses) != 2 || entry.Senses[0].PartOfSpeech != "verb" || entry.Senses[1].PartOfSpeech != "adjective" {
+		t.Errorf("unexpected senses: %+v", entry.Senses)
+	}
+	if entry.IPAUS != "/fɑːst/" {
+		t.Errorf("untagged IPA should be used for both accents, got %q", entry.IPAUS)
+	}
+
+	translation, err := w.Translate(ctx, "keep in mind")
+	if err != nil || translation.Text != "иметь в виду" || translation.Confidence != 1 {
+		t.Errorf("Translate(keep in mind) = %+v, %v", translation, err)
+	}
+
+	if _, err := w.Lookup(ctx, "Apfel", ""); !errors.Is(err, core.ErrWordNotFound) {
+		t.Errorf("expected ErrWordNotFound, got %v", err)
+	}
+}
@@ -2,6 +2,9 @@ package downloader
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -9,6 +12,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/util"
@@ -20,7 +24,12 @@ import (
 type Downloader struct {
 	client   *http.Client
 	mediaDir string
+	limiter  *util.RateLimiter
+	retry    *util.RetryPolicy
 	logger   *zap.Logger
+
+	mu       sync.Mutex
+	manifest map[string]manifestEntry // loaded on first use, see manifest.go
 }
 
 // NewDownloader creates a new media downloader
@@ -30,10 +39,21 @@ func NewDownloader(mediaDir string, logger *zap.Logger) *Downloader {
 			Timeout: 60 * time.Second,
 		},
 		mediaDir: mediaDir,
+		retry:    util.DefaultRetryPolicy(),
 		logger:   logger,
 	}
 }
 
+// SetRateLimiter limits how often files are downloaded; nil means unlimited
+func (d *Downloader) SetRateLimiter(limiter *util.RateLimiter) {
+	d.limiter = limiter
+}
+
+// SetTransport replaces the HTTP transport, e.g. to replay downloads in tests; nil means the default
+func (d *Downloader) SetTransport(transport http.RoundTripper) {
+	d.client.Transport = transport
+}
+
 // sanitizeFilename removes or replaces special characters to make filename safe
 func sanitizeFilename(filename string) string {
 	// Replace spaces and special characters with underscores
@@ -50,60 +70,171 @@ func sanitizeFilename(filename string) string {
 	return sanitized
 }
 
-// downloadFile is a helper for downloading a file from a URL and saving it to the media directory.
-func (d *Downloader) downloadFile(ctx context.Context, url, filename, logType string) (string, error) {
+// downloadFile downloads a file of the given kind into the media directory and returns its name there.
+// The file is written to a temporary file and renamed when complete and valid, so an interrupted download
+// never leaves a partial file behind. Its extension is set from the content, and its checksum is recorded
+// so a file that was damaged or replaced since is downloaded again.
+func (d *Downloader) downloadFile(ctx context.Context, url, filename string, kind *mediaKind) (string, error) {
 	safeFilename := sanitizeFilename(filename)
-	d.logger.Debug("Downloading "+logType, zap.String("url", url), zap.String("filename", safeFilename))
+	d.logger.Debug("Downloading "+kind.name, zap.String("url", url), zap.String("filename", safeFilename))
 
 	if err := os.MkdirAll(d.mediaDir, 0755); err != nil { //nolint:mnd
 		return "", fmt.Errorf("failed to create media directory: %w", err)
 	}
-
-	filePath := filepath.Join(d.mediaDir, safeFilename)
-	if _, err := os.Stat(filePath); err == nil {
-		d.logger.Debug(logType+" already exists", zap.String("path", filePath))
-		return safeFilename, nil
+	if name, ok := d.cached(safeFilename, kind); ok {
+		d.logger.Debug(kind.name+" already exists", zap.String("path", filepath.Join(d.mediaDir, name)))
+		return name, nil
 	}
 
-	var resp *http.Response
-	err := util.RetryWithBackoff(ctx, 3, func() error {
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil) //nolint:gocritic
-		if err != nil {
-			return fmt.Errorf("failed to create request: %w", err)
-		}
-		resp, err = d.client.Do(req) //nolint:bodyclose
-		if err != nil {
-			return fmt.Errorf("failed to download "+logType+": %w", err)
-		}
-		return nil
+	resp, err := d.retry.Do(ctx, d.client, d.limiter, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, "GET", url, http.NoBody) //nolint:gocritic
 	})
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("failed to download %s: %w", kind.name, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to download %s: status %d", logType, resp.StatusCode)
+		return "", fmt.Errorf("failed to download %s: status %d", kind.name, resp.StatusCode)
+	}
+	if err := kind.checkContentType(resp.Header.Get("Content-Type")); err != nil {
+		return "", fmt.Errorf("failed to download %s from %s: %w", kind.name, url, err)
+	}
+	if resp.ContentLength > kind.maxSize {
+		return "", fmt.Errorf("failed to download %s from %s: %w: %d bytes is over the %d byte limit",
+			kind.name, url, ErrInvalidMedia, resp.ContentLength, kind.maxSize)
+	}
+
+	name, checksum, err := d.save(resp.Body, safeFilename, kind)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s from %s: %w", kind.name, url, err)
 	}
+	d.record(safeFilename, name, checksum)
+	d.logger.Debug("Successfully downloaded "+kind.name, zap.String("path", filepath.Join(d.mediaDir, name)))
+	return name, nil
+}
 
-	file, err := os.Create(filePath)
+// save writes a download to a temporary file, checks its size and format, and renames it to filename with
+// the extension of the format. It returns the final name and the SHA-256 of the content.
+func (d *Downloader) save(body io.Reader, filename string, kind *mediaKind) (string, string, error) {
+	tmp, err := os.CreateTemp(d.mediaDir, "."+filename+"-*")
 	if err != nil {
-		return "", fmt.Errorf("failed to create file: %w", err)
+		return "", "", fmt.Errorf("failed to create file: %w", err)
 	}
-	defer file.Close()
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
 
-	_, err = io.Copy(file, resp.Body)
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, h), io.LimitReader(body, kind.maxSize+1))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to write file: %w", err)
+	}
+	if n > kind.maxSize {
+		return "", "", fmt.Errorf("%w: over the %d byte limit", ErrInvalidMedia, kind.maxSize)
+	}
+	head := make([]byte, sniffLen)
+	m, err := tmp.ReadAt(head, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", "", fmt.Errorf("failed to read file: %w", err)
+	}
+	format, err := kind.detect(head[:m])
 	if err != nil {
-		return "", fmt.Errorf("failed to write file: %w", err)
+		return "", "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", "", fmt.Errorf("failed to write file: %w", err)
+	}
+
+	name := strings.TrimSuffix(filename, filepath.Ext(filename)) + format.ext
+	if err := os.Rename(tmp.Name(), filepath.Join(d.mediaDir, name)); err != nil {
+		return "", "", fmt.Errorf("failed to write file: %w", err)
+	}
+	return name, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// cached returns the name of an earlier download of filename if its content is unchanged. A damaged or
+// missing file is removed from the manifest so it is downloaded again. A file downloaded before checksums were
+// recorded may have been cut short, so it is only kept if its format tells that it is complete. Files are hashed
+// without holding d.mu, so workers only wait for each other to read or update the manifest.
+func (d *Downloader) cached(filename string, kind *mediaKind) (string, bool) {
+	d.mu.Lock()
+	d.loadManifest()
+	entry, recorded := d.manifest[filename]
+	d.mu.Unlock()
+
+	if recorded {
+		path := filepath.Join(d.mediaDir, entry.File)
+		if checksum, err := fileChecksum(path); err == nil && checksum == entry.SHA256 {
+			return entry.File, true
+		}
+		d.logger.Warn("Cached media file is missing or damaged, downloading it again", zap.String("path", path))
+		os.Remove(path)
+		d.mu.Lock()
+		if d.manifest[filename] == entry {
+			delete(d.manifest, filename)
+		}
+		d.mu.Unlock()
+		return "", false
+	}
+
+	path := filepath.Join(d.mediaDir, filename)
+	checksum, err := legacyChecksum(path, kind)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", false
+	}
+	if err != nil {
+		d.logger.Warn("Cached media file may be incomplete, downloading it again", zap.String("path", path), zap.Error(err))
+		os.Remove(path)
+		return "", false
+	}
+	d.record(filename, filename, checksum)
+	return filename, true
+}
+
+// record adds a finished download to the manifest
+func (d *Downloader) record(filename, name, checksum string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.loadManifest()
+
+	d.manifest[filename] = manifestEntry{File: name, SHA256: checksum}
+	if err := d.saveManifest(); err != nil {
+		d.logger.Warn("Failed to save download manifest", zap.Error(err))
 	}
-	d.logger.Debug("Successfully downloaded "+logType, zap.String("path", filePath))
-	return safeFilename, nil
 }
 
 func (d *Downloader) DownloadImage(ctx context.Context, imageURL, filename string) (string, error) {
-	return d.downloadFile(ctx, imageURL, filename, "image")
+	return d.downloadFile(ctx, imageURL, filename, imageKind)
 }
 
 func (d *Downloader) DownloadAudio(ctx context.Context, audioURL, filename string) (string, error) {
-	return d.downloadFile(ctx, audioURL, filename, "audio")
+	return d.downloadFile(ctx, audioURL, filename, audioKind)
+}
+
+// CopyFile copies a local image (e.g. from a user image folder) into the media directory, with the same
+// checks as a download: the copy is renamed into place when complete, must be an image within the size limit,
+// gets the extension of its format and a checksum in the manifest
+func (d *Downloader) CopyFile(srcPath, filename string) (string, error) {
+	safeFilename := sanitizeFilename(filename)
+	if err := os.MkdirAll(d.mediaDir, 0755); err != nil { //nolint:mnd
+		return "", fmt.Errorf("failed to create media directory: %w", err)
+	}
+	if name, ok := d.cached(safeFilename, imageKind); ok {
+		d.logger.Debug("File already exists", zap.String("path", filepath.Join(d.mediaDir, name)))
+		return name, nil
+	}
+
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer src.Close()
+
+	name, checksum, err := d.save(src, safeFilename, imageKind)
+	if err != nil {
+		return "", fmt.Errorf("failed to copy %s: %w", srcPath, err)
+	}
+	d.record(safeFilename, name, checksum)
+	d.logger.Debug("Copied local file", zap.String("from", srcPath), zap.String("path", filepath.Join(d.mediaDir, name)))
+	return name, nil
 }
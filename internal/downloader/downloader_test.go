@@ -0,0 +1,177 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+var pngData = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR")
+
+func TestDownloadImage(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/photo":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(pngData)
+		case "/error-page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte("<html>Service unavailable</html>"))
+		case "/not-an-image":
+			w.Header().Set("Content-Type", "image/jpeg")
+			_, _ = w.Write([]byte("<html>Service unavailable</html>"))
+		case "/huge":
+			w.Header().Set("Content-Type", "image/jpeg")
+			_, _ = w.Write([]byte(strings.Repeat("\xff\xd8\xff", 1000)))
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	d := NewDownloader(dir, zap.NewNop())
+	ctx := context.Background()
+
+	// The extension follows the content: a PNG requested as .jpg is saved as .png
+	name, err := d.DownloadImage(ctx, server.URL+"/photo", "1_apple.jpg")
+	if err != nil || name != "1_apple.png" {
+		t.Fatalf("DownloadImage: got %q, %v; want 1_apple.png", name, err)
+	}
+	if name, err := d.DownloadImage(ctx, server.URL+"/photo", "1_apple.jpg"); err != nil || name != "1_apple.png" || requests.Load() != 1 {
+		t.Errorf("second download: got %q, %v after %d requests; want the cached file", name, err, requests.Load())
+	}
+
+	// A damaged file is detected by its checksum and downloaded again, also by a new downloader
+	if err := os.WriteFile(filepath.Join(dir, "1_apple.png"), pngData[:4], 0600); err != nil {
+		t.Fatal(err)
+	}
+	fresh := NewDownloader(dir, zap.NewNop())
+	if name, err := fresh.DownloadImage(ctx, server.URL+"/photo", "1_apple.jpg"); err != nil || requests.Load() != 2 {
+		t.Errorf("damaged file: got %q, %v after %d requests; want a new download", name, err, requests.Load())
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "1_apple.png")); string(data) != string(pngData) {
+		t.Errorf("damaged file was not replaced: %q", data)
+	}
+
+	// Invalid downloads fail and leave nothing behind
+	huge := *imageKind
+	huge.maxSize = 100
+	for _, path := range []string{"/error-page", "/not-an-image", "/huge"} {
+		kind := imageKind
+		if path == "/huge" {
+			kind = &huge
+		}
+		if _, err := d.downloadFile(ctx, server.URL+path, "2_pear.jpg", kind); !errors.Is(err, ErrInvalidMedia) {
+			t.Errorf("%s: got %v, want ErrInvalidMedia", path, err)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if strings.Join(names, " ") != manifestFile+" 1_apple.png" {
+		t.Errorf("media directory has %q", names)
+	}
+}
+
+// TestCached_LegacyFiles checks files downloaded before checksums were recorded: an image is kept when it
+// ends with the end marker of its format, while a cut-off image and an MP3, whose end cannot be checked, are
+// downloaded again
+func TestCached_LegacyFiles(t *testing.T) {
+	dir := t.TempDir()
+	jpeg := "\xff\xd8\xff\xe0\x00\x10JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00\xff\xd9"
+	files := map[string]string{
+		"1_apple.jpg":    jpeg,
+		"2_pear.jpg":     jpeg[:len(jpeg)-2],
+		"3_plum.png":     string(pngData) + "\x00\x00\x00\x00IEND\xaeB`\x82",
+		"1_apple_uk.mp3": "ID3\x03\x00",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if strings.HasSuffix(r.URL.Path, ".mp3") {
+			_, _ = w.Write([]byte("ID3\x04\x00"))
+			return
+		}
+		_, _ = w.Write([]byte(jpeg))
+	}))
+	defer server.Close()
+
+	d := NewDownloader(dir, zap.NewNop())
+	for _, name := range []string{"1_apple.jpg", "2_pear.jpg", "3_plum.png"} {
+		if got, err := d.DownloadImage(context.Background(), server.URL+"/"+name, name); err != nil || got != name {
+			t.Errorf("DownloadImage(%s): got %q, %v", name, got, err)
+		}
+	}
+	got, err := d.DownloadAudio(context.Background(), server.URL+"/1_apple_uk.mp3", "1_apple_uk.mp3")
+	if err != nil || got != "1_apple_uk.mp3" {
+		t.Errorf("DownloadAudio: got %q, %v", got, err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("got %d requests, want 2 for the cut-off JPEG and the MP3", requests.Load())
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "2_pear.jpg")); string(data) != jpeg {
+		t.Errorf("cut-off JPEG was not replaced: %q", data)
+	}
+
+	// Kept files are recorded, so the next run checks them by checksum
+	fresh := NewDownloader(dir, zap.NewNop())
+	for name := range files {
+		if _, ok := fresh.cached(name, imageKind); !ok {
+			t.Errorf("%s is not in the manifest", name)
+		}
+	}
+}
+
+func TestCopyFile(t *testing.T) {
+	srcDir, dir := t.TempDir(), t.TempDir()
+	photo := filepath.Join(srcDir, "cat.jpg")
+	if err := os.WriteFile(photo, pngData, 0600); err != nil {
+		t.Fatal(err)
+	}
+	notes := filepath.Join(srcDir, "notes.jpg")
+	if err := os.WriteFile(notes, []byte("not an image"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDownloader(dir, zap.NewNop())
+
+	// Copies get the checks of downloads: the extension follows the content, and a damaged copy is replaced
+	name, err := d.CopyFile(photo, "1_cat.jpg")
+	if err != nil || name != "1_cat.png" {
+		t.Fatalf("CopyFile: got %q, %v; want 1_cat.png", name, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), pngData[:3], 0600); err != nil {
+		t.Fatal(err)
+	}
+	if name, err := d.CopyFile(photo, "1_cat.jpg"); err != nil || name != "1_cat.png" {
+		t.Fatalf("CopyFile again: got %q, %v", name, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "1_cat.png")); string(data) != string(pngData) {
+		t.Errorf("damaged copy was not replaced: %q", data)
+	}
+
+	if _, err := d.CopyFile(notes, "2_notes.jpg"); !errors.Is(err, ErrInvalidMedia) {
+		t.Errorf("got %v, want ErrInvalidMedia", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("media directory has %d files, want the copy and the manifest", len(entries))
+	}
+}
@@ -0,0 +1,108 @@
+package downloader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+)
+
+// manifestFile records the downloads in the media directory, so corrupt or replaced files are detected
+const manifestFile = ".downloads.json"
+
+// manifestEntry is a finished download, keyed by the file name it was requested as
+type manifestEntry struct {
+	File   string `json:"file"` // name in the media directory; the extension matches the content
+	SHA256 string `json:"sha256"`
+}
+
+// loadManifest reads the manifest of the media directory once; a missing or unreadable manifest starts empty.
+// The caller holds d.mu.
+func (d *Downloader) loadManifest() {
+	if d.manifest != nil {
+		return
+	}
+	d.manifest = make(map[string]manifestEntry)
+	data, err := os.ReadFile(filepath.Join(d.mediaDir, manifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &d.manifest)
+	}
+	if err != nil {
+		d.logger.Warn("Failed to read download manifest, checking media files again", zap.Error(err))
+		d.manifest = make(map[string]manifestEntry)
+	}
+}
+
+// saveManifest writes the manifest atomically. The caller holds d.mu.
+func (d *Downloader) saveManifest() error {
+	data, err := json.MarshalIndent(d.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode download manifest: %w", err)
+	}
+	path := filepath.Join(d.mediaDir, manifestFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil { //nolint:gosec,mnd
+		return fmt.Errorf("failed to write download manifest: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write download manifest: %w", err)
+	}
+	return nil
+}
+
+// legacyChecksum returns the checksum of a file downloaded before checksums were recorded, if it is a complete
+// file of the kind. Formats whose end cannot be checked, such as MP3, are never trusted.
+func legacyChecksum(path string, kind *mediaKind) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	format, err := kind.detect(head[:n])
+	if err != nil {
+		return "", err
+	}
+	if format.complete == nil || !format.complete(f, info.Size()) {
+		return "", fmt.Errorf("%w: cannot tell that the %s file is complete", ErrInvalidMedia, format.ext)
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, io.NewSectionReader(f, 0, info.Size())); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fileChecksum returns the hex SHA-256 of a file
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,123 @@
+package downloader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+)
+
+// ErrInvalidMedia is returned when a download is not a file of the expected kind, e.g. an HTML error page
+var ErrInvalidMedia = errors.New("invalid media file")
+
+// sniffLen is how many leading bytes are read to tell the file format
+const sniffLen = 16
+
+// trailerLen is how many trailing bytes are read to tell whether a file is complete
+const trailerLen = 64
+
+// mediaKind describes what a download must contain
+type mediaKind struct {
+	name         string   // "image" or "audio", for logs and errors
+	contentTypes []string // accepted Content-Type prefixes; no header and application/octet-stream are accepted too
+	maxSize      int64
+	formats      []mediaFormat
+}
+
+// mediaFormat is a file format recognized by its leading bytes
+type mediaFormat struct {
+	ext   string
+	match func(head []byte) bool
+	// complete reports whether a file of the format is whole, e.g. by its end marker; nil if that cannot be told
+	complete func(r io.ReaderAt, size int64) bool
+}
+
+var imageKind = &mediaKind{
+	name:         "image",
+	contentTypes: []string{"image/"},
+	maxSize:      10 << 20, //nolint:mnd
+	formats: []mediaFormat{
+		{".jpg", prefix("\xff\xd8\xff"), trailer("\xff\xd9")},
+		{".png", prefix("\x89PNG\r\n\x1a\n"), trailer("IEND\xaeB`\x82")},
+		{".gif", prefix("GIF8"), trailer(";")},
+		{".webp", riff("WEBP"), riffComplete},
+	},
+}
+
+var audioKind = &mediaKind{
+	name:         "audio",
+	contentTypes: []string{"audio/", "application/ogg"},
+	maxSize:      5 << 20, //nolint:mnd
+	formats: []mediaFormat{
+		{".mp3", func(head []byte) bool {
+			// An ID3 tag or an MPEG frame sync
+			return bytes.HasPrefix(head, []byte("ID3")) || (len(head) > 1 && head[0] == 0xff && head[1]&0xe0 == 0xe0)
+		}, nil},
+		{".ogg", prefix("OggS"), nil},
+		{".wav", riff("WAVE"), riffComplete},
+		{".m4a", func(head []byte) bool { return len(head) >= 8 && string(head[4:8]) == "ftyp" }, nil},
+	},
+}
+
+func prefix(magic string) func([]byte) bool {
+	return func(head []byte) bool { return bytes.HasPrefix(head, []byte(magic)) }
+}
+
+func riff(format string) func([]byte) bool {
+	return func(head []byte) bool {
+		return len(head) >= 12 && string(head[:4]) == "RIFF" && string(head[8:12]) == format
+	}
+}
+
+// trailer checks the end marker of a format, ignoring zero padding after it
+func trailer(marker string) func(io.ReaderAt, int64) bool {
+	return func(r io.ReaderAt, size int64) bool {
+		tail := make([]byte, min(size, trailerLen))
+		if _, err := r.ReadAt(tail, size-int64(len(tail))); err != nil {
+			return false
+		}
+		return bytes.HasSuffix(bytes.TrimRight(tail, "\x00"), []byte(marker))
+	}
+}
+
+// riffComplete checks that a RIFF file is as long as its header says
+func riffComplete(r io.ReaderAt, size int64) bool {
+	header := make([]byte, 8) //nolint:mnd
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return false
+	}
+	return int64(binary.LittleEndian.Uint32(header[4:]))+8 <= size
+}
+
+// checkContentType rejects responses that declare another type, such as text/html error pages
+func (k *mediaKind) checkContentType(contentType string) error {
+	if contentType == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("%w: bad Content-Type %q", ErrInvalidMedia, contentType)
+	}
+	if mediaType == "application/octet-stream" {
+		return nil
+	}
+	for _, accepted := range k.contentTypes {
+		if strings.HasPrefix(mediaType, accepted) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: got %s instead of %s", ErrInvalidMedia, mediaType, k.name)
+}
+
+// detect returns the format the leading bytes belong to
+func (k *mediaKind) detect(head []byte) (*mediaFormat, error) {
+	for i := range k.formats {
+		if k.formats[i].match(head) {
+			return &k.formats[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: content is not a known %s format", ErrInvalidMedia, k.name)
+}
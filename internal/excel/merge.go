@@ -0,0 +1,270 @@
+package excel
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"github.com/xuri/excelize/v2"
+	"go.uber.org/zap"
+)
+
+// newRowFill highlights the rows added by MergeExtractedWords
+const newRowFill = "#E2EFDA"
+
+// Columns written by MergeExtractedWords to mark where new words came from
+const (
+	bookHeader  = "Book"
+	addedHeader = "Added"
+)
+
+// MergeOptions control how MergeExtractedWords adds words to a workbook
+type MergeOptions struct {
+	Book      string    // written to the Book column of new rows
+	Added     time.Time // written to the Added column of new rows
+	Highlight bool      // fill new rows with newRowFill
+	// Lemmatizer compares words by dictionary form, so "ran" is a duplicate of "run"; nil compares them as written.
+	// It must keep a word as written when it is not sure of the lemma, as lexicon.English does, or unrelated
+	// words such as "hoped" and "hop" would match.
+	Lemmatizer core.Lemmatizer
+}
+
+// MergeExtractedWords adds the words that are not in the first sheet of the workbook yet and leaves the existing
+// rows, with their manual edits and formatting, untouched. Words are compared case-insensitively by lemma.
+// Columns are matched by header; missing ones (context columns, Book and Added) are added after the last
+// one. A sheet without a header naming the languages is all data: new rows get the source in column A, the
+// target in B, the part of speech in C and the other values unlabeled after it. The workbook is created when
+// it does not exist.
+func (w *Writer) MergeExtractedWords(words []*core.ExtractedWord, langs core.LanguagePair, filename string, opts *MergeOptions) error { //nolint:lll
+	f, err := excelize.OpenFile(filename)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		w.logger.Info("Workbook not found, creating it", zap.String("file", filename))
+		f = excelize.NewFile()
+		if err := f.SetSheetName(f.GetSheetName(0), wordsSheet); err != nil {
+			return fmt.Errorf("failed to name sheet: %w", err)
+		}
+	case err != nil:
+		return fmt.Errorf("failed to open Excel file: %w", err)
+	}
+	defer f.Close()
+
+	sheet := f.GetSheetList()[0]
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		return fmt.Errorf("failed to get rows: %w", err)
+	}
+	m, err := newSheetMerge(f, sheet, rows, langs)
+	if err != nil {
+		return err
+	}
+
+	lemmatizer := opts.Lemmatizer
+	if lemmatizer == nil {
+		lemmatizer = core.IdentityLemmatizer{}
+	}
+	key := func(text string) string {
+		return core.NormalizeText(core.LemmatizePhrase(lemmatizer, core.CleanWord(text)))
+	}
+	known := make(map[string]bool)
+	for _, row := range rows[min(m.firstDataRow(), len(rows)):] {
+		if m.target < len(row) {
+			known[key(row[m.target])] = true
+		}
+	}
+
+	var added []*core.ExtractedWord
+	for _, word := range words {
+		k := key(word.Target)
+		if k == "" || known[k] {
+			continue
+		}
+		known[k] = true
+		row := m.nextRow + len(added)
+		if err := m.setAt(row, m.source, word.Source); err != nil {
+			return err
+		}
+		if err := m.setAt(row, m.target, strings.ToLower(word.Target)); err != nil {
+			return err
+		}
+		values := [][2]string{{"PartOfSpeech", word.PartOfSpeech}, {"Review", word.Review}}
+		for _, column := range contextColumns {
+			values = append(values, [2]string{column.header, column.value(word)})
+		}
+		values = append(values, [2]string{bookHeader, opts.Book}, [2]string{addedHeader, opts.Added.Format(time.DateOnly)})
+		for i, v := range values {
+			var err error
+			if m.headerless {
+				err = m.setAt(row, positionalPartOfSpeech+i, v[1])
+			} else {
+				err = m.set(row, v[0], v[1])
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if m.headerless {
+			m.width = max(m.width, positionalPartOfSpeech+len(values))
+		}
+		added = append(added, word)
+	}
+
+	if err := m.highlight(added, opts.Highlight); err != nil {
+		return err
+	}
+	if err := f.SaveAs(filename); err != nil {
+		return fmt.Errorf("failed to save Excel file: %w", err)
+	}
+	w.logger.Info("Merged extracted words", zap.String("file", filename),
+		zap.Int("added", len(added)), zap.Int("skipped", len(words)-len(added)))
+	return nil
+}
+
+// sheetMerge tracks the columns of the sheet words are merged into
+type sheetMerge struct {
+	f         *excelize.File
+	sheet     string
+	headerRow int      // 0-based; the first data row of a headerless sheet
+	header    []string // grows as columns are added
+	source    int      // 0-based columns of the translations and target words
+	target    int
+	nextRow   int // 1-based row of the first new word
+	// headerless sheets get values by position, starting with the part of speech in column C
+	headerless bool
+	width      int // columns written to a headerless sheet
+}
+
+// positionalPartOfSpeech is the 0-based column of the part of speech in a sheet without a header, where
+// Reader looks for it
+const positionalPartOfSpeech = 2
+
+// newSheetMerge finds the header row (the first non-empty row) and the target column. A new sheet gets the
+// header of WriteExtractedWords; a sheet whose first row does not name the target language has no header and
+// is read with the source in column A and the target in column B, like Reader without a header.
+func newSheetMerge(f *excelize.File, sheet string, rows [][]string, langs core.LanguagePair) (*sheetMerge, error) {
+	m := &sheetMerge{f: f, sheet: sheet}
+	for m.headerRow < len(rows) && len(rows[m.headerRow]) == 0 {
+		m.headerRow++
+	}
+	if m.headerRow < len(rows) {
+		m.header = slices.Clone(rows[m.headerRow])
+	} else {
+		m.headerRow = 0
+	}
+	m.nextRow = max(len(rows), m.headerRow+1) + 1
+
+	source, target := core.LanguageTitle(langs.Source), core.LanguageTitle(langs.Target)
+	if len(m.header) > 0 && m.column(target) < 0 {
+		m.headerless = true
+		m.header = nil
+		m.source, m.target = 0, 1
+		m.width = 2 //nolint:mnd
+		return m, nil
+	}
+	var err error
+	if m.source, err = m.ensure(source); err != nil {
+		return nil, err
+	}
+	if m.target, err = m.ensure(target); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// firstDataRow returns the 0-based row of the first word
+func (m *sheetMerge) firstDataRow() int {
+	if m.headerless {
+		return m.headerRow
+	}
+	return m.headerRow + 1
+}
+
+// column returns the 0-based index of the column with the header, or -1
+func (m *sheetMerge) column(name string) int {
+	return slices.IndexFunc(m.header, func(h string) bool { return strings.EqualFold(strings.TrimSpace(h), name) })
+}
+
+// ensure returns the 0-based index of the column with the header, adding it after the last column if needed
+func (m *sheetMerge) ensure(name string) (int, error) {
+	if i := m.column(name); i >= 0 {
+		return i, nil
+	}
+	i := len(m.header)
+	m.header = append(m.header, name)
+	cell, err := excelize.CoordinatesToCellName(i+1, m.headerRow+1)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add column %s: %w", name, err)
+	}
+	if err := m.f.SetCellValue(m.sheet, cell, name); err != nil {
+		return 0, fmt.Errorf("failed to add column %s: %w", name, err)
+	}
+	return i, nil
+}
+
+// set writes a value to the column with the header in a 1-based row
+func (m *sheetMerge) set(row int, name, value string) error {
+	if value == "" {
+		return nil
+	}
+	i, err := m.ensure(name)
+	if err != nil {
+		return err
+	}
+	return m.setAt(row, i, value)
+}
+
+// setAt writes a value to a 0-based column of a 1-based row
+func (m *sheetMerge) setAt(row, column int, value string) error {
+	if value == "" {
+		return nil
+	}
+	cell, err := excelize.CoordinatesToCellName(column+1, row)
+	if err != nil {
+		return fmt.Errorf("failed to write row %d: %w", row, err)
+	}
+	if err := m.f.SetCellValue(m.sheet, cell, value); err != nil {
+		return fmt.Errorf("failed to write row %d: %w", row, err)
+	}
+	return nil
+}
+
+// highlight fills the new rows when asked to, and the translation cells of new words marked for review
+func (m *sheetMerge) highlight(added []*core.ExtractedWord, all bool) error {
+	newStyle, err := m.f.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{newRowFill}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create new row style: %w", err)
+	}
+	reviewStyle, err := m.f.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{reviewFill}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create review style: %w", err)
+	}
+	lastColumn, _ := excelize.ColumnNumberToName(max(len(m.header), m.width))
+	review := m.column("Review")
+	if m.headerless {
+		review = positionalPartOfSpeech + 1 // Review follows the part of speech
+	}
+	reviewColumn, _ := excelize.ColumnNumberToName(max(review, m.source, m.target) + 1)
+	for i, word := range added {
+		row := m.nextRow + i
+		if all {
+			if err := m.f.SetCellStyle(m.sheet, fmt.Sprintf("A%d", row), fmt.Sprintf("%s%d", lastColumn, row), newStyle); err != nil {
+				return fmt.Errorf("failed to highlight row %d: %w", row, err)
+			}
+		}
+		if word.Review != "" {
+			if err := m.f.SetCellStyle(m.sheet, fmt.Sprintf("A%d", row), fmt.Sprintf("%s%d", reviewColumn, row), reviewStyle); err != nil {
+				return fmt.Errorf("failed to highlight row %d: %w", row, err)
+			}
+		}
+	}
+	return nil
+}
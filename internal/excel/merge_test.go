@@ -0,0 +1,140 @@
+package excel
+
+import (
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/lexicon"
+
+	"github.com/xuri/excelize/v2"
+	"go.uber.org/zap"
+)
+
+func TestMergeExtractedWords(t *testing.T) {
+	path := writeWorkbook(t, map[string][][]string{
+		"Words": {
+			{"Russian", "English", "PartOfSpeech", "Review", "Notes"},
+			{"бежать", "Ran", "verb", "", "typed by hand"},
+			{"яблоко", "apple", "", "", ""},
+		},
+	}, []string{"Words"})
+
+	words := []*core.ExtractedWord{
+		{Target: "run", Example: "He was running late."},
+		{Target: "Serendipity", Example: "Pure serendipity.", Page: 12, Review: "no translation"},
+		{Target: "APPLE"},
+	}
+	opts := &MergeOptions{
+		Book:       "Novel",
+		Added:      time.Date(2024, 3, 15, 9, 0, 0, 0, time.UTC),
+		Highlight:  true,
+		Lemmatizer: lexicon.NewEnglish(nil),
+	}
+	if err := NewWriter(zap.NewNop()).MergeExtractedWords(words, core.DefaultLanguagePair, path, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := f.GetRows("Words")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"Russian", "English", "PartOfSpeech", "Review", "Notes", "Example", "Page", "Book", "Added"},
+		{"бежать", "Ran", "verb", "", "typed by hand"},
+		{"яблоко", "apple"},
+		{"", "serendipity", "", "no translation", "", "Pure serendipity.", "12", "Novel", "2024-03-15"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q\nwant %q", rows, want)
+	}
+
+	// Merging into a missing workbook creates it
+	path = filepath.Join(t.TempDir(), "new.xlsx")
+	if err := NewWriter(zap.NewNop()).MergeExtractedWords(words[:1], core.DefaultLanguagePair, path, opts); err != nil {
+		t.Fatal(err)
+	}
+	f, err = excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if rows, _ := f.GetRows(wordsSheet); len(rows) != 2 || rows[1][1] != "run" {
+		t.Errorf("new workbook rows = %q", rows)
+	}
+}
+
+// TestMergeExtractedWords_Lemmas only treats words as the same when the lemma is certain: "hoped" and "writing"
+// are not the words "hop" and "writ" already in the sheet, while "Stopped" covers "stop"
+func TestMergeExtractedWords_Lemmas(t *testing.T) {
+	path := writeWorkbook(t, map[string][][]string{
+		"Words": {
+			{"Russian", "English"},
+			{"прыгать", "hop"},
+			{"предписание", "writ"},
+			{"остановил", "Stopped"},
+		},
+	}, []string{"Words"})
+
+	words := []*core.ExtractedWord{{Target: "hoped"}, {Target: "writing"}, {Target: "stop"}}
+	opts := &MergeOptions{Lemmatizer: lexicon.NewEnglish(nil)}
+	if err := NewWriter(zap.NewNop()).MergeExtractedWords(words, core.DefaultLanguagePair, path, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	cols, err := f.GetCols("Words")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"English", "hop", "writ", "Stopped", "hoped", "writing"}; !slices.Equal(cols[1], want) {
+		t.Errorf("English column = %q, want %q", cols[1], want)
+	}
+}
+
+func TestMergeExtractedWords_NoHeader(t *testing.T) {
+	// The first row does not name the languages, so it is a word like the others
+	path := writeWorkbook(t, map[string][][]string{
+		"Words": {
+			{"бежать", "run", "verb"},
+			{"яблоко", "apple", "noun"},
+		},
+	}, []string{"Words"})
+
+	words := []*core.ExtractedWord{{Target: "run"}, {Target: "pear", PartOfSpeech: "noun", Example: "A ripe pear."}}
+	opts := &MergeOptions{Book: "Novel", Added: time.Date(2024, 3, 15, 9, 0, 0, 0, time.UTC)}
+	if err := NewWriter(zap.NewNop()).MergeExtractedWords(words, core.DefaultLanguagePair, path, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := f.GetRows("Words")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || !reflect.DeepEqual(rows[0], []string{"бежать", "run", "verb"}) {
+		t.Fatalf("rows = %q, want the two rows untouched and pear added", rows)
+	}
+	// Values are written by position: the part of speech in C, where the reader without a header finds it,
+	// then the other values without labels
+	if pear := rows[2]; len(pear) < 3 || pear[1] != "pear" || pear[2] != "noun" ||
+		!slices.Contains(pear, "A ripe pear.") || pear[len(pear)-1] != "2024-03-15" {
+		t.Errorf("new row = %q", pear)
+	}
+}
@@ -2,138 +2,116 @@ package excel
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/wordlist"
 
 	"github.com/xuri/excelize/v2"
 	"go.uber.org/zap"
 )
 
+// SourceName identifies Excel workbooks as a word source
+const SourceName = "xlsx"
+
 // Reader handles reading Excel files with word pairs
 type Reader struct {
+	opts   *wordlist.Options
 	logger *zap.Logger
 }
 
-// NewReader creates a new Excel reader
-func NewReader(logger *zap.Logger) *Reader {
+// NewReader creates a new Excel reader; opts selects the sheet and columns
+func NewReader(opts *wordlist.Options, logger *zap.Logger) *Reader {
 	return &Reader{
+		opts:   opts,
 		logger: logger,
 	}
 }
 
-// ReadWordPairs reads Russian-English word pairs from an Excel file
+// Name implements core.WordSource
+func (r *Reader) Name() string {
+	return SourceName
+}
+
+// ReadWordPairs reads word pairs from a sheet of an Excel file, see wordlist.ParseRows for the column layout
 func (r *Reader) ReadWordPairs(filePath string) ([]*core.RawFlashcard, error) {
 	r.logger.Info("Reading Excel file", zap.String("path", filePath))
 
-	// Open Excel file
-	f, err := excelize.OpenFile(filePath)
+	sheetName, rows, err := r.readSheet(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open Excel file: %w", err)
+		return nil, err
 	}
-	defer f.Close()
-
-	// Get the first sheet
-	sheets := f.GetSheetList()
-	if len(sheets) == 0 {
-		return nil, fmt.Errorf("no sheets found in Excel file")
+	wordPairs, err := wordlist.ParseRows(rows, r.opts, r.logger.With(zap.String("sheet", sheetName)))
+	if err != nil {
+		return nil, err
 	}
 
-	sheetName := sheets[0]
-	r.logger.Debug("Reading sheet", zap.String("sheet", sheetName))
+	r.logger.Info("Successfully read word pairs", zap.Int("count", len(wordPairs)))
+	return wordPairs, nil
+}
 
-	// Get all rows from the sheet
-	rows, err := f.GetRows(sheetName)
+// Validate validates that an Excel file has the correct format
+func (r *Reader) Validate(filePath string) error {
+	sheetName, rows, err := r.readSheet(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get rows: %w", err)
+		return err
 	}
-
-	if len(rows) < 2 {
-		return nil, fmt.Errorf("Excel file must have at least 2 rows (header + data)") //nolint:stylecheck
+	dataRows, err := wordlist.ValidateRows(rows, r.opts)
+	if err != nil {
+		return fmt.Errorf("sheet %q: %w", sheetName, err)
 	}
 
-	var wordPairs []*core.RawFlashcard
-
-	// Skip header row, process data rows
-	for i, row := range rows[1:] {
-		if len(row) < 2 {
-			r.logger.Warn("Skipping row with insufficient columns", zap.Int("row", i+2), zap.Int("columns", len(row)))
-			continue
-		}
-
-		// Read columns: Russian, English, PartOfSpeech (optional)
-		russian := row[0]
-		english := row[1]
-		partOfSpeech := ""
-		if len(row) > 2 {
-			partOfSpeech = row[2]
-		}
-
-		// Skip empty rows
-		if russian == "" || english == "" {
-			r.logger.Debug("Skipping empty row", zap.Int("row", i+2))
-			continue
-		}
-
-		wordPair := &core.RawFlashcard{
-			Russian:      russian,
-			English:      english,
-			PartOfSpeech: partOfSpeech,
-		}
-
-		wordPairs = append(wordPairs, wordPair)
-		r.logger.Debug("Read word pair", zap.String("russian", russian), zap.String("english", english))
-	}
+	r.logger.Info("Excel file validation passed",
+		zap.String("file", filePath),
+		zap.String("sheet", sheetName),
+		zap.Int("total_rows", len(rows)),
+		zap.Int("data_rows", dataRows))
 
-	r.logger.Info("Successfully read word pairs", zap.Int("count", len(wordPairs)))
-	return wordPairs, nil
+	return nil
 }
 
-// ValidateExcelFile validates that an Excel file has the correct format
-func (r *Reader) ValidateExcelFile(filePath string) error {
+// readSheet returns the name and rows of the selected sheet
+func (r *Reader) readSheet(filePath string) (string, [][]string, error) {
+	// Open Excel file
 	f, err := excelize.OpenFile(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to open Excel file: %w", err)
+		return "", nil, fmt.Errorf("failed to open Excel file: %w", err)
 	}
 	defer f.Close()
 
-	sheets := f.GetSheetList()
-	if len(sheets) == 0 {
-		return fmt.Errorf("no sheets found in Excel file")
+	sheetName, err := selectSheet(f, r.opts.Sheet)
+	if err != nil {
+		return "", nil, err
 	}
+	r.logger.Debug("Reading sheet", zap.String("sheet", sheetName))
 
-	sheetName := sheets[0]
+	// Get all rows from the sheet
 	rows, err := f.GetRows(sheetName)
 	if err != nil {
-		return fmt.Errorf("failed to get rows: %w", err)
+		return "", nil, fmt.Errorf("failed to get rows: %w", err)
 	}
+	return sheetName, rows, nil
+}
 
-	if len(rows) < 2 {
-		return fmt.Errorf("Excel file must have at least 2 rows (header + data)") //nolint:stylecheck
+// selectSheet resolves a sheet name or 1-based index to a sheet name
+func selectSheet(f *excelize.File, sheet string) (string, error) {
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return "", fmt.Errorf("no sheets found in Excel file")
 	}
-
-	// Check header row
-	header := rows[0]
-	if len(header) < 2 {
-		return fmt.Errorf("header row must have at least 2 columns")
+	if sheet == "" {
+		return sheets[0], nil
 	}
-
-	// Validate that we have some data rows
-	dataRows := 0
-	for _, row := range rows[1:] {
-		if len(row) >= 2 && row[0] != "" && row[1] != "" {
-			dataRows++
-		}
+	if slices.Contains(sheets, sheet) {
+		return sheet, nil
 	}
-
-	if dataRows == 0 {
-		return fmt.Errorf("no valid data rows found in Excel file")
+	if index, err := strconv.Atoi(sheet); err == nil {
+		if index < 1 || index > len(sheets) {
+			return "", fmt.Errorf("sheet %d out of range: the file has %d sheets", index, len(sheets))
+		}
+		return sheets[index-1], nil
 	}
-
-	r.logger.Info("Excel file validation passed",
-		zap.String("file", filePath),
-		zap.String("sheet", sheetName),
-		zap.Int("total_rows", len(rows)),
-		zap.Int("data_rows", dataRows))
-
-	return nil
+	return "", fmt.Errorf("sheet %q not found, available sheets: %s", sheet, strings.Join(sheets, ", "))
 }
@@ -0,0 +1,113 @@
+package excel
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/wordlist"
+
+	"github.com/xuri/excelize/v2"
+	"go.uber.org/zap"
+)
+
+// writeWorkbook creates a workbook with the given sheets, each a list of rows
+func writeWorkbook(t *testing.T, sheets map[string][][]string, order []string) string {
+	t.Helper()
+	f := excelize.NewFile()
+	defer f.Close()
+	for i, name := range order {
+		if i == 0 {
+			if err := f.SetSheetName(f.GetSheetName(0), name); err != nil {
+				t.Fatal(err)
+			}
+		} else if _, err := f.NewSheet(name); err != nil {
+			t.Fatal(err)
+		}
+		for r, row := range sheets[name] {
+			cell, _ := excelize.CoordinatesToCellName(1, r+1)
+			values := row
+			if err := f.SetSheetRow(name, cell, &values); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	path := filepath.Join(t.TempDir(), "words.xlsx")
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadWordPairs_HeaderMapping(t *testing.T) {
+	path := writeWorkbook(t, map[string][][]string{
+		"Summary": {{"nothing here"}},
+		"Team": {
+			{"Team vocabulary, autumn"},
+			{},
+			{"English", "Level", "Translation", "POS", "Tags", "Example", "Notes"},
+			{"apple", "A1", "яблоко", "noun", "food, fruit", "An apple a day.", "countable"},
+			{"keep in mind", "", "иметь ввиду", "phrase", "", "", ""},
+			{"orphan", "B2", "", "noun", "", "", ""},
+		},
+	}, []string{"Summary", "Team"})
+
+	for _, sheet := range []string{"Team", "2"} {
+		reader := NewReader(&wordlist.Options{Sheet: sheet, Languages: core.DefaultLanguagePair}, zap.NewNop())
+		words, err := reader.ReadWordPairs(path)
+		if err != nil {
+			t.Fatalf("ReadWordPairs(sheet %q): %v", sheet, err)
+		}
+		want := []*core.RawFlashcard{
+			{
+				Source: "яблоко", Target: "apple", PartOfSpeech: "noun",
+				Tags: []string{"food", "fruit"}, Example: "An apple a day.", Notes: "countable",
+				Extra: map[string]string{"Level": "A1"},
+			},
+			{Source: "иметь ввиду", Target: "keep in mind", PartOfSpeech: "phrase"},
+		}
+		if !reflect.DeepEqual(words, want) {
+			t.Errorf("sheet %q: got %+v, want %+v", sheet, words[0], want[0])
+		}
+	}
+
+	if _, err := NewReader(&wordlist.Options{Sheet: "Missing"}, zap.NewNop()).ReadWordPairs(path); err == nil {
+		t.Error("expected an error for a missing sheet")
+	}
+}
+
+func TestReadWordPairs_ExplicitAndPositionalColumns(t *testing.T) {
+	path := writeWorkbook(t, map[string][][]string{
+		"Words": {
+			{"Col1", "Col2", "Col3", "Mine"},
+			{"книга", "book", "noun", "library"},
+		},
+	}, []string{"Words"})
+	// No recognizable header: columns A, B and C as before
+	words, err := NewReader(&wordlist.Options{Languages: core.DefaultLanguagePair}, zap.NewNop()).ReadWordPairs(path)
+	if err != nil {
+		t.Fatalf("ReadWordPairs: %v", err)
+	}
+	if len(words) != 1 || words[0].Source != "книга" || words[0].Target != "book" || words[0].PartOfSpeech != "noun" {
+		t.Fatalf("positional columns: got %+v", words)
+	}
+	if words[0].Extra["Mine"] != "library" {
+		t.Errorf("expected extra column Mine, got %v", words[0].Extra)
+	}
+
+	// Explicit header names and letters
+	opts := &wordlist.Options{Columns: map[string]string{"source": "Col1", "target": "Col2", "tags": "D"}}
+	words, err = NewReader(opts, zap.NewNop()).ReadWordPairs(path)
+	if err != nil {
+		t.Fatalf("ReadWordPairs: %v", err)
+	}
+	if !reflect.DeepEqual(words[0].Tags, []string{"library"}) || words[0].Extra["Col3"] != "noun" {
+		t.Errorf("explicit columns: got %+v", words[0])
+	}
+
+	opts = &wordlist.Options{Columns: map[string]string{"meaning": "A"}}
+	if _, err := NewReader(opts, zap.NewNop()).ReadWordPairs(path); err == nil {
+		t.Error("expected an error for an unknown column role")
+	}
+}
@@ -0,0 +1,107 @@
+package excel
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"github.com/xuri/excelize/v2"
+	"go.uber.org/zap"
+)
+
+// wordsSheet is the name of the sheet of new workbooks
+const wordsSheet = "WordsSheet1"
+
+// reviewFill highlights rows whose translation should be checked by hand
+const reviewFill = "#FFF2CC"
+
+// contextColumns are written after Review, each only when some word has a value for it.
+// Reader maps Example, Tags and Deck; Forms, Chapter and Page are passed through as extra fields.
+var contextColumns = []struct {
+	header string
+	value  func(word *core.ExtractedWord) string
+}{
+	{"Forms", func(word *core.ExtractedWord) string { return strings.Join(word.Forms, ", ") }},
+	{"Example", func(word *core.ExtractedWord) string { return word.Example }},
+	{"Chapter", func(word *core.ExtractedWord) string { return word.Chapter }},
+	{"Page", func(word *core.ExtractedWord) string {
+		if word.Page == 0 {
+			return ""
+		}
+		return strconv.Itoa(word.Page)
+	}},
+	{"Tags", func(word *core.ExtractedWord) string { return strings.Join(word.Tags, " ") }},
+	{"Deck", func(word *core.ExtractedWord) string { return word.Deck }},
+}
+
+// Writer handles writing extracted words to Excel files in the format read by Reader
+type Writer struct {
+	logger *zap.Logger
+}
+
+// NewWriter creates a new Excel writer
+func NewWriter(logger *zap.Logger) *Writer {
+	return &Writer{
+		logger: logger,
+	}
+}
+
+// WriteExtractedWords writes words as source, target, PartOfSpeech rows with the language names as headers.
+// Words marked for review are highlighted and explained in a fourth Review column, which Reader ignores.
+// Context columns (see contextColumns) follow when some words have them.
+func (w *Writer) WriteExtractedWords(words []*core.ExtractedWord, langs core.LanguagePair, filename string) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	var columns []int // indexes into contextColumns
+	for i, column := range contextColumns {
+		if slices.ContainsFunc(words, func(word *core.ExtractedWord) bool { return column.value(word) != "" }) {
+			columns = append(columns, i)
+		}
+	}
+
+	sheet := wordsSheet
+	if err := f.SetSheetName(f.GetSheetName(0), sheet); err != nil {
+		return fmt.Errorf("failed to name sheet: %w", err)
+	}
+	header := []string{core.LanguageTitle(langs.Source), core.LanguageTitle(langs.Target), "PartOfSpeech", "Review"}
+	for _, i := range columns {
+		header = append(header, contextColumns[i].header)
+	}
+	if err := f.SetSheetRow(sheet, "A1", &header); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	reviewStyle, err := f.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{reviewFill}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create review style: %w", err)
+	}
+
+	for i, word := range words {
+		row := i + 2 //nolint:mnd
+		cell := fmt.Sprintf("A%d", row)
+		values := []string{word.Source, strings.ToLower(word.Target), word.PartOfSpeech, word.Review}
+		for _, i := range columns {
+			values = append(values, contextColumns[i].value(word))
+		}
+		if err := f.SetSheetRow(sheet, cell, &values); err != nil {
+			return fmt.Errorf("failed to write row %d: %w", row, err)
+		}
+		if word.Review != "" {
+			if err := f.SetCellStyle(sheet, cell, fmt.Sprintf("D%d", row), reviewStyle); err != nil {
+				return fmt.Errorf("failed to highlight row %d: %w", row, err)
+			}
+		}
+	}
+
+	if err := f.SaveAs(filename); err != nil {
+		return fmt.Errorf("failed to save Excel file: %w", err)
+	}
+	w.logger.Debug("Wrote extracted words", zap.String("file", filename), zap.Int("words", len(words)))
+	return nil
+}
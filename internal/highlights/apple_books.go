@@ -0,0 +1,131 @@
+package highlights
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite" // pure-Go SQLite driver
+)
+
+// AppleBooksSourceName identifies the Apple Books annotation database
+const AppleBooksSourceName = "apple-books"
+
+// appleAnnotationsQuery reads live highlights; ZFUTUREPROOFING5 holds the chapter title
+const appleAnnotationsQuery = `
+SELECT COALESCE(ZANNOTATIONASSETID, ''), ZANNOTATIONSELECTEDTEXT,
+       COALESCE(ZANNOTATIONREPRESENTATIVETEXT, ''), COALESCE(ZFUTUREPROOFING5, ''), COALESCE(ZANNOTATIONNOTE, '')
+FROM ZAEANNOTATION
+WHERE COALESCE(ZANNOTATIONDELETED, 0) = 0 AND COALESCE(ZANNOTATIONSELECTEDTEXT, '') <> ''
+ORDER BY ZANNOTATIONASSETID, ZPLLOCATIONRANGESTART, Z_PK`
+
+// appleTitlesQuery maps asset IDs to book titles in the library database
+const appleTitlesQuery = `SELECT ZASSETID, COALESCE(ZTITLE, '') FROM ZBKLIBRARYASSET WHERE ZASSETID IS NOT NULL`
+
+// AppleBooks reads highlights from the Apple Books annotation database (AEAnnotation_*.sqlite, found in
+// ~/Library/Containers/com.apple.iBooksX/Data/Documents/AEAnnotation). Book titles come from the
+// BKLibrary database next to it when it exists.
+type AppleBooks struct {
+	logger *zap.Logger
+}
+
+// NewAppleBooks creates an Apple Books highlight source
+func NewAppleBooks(logger *zap.Logger) *AppleBooks {
+	return &AppleBooks{
+		logger: logger,
+	}
+}
+
+// Name implements core.HighlightSource
+func (a *AppleBooks) Name() string {
+	return AppleBooksSourceName
+}
+
+// ReadHighlights implements core.HighlightSource
+func (a *AppleBooks) ReadHighlights(path string) ([]*core.Highlight, error) {
+	a.logger.Info("Reading highlights", zap.String("path", path), zap.String("format", AppleBooksSourceName))
+
+	db, err := openReadOnly(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Apple Books annotations: %w", err)
+	}
+	defer db.Close()
+
+	titles := a.bookTitles(path)
+
+	rows, err := db.Query(appleAnnotationsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Apple Books annotations (is it an AEAnnotation database?): %w", err)
+	}
+	defer rows.Close()
+
+	var highlights []*core.Highlight
+	for rows.Next() {
+		var assetID, text, context, chapter, note string
+		if err := rows.Scan(&assetID, &text, &context, &chapter, &note); err != nil {
+			return nil, fmt.Errorf("failed to read Apple Books annotation: %w", err)
+		}
+		// The representative text is the paragraph or sentence around the selection
+		sentence := core.FindSentence(context, text)
+		if sentence == "" {
+			sentence = context
+		}
+		highlights = append(highlights, &core.Highlight{
+			Text:     text,
+			Sentence: sentence,
+			Chapter:  chapter,
+			Book:     titles[assetID],
+			Note:     note,
+			Spine:    -1,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query Apple Books annotations: %w", err)
+	}
+
+	a.logger.Info("Read highlights", zap.Int("count", len(highlights)), zap.Int("books", len(titles)))
+	return highlights, nil
+}
+
+// bookTitles reads the titles from ../BKLibrary/BKLibrary*.sqlite; it returns an empty map if there is none
+func (a *AppleBooks) bookTitles(annotationsPath string) map[string]string {
+	titles := make(map[string]string)
+	matches, _ := filepath.Glob(filepath.Join(filepath.Dir(filepath.Dir(annotationsPath)), "BKLibrary", "BKLibrary*.sqlite"))
+	if len(matches) == 0 {
+		a.logger.Warn("Apple Books library database not found next to the annotations, highlights will have no book title")
+		return titles
+	}
+
+	db, err := openReadOnly(matches[0])
+	if err != nil {
+		a.logger.Warn("Failed to open Apple Books library", zap.Error(err))
+		return titles
+	}
+	defer db.Close()
+
+	rows, err := db.Query(appleTitlesQuery)
+	if err != nil {
+		a.logger.Warn("Failed to read Apple Books library", zap.String("path", matches[0]), zap.Error(err))
+		return titles
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var assetID, title string
+		if err := rows.Scan(&assetID, &title); err == nil {
+			titles[assetID] = title
+		}
+	}
+	return titles
+}
+
+// openReadOnly opens an existing SQLite database read-only
+func openReadOnly(path string) (*sql.DB, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	return sql.Open("sqlite", "file:"+path+"?mode=ro")
+}
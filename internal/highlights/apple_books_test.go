@@ -0,0 +1,100 @@
+package highlights
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"go.uber.org/zap"
+)
+
+// appleAnnotationSchema is the part of the AEAnnotation schema read by AppleBooks
+const appleAnnotationSchema = `
+CREATE TABLE ZAEANNOTATION (Z_PK INTEGER PRIMARY KEY, ZANNOTATIONASSETID VARCHAR, ZANNOTATIONSELECTEDTEXT VARCHAR,
+    ZANNOTATIONREPRESENTATIVETEXT VARCHAR, ZFUTUREPROOFING5 VARCHAR, ZANNOTATIONNOTE VARCHAR,
+    ZANNOTATIONDELETED INTEGER, ZPLLOCATIONRANGESTART INTEGER);
+
+INSERT INTO ZAEANNOTATION VALUES
+    (1, 'B2', 'ceaselessly', 'So we beat on, boats against the current. Borne back ceaselessly into the past.',
+        'Chapter 9', NULL, 0, 40),
+    (2, 'A1', 'serendipity', 'It was pure serendipity that we met. We never looked back.', 'Chapter 1', 'luck', 0, 20),
+    (3, 'A1', 'deleted', 'A deleted highlight.', 'Chapter 1', NULL, 1, 10),
+    (4, 'A1', NULL, 'A bookmark has no selected text.', 'Chapter 1', NULL, 0, 5),
+    (5, 'A1', 'ubiquitous', NULL, NULL, NULL, NULL, 10),
+    (6, 'C3', 'sideloaded', 'A sideloaded book is not in the library.', '', NULL, 0, 1);
+`
+
+// appleLibrarySchema is the part of the BKLibrary schema read by AppleBooks
+const appleLibrarySchema = `
+CREATE TABLE ZBKLIBRARYASSET (Z_PK INTEGER PRIMARY KEY, ZASSETID VARCHAR, ZTITLE VARCHAR);
+
+INSERT INTO ZBKLIBRARYASSET VALUES (1, 'A1', 'Serendipity'), (2, 'B2', 'The Great Gatsby'), (3, NULL, 'No asset');
+`
+
+// writeSQLite creates a database at path from a schema
+func writeSQLite(t *testing.T, path, schema string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAppleBooks(t *testing.T) {
+	// The layout of ~/Library/Containers/com.apple.iBooksX/Data/Documents
+	documents := t.TempDir()
+	annotations := filepath.Join(documents, "AEAnnotation", "AEAnnotation_v10312011_1727_local.sqlite")
+	writeSQLite(t, annotations, appleAnnotationSchema)
+
+	// Without the library, highlights are read without titles
+	highlights, err := NewAppleBooks(zap.NewNop()).ReadHighlights(annotations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(highlights) != 4 || highlights[0].Book != "" {
+		t.Fatalf("without a library: %+v", highlights)
+	}
+
+	writeSQLite(t, filepath.Join(documents, "BKLibrary", "BKLibrary-1-091020131601.sqlite"), appleLibrarySchema)
+	highlights, err = NewAppleBooks(zap.NewNop()).ReadHighlights(annotations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]core.Highlight, len(highlights))
+	for i, h := range highlights {
+		got[i] = *h
+	}
+	want := []core.Highlight{
+		{Text: "ubiquitous", Book: "Serendipity", Spine: -1},
+		{Text: "serendipity", Sentence: "It was pure serendipity that we met.", Chapter: "Chapter 1", Book: "Serendipity",
+			Note: "luck", Spine: -1},
+		{Text: "ceaselessly", Sentence: "Borne back ceaselessly into the past.", Chapter: "Chapter 9",
+			Book: "The Great Gatsby", Spine: -1},
+		{Text: "sideloaded", Sentence: "A sideloaded book is not in the library.", Spine: -1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("highlights:\n%+v\nwant:\n%+v", got, want)
+	}
+}
+
+func TestAppleBooks_NotAnAnnotationDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "other.sqlite")
+	writeSQLite(t, path, appleLibrarySchema)
+	if _, err := NewAppleBooks(zap.NewNop()).ReadHighlights(path); err == nil {
+		t.Error("want an error for a database without ZAEANNOTATION")
+	}
+	if _, err := NewAppleBooks(zap.NewNop()).ReadHighlights(filepath.Join(t.TempDir(), "missing.sqlite")); err == nil {
+		t.Error("want an error for a missing file")
+	}
+}
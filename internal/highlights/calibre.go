@@ -0,0 +1,83 @@
+package highlights
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"go.uber.org/zap"
+)
+
+// CalibreSourceName identifies annotations exported from the calibre viewer
+const CalibreSourceName = "calibre"
+
+// calibreExport is the .calibre_highlights file written by "Export" in the calibre viewer's highlights panel
+type calibreExport struct {
+	Type       string `json:"type"`
+	Highlights []struct {
+		Type            string   `json:"type"`
+		HighlightedText string   `json:"highlighted_text"`
+		Notes           string   `json:"notes"`
+		TOCFamilyTitles []string `json:"toc_family_titles"`
+		SpineIndex      *int     `json:"spine_index"`
+		Removed         bool     `json:"removed"`
+	} `json:"highlights"`
+}
+
+// Calibre reads calibre viewer highlight exports. They carry no book title; use --title or --epub.
+type Calibre struct {
+	logger *zap.Logger
+}
+
+// NewCalibre creates a calibre highlight source
+func NewCalibre(logger *zap.Logger) *Calibre {
+	return &Calibre{
+		logger: logger,
+	}
+}
+
+// Name implements core.HighlightSource
+func (c *Calibre) Name() string {
+	return CalibreSourceName
+}
+
+// ReadHighlights implements core.HighlightSource
+func (c *Calibre) ReadHighlights(path string) ([]*core.Highlight, error) {
+	c.logger.Info("Reading highlights", zap.String("path", path), zap.String("format", CalibreSourceName))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calibre highlights: %w", err)
+	}
+	var export calibreExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("failed to parse calibre highlights: %w", err)
+	}
+	if export.Type != "calibre_highlights" {
+		return nil, fmt.Errorf("%s is not a calibre highlights export", path)
+	}
+
+	var highlights []*core.Highlight
+	for _, h := range export.Highlights {
+		if h.Type != "highlight" || h.Removed || h.HighlightedText == "" {
+			continue
+		}
+		highlight := &core.Highlight{
+			Text:  h.HighlightedText,
+			Note:  h.Notes,
+			Spine: -1,
+		}
+		if n := len(h.TOCFamilyTitles); n > 0 {
+			highlight.Chapter = h.TOCFamilyTitles[n-1]
+		}
+		if h.SpineIndex != nil {
+			highlight.Spine = *h.SpineIndex
+		}
+		highlights = append(highlights, highlight)
+	}
+
+	c.logger.Info("Read highlights", zap.Int("count", len(highlights)))
+	return highlights, nil
+}
@@ -0,0 +1,227 @@
+package highlights
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"go.uber.org/zap"
+)
+
+// blockElements end a paragraph in the plain text of a chapter
+var blockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "tr": true, "blockquote": true, "section": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "dt": true, "dd": true,
+}
+
+// headingElements give a chapter its title
+var headingElements = map[string]bool{"h1": true, "h2": true, "h3": true}
+
+// skippedElements have no readable text
+var skippedElements = map[string]bool{"head": true, "script": true, "style": true}
+
+// Chapter is the plain text of one EPUB content document
+type Chapter struct {
+	Title string
+	Text  string // paragraphs separated by newlines
+}
+
+// EPUB is the text of a book, used to find the sentence around a highlight
+type EPUB struct {
+	Title    string
+	Chapters []*Chapter // in spine (reading) order
+}
+
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+type epubPackage struct {
+	Titles   []string `xml:"metadata>title"`
+	Manifest []struct {
+		ID   string `xml:"id,attr"`
+		Href string `xml:"href,attr"`
+	} `xml:"manifest>item"`
+	Spine []struct {
+		IDRef string `xml:"idref,attr"`
+	} `xml:"spine>itemref"`
+}
+
+// OpenEPUB reads the title and chapter texts of an EPUB file
+func OpenEPUB(filename string, logger *zap.Logger) (*EPUB, error) {
+	zr, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open EPUB: %w", err)
+	}
+	defer zr.Close()
+
+	var container epubContainer
+	if err := readZipXML(&zr.Reader, "META-INF/container.xml", &container); err != nil {
+		return nil, err
+	}
+	if len(container.Rootfiles) == 0 {
+		return nil, fmt.Errorf("EPUB has no package document")
+	}
+	opfPath := container.Rootfiles[0].FullPath
+	var pkg epubPackage
+	if err := readZipXML(&zr.Reader, opfPath, &pkg); err != nil {
+		return nil, err
+	}
+
+	hrefs := make(map[string]string, len(pkg.Manifest))
+	for _, item := range pkg.Manifest {
+		hrefs[item.ID] = item.Href
+	}
+
+	book := &EPUB{}
+	if len(pkg.Titles) > 0 {
+		book.Title = strings.TrimSpace(pkg.Titles[0])
+	}
+	for _, itemRef := range pkg.Spine {
+		chapter := &Chapter{}
+		if href, ok := hrefs[itemRef.IDRef]; ok {
+			name := path.Join(path.Dir(opfPath), href)
+			if chapter, err = readChapter(&zr.Reader, name); err != nil {
+				logger.Warn("Skipping unreadable EPUB chapter", zap.String("file", name), zap.Error(err))
+				chapter = &Chapter{}
+			}
+		}
+		book.Chapters = append(book.Chapters, chapter)
+	}
+
+	logger.Info("Read EPUB", zap.String("title", book.Title), zap.Int("chapters", len(book.Chapters)))
+	return book, nil
+}
+
+// AddContext fills in the book title, chapter and sentence of highlights that lack them, searching the
+// chapter the highlight is in or, when that is unknown, the whole book
+func (e *EPUB) AddContext(highlights []*core.Highlight) {
+	for _, h := range highlights {
+		if h.Book == "" {
+			h.Book = e.Title
+		}
+		chapters := e.Chapters
+		if h.Spine >= 0 && h.Spine < len(e.Chapters) {
+			chapters = e.Chapters[h.Spine : h.Spine+1]
+			if h.Chapter == "" {
+				h.Chapter = chapters[0].Title
+			}
+		}
+		if h.Sentence != "" {
+			continue
+		}
+		text := strings.Join(strings.Fields(h.Text), " ")
+		for _, chapter := range chapters {
+			if sentence := core.FindSentence(chapter.Text, text); sentence != "" {
+				h.Sentence = sentence
+				if h.Chapter == "" {
+					h.Chapter = chapter.Title
+				}
+				break
+			}
+		}
+	}
+}
+
+func readZipXML(zr *zip.Reader, name string, v any) error {
+	f, err := zr.Open(name)
+	if err != nil {
+		return fmt.Errorf("failed to open %s in EPUB: %w", name, err)
+	}
+	defer f.Close()
+	if err := xml.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse %s in EPUB: %w", name, err)
+	}
+	return nil
+}
+
+// readChapter converts an XHTML content document to plain text
+func readChapter(zr *zip.Reader, name string) (*Chapter, error) {
+	f, err := zr.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	decoder := xml.NewDecoder(f)
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	var (
+		paragraphs []string
+		current    strings.Builder
+		title      strings.Builder
+		skipDepth  int
+		inHeading  bool
+		pageTitle  string
+		inTitleTag bool
+	)
+	flush := func() {
+		if p := strings.Join(strings.Fields(current.String()), " "); p != "" {
+			paragraphs = append(paragraphs, p)
+		}
+		current.Reset()
+	}
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			switch {
+			case name == "title":
+				inTitleTag = true
+			case skippedElements[name]:
+				skipDepth++
+			case blockElements[name]:
+				flush()
+			}
+			inHeading = inHeading || (headingElements[name] && title.Len() == 0)
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			switch {
+			case name == "title":
+				inTitleTag = false
+			case skippedElements[name] && skipDepth > 0:
+				skipDepth--
+			case blockElements[name]:
+				flush()
+			}
+			if headingElements[name] {
+				inHeading = false
+			}
+		case xml.CharData:
+			if inTitleTag {
+				pageTitle += string(t)
+			}
+			if skipDepth > 0 {
+				continue
+			}
+			current.Write(t)
+			if inHeading {
+				title.Write(t)
+			}
+		}
+	}
+	flush()
+
+	chapterTitle := strings.Join(strings.Fields(title.String()), " ")
+	if chapterTitle == "" {
+		chapterTitle = strings.Join(strings.Fields(pageTitle), " ")
+	}
+	return &Chapter{Title: chapterTitle, Text: strings.Join(paragraphs, "\n")}, nil
+}
@@ -0,0 +1,173 @@
+package highlights
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"go.uber.org/zap"
+)
+
+const koreaderMetadata = `-- we can read Lua syntax here!
+return {
+    ["annotations"] = {
+        [1] = {
+            ["chapter"] = "Chapter One",
+            ["datetime"] = "2024-08-01 21:10:00",
+            ["drawer"] = "lighten",
+            ["pos0"] = "/body/DocFragment[2]/body/p[1]/text().4",
+            ["pos1"] = "/body/DocFragment[2]/body/p[1]/text().15",
+            ["text"] = "serendipity,",
+        },
+        [2] = {
+            ["chapter"] = "Chapter One",
+            ["page"] = "/body/DocFragment[2]/body/p[2]",
+            ["text"] = "in Chapter One",
+        },
+        [3] = {
+            ["chapter"] = "Chapter One",
+            ["pos0"] = "/body/DocFragment[2]/body/p[2]/text().0",
+            ["pos1"] = "/body/DocFragment[2]/body/p[2]/text().40",
+            ["text"] = "It was \"quoted\" and \u{2014} long,\nvery long indeed",
+            ["note"] = [[a long
+note]],
+        },
+    },
+    ["doc_props"] = {
+        ["authors"] = "Jane Doe",
+        ["title"] = "The Test Book",
+    },
+    ["stats"] = { pages = 120, ["highlights"] = 2; notes = 1 },
+}
+`
+
+// writeEPUB writes a two-chapter EPUB
+func writeEPUB(t *testing.T, path string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	files := []struct{ name, body string }{
+		{"mimetype", "application/epub+zip"},
+		{"META-INF/container.xml", `<?xml version="1.0"?>
+<container xmlns="urn:oasis:names:tc:opendocument:xmlns:container" version="1.0">
+  <rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles>
+</container>`},
+		{"OEBPS/content.opf", `<?xml version="1.0"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title>The Test Book</dc:title></metadata>
+  <manifest>
+    <item id="cover" href="cover.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch1" href="text/ch1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine><itemref idref="cover"/><itemref idref="ch1"/></spine>
+</package>`},
+		{"OEBPS/cover.xhtml", `<html><head><title>Cover</title></head><body><p>Serendipity appears on the cover too.</p></body></html>`},
+		{"OEBPS/text/ch1.xhtml", `<?xml version="1.0" encoding="utf-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml"><head><title>ch1</title><style>p { margin: 0 }</style></head>
+<body><h1>Chapter&nbsp;One</h1>
+<p>Some text first. By pure serendipity, we met
+  again. Then we left.</p><p>Another paragraph.</p></body></html>`},
+	}
+	zw := zip.NewWriter(f)
+	for _, file := range files {
+		w, err := zw.Create(file.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(file.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKOReader_WithEPUBContext(t *testing.T) {
+	dir := t.TempDir()
+	bookPath := filepath.Join(dir, "book.epub")
+	writeEPUB(t, bookPath)
+	if err := os.Mkdir(filepath.Join(dir, "book.sdr"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	metadataPath := filepath.Join(dir, "book.sdr", "metadata.epub.lua")
+	if err := os.WriteFile(metadataPath, []byte(koreaderMetadata), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	marks, err := NewKOReader(zap.NewNop()).ReadHighlights(bookPath)
+	if err != nil {
+		t.Fatalf("ReadHighlights: %v", err)
+	}
+	if len(marks) != 2 {
+		t.Fatalf("expected 2 highlights (the bookmark skipped), got %d", len(marks))
+	}
+	if marks[1].Note != "a long\nnote" || marks[1].Text != "It was \"quoted\" and — long,\nvery long indeed" {
+		t.Errorf("unexpected second highlight: %+v", marks[1])
+	}
+	if got := KOReaderBook(metadataPath); got != bookPath {
+		t.Errorf("KOReaderBook = %q, want %q", got, bookPath)
+	}
+
+	book, err := OpenEPUB(bookPath, zap.NewNop())
+	if err != nil {
+		t.Fatalf("OpenEPUB: %v", err)
+	}
+	book.AddContext(marks)
+
+	words, skipped := ExtractedWords(marks, 4)
+	want := []*core.ExtractedWord{{
+		Target:  "serendipity",
+		Example: "By pure serendipity, we met again.",
+		Chapter: "Chapter One",
+		Tags:    []string{"The_Test_Book"},
+	}}
+	if skipped != 1 || !reflect.DeepEqual(words, want) {
+		t.Errorf("got %+v (skipped %d), want %+v", words[0], skipped, want[0])
+	}
+}
+
+func TestCalibreAndMoonReader(t *testing.T) {
+	dir := t.TempDir()
+	calibrePath := filepath.Join(dir, "book.calibre_highlights")
+	calibre := `{"version": 1, "type": "calibre_highlights", "highlights": [
+		{"type": "highlight", "highlighted_text": "met", "spine_index": 1, "toc_family_titles": ["Part 1", "Chapter One"]},
+		{"type": "highlight", "highlighted_text": "gone", "removed": true},
+		{"type": "bookmark", "title": "here"}]}`
+	if err := os.WriteFile(calibrePath, []byte(calibre), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	marks, err := NewCalibre(zap.NewNop()).ReadHighlights(calibrePath)
+	if err != nil {
+		t.Fatalf("calibre: %v", err)
+	}
+	if len(marks) != 1 || marks[0].Chapter != "Chapter One" || marks[0].Spine != 1 {
+		t.Errorf("calibre: unexpected highlights %+v", marks)
+	}
+
+	moonPath := filepath.Join(dir, "book.mrexpt")
+	moon := "0\nindent:false\ntrim:false\n#\n" +
+		"1\nThe Test Book\n/sdcard/Books/book.epub\n/sdcard/books/book.epub\n1\n0\n120\n11\n-256\n1700000000000\n\n\nserendipity\n0\n0\n0\n#\n" +
+		"2\nOther Book\n/sdcard/Books/other.epub\n/sdcard/books/other.epub\n3\n0\n50\n0\n0\n1700000000001\nbookmark\n\n\n0\n0\n0\n"
+	if err := os.WriteFile(moonPath, []byte(moon), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	marks, err = NewMoonReader(zap.NewNop()).ReadHighlights(moonPath)
+	if err != nil {
+		t.Fatalf("moon reader: %v", err)
+	}
+	want := []*core.Highlight{{Text: "serendipity", Book: "The Test Book", Spine: 1}}
+	if !reflect.DeepEqual(marks, want) {
+		t.Errorf("moon reader: got %+v, want %+v", marks[0], want[0])
+	}
+	if kept := FilterBooks(marks, []string{"other"}); len(kept) != 0 {
+		t.Errorf("FilterBooks kept %+v", kept)
+	}
+}
@@ -0,0 +1,163 @@
+package highlights
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"go.uber.org/zap"
+)
+
+// KOReaderSourceName identifies KOReader sidecar files
+const KOReaderSourceName = "koreader"
+
+// docFragment finds the content document of an EPUB position, e.g. "/body/DocFragment[3]/body/p[5]/text().10"
+var docFragment = regexp.MustCompile(`^/body/DocFragment\[(\d+)\]`)
+
+// KOReader reads the highlights KOReader keeps in the book's sidecar folder ("book.sdr/metadata.epub.lua")
+type KOReader struct {
+	logger *zap.Logger
+}
+
+// NewKOReader creates a KOReader highlight source
+func NewKOReader(logger *zap.Logger) *KOReader {
+	return &KOReader{
+		logger: logger,
+	}
+}
+
+// Name implements core.HighlightSource
+func (k *KOReader) Name() string {
+	return KOReaderSourceName
+}
+
+// ReadHighlights implements core.HighlightSource. The path may be the metadata file, the .sdr folder or the book itself.
+func (k *KOReader) ReadHighlights(path string) ([]*core.Highlight, error) {
+	metadataPath, err := KOReaderMetadata(path)
+	if err != nil {
+		return nil, err
+	}
+	k.logger.Info("Reading highlights", zap.String("path", metadataPath), zap.String("format", KOReaderSourceName))
+
+	data, err := os.ReadFile(metadataPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read KOReader metadata: %w", err)
+	}
+	metadata, err := parseLuaTable(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse KOReader metadata %s: %w", metadataPath, err)
+	}
+
+	title := metadata.table("doc_props").str("title")
+	if title == "" {
+		title = metadata.table("stats").str("title")
+	}
+
+	var entries []luaTable
+	if annotations := metadata.table("annotations"); annotations != nil {
+		// KOReader 2024.07 and later
+		for _, v := range annotations.list() {
+			if entry, ok := v.(luaTable); ok {
+				entries = append(entries, entry)
+			}
+		}
+	} else {
+		// Older versions group highlights by page
+		pages := metadata.table("highlight")
+		keys := make([]float64, 0, len(pages))
+		for key := range pages {
+			if page, ok := key.(float64); ok {
+				keys = append(keys, page)
+			}
+		}
+		sort.Float64s(keys)
+		for _, page := range keys {
+			list, _ := pages[page].(luaTable)
+			for _, v := range list.list() {
+				if entry, ok := v.(luaTable); ok {
+					entries = append(entries, entry)
+				}
+			}
+		}
+	}
+
+	var highlights []*core.Highlight
+	for _, entry := range entries {
+		// Bookmarks have no selection
+		if entry["pos0"] == nil || strings.TrimSpace(entry.str("text")) == "" {
+			continue
+		}
+		highlights = append(highlights, &core.Highlight{
+			Text:    entry.str("text"),
+			Chapter: entry.str("chapter"),
+			Book:    title,
+			Note:    entry.str("note"),
+			Spine:   spineIndex(entry.str("pos0")),
+		})
+	}
+
+	k.logger.Info("Read highlights", zap.Int("count", len(highlights)), zap.String("book", title))
+	return highlights, nil
+}
+
+// KOReaderMetadata finds the metadata file for a book, its .sdr folder or the metadata file itself
+func KOReaderMetadata(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open KOReader metadata: %w", err)
+	}
+	if !info.IsDir() && strings.EqualFold(filepath.Ext(path), ".lua") {
+		return path, nil
+	}
+
+	dir := path
+	if !info.IsDir() {
+		// The book: "book.epub" keeps its metadata in "book.sdr"
+		dir = strings.TrimSuffix(path, filepath.Ext(path)) + ".sdr"
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, "metadata.*.lua"))
+	matches = append(matches, filepath.Join(dir, "metadata.lua"))
+	for _, match := range matches {
+		if _, err := os.Stat(match); err == nil {
+			return match, nil
+		}
+	}
+	return "", fmt.Errorf("no KOReader metadata found in %s", dir)
+}
+
+// KOReaderBook finds the book next to a KOReader sidecar folder, or "" if there is none
+func KOReaderBook(metadataPath string) string {
+	dir := filepath.Dir(metadataPath)
+	if !strings.EqualFold(filepath.Ext(dir), ".sdr") {
+		return ""
+	}
+	// metadata.epub.lua -> .epub
+	ext := filepath.Ext(strings.TrimSuffix(filepath.Base(metadataPath), ".lua"))
+	if ext == "" {
+		ext = ".epub"
+	}
+	book := strings.TrimSuffix(dir, filepath.Ext(dir)) + ext
+	if _, err := os.Stat(book); err != nil {
+		return ""
+	}
+	return book
+}
+
+// spineIndex returns the 0-based content document of an EPUB position, or -1
+func spineIndex(pos string) int {
+	m := docFragment.FindStringSubmatch(pos)
+	if m == nil {
+		return -1
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		return -1
+	}
+	return n - 1
+}
@@ -0,0 +1,332 @@
+package highlights
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// luaTable is a parsed Lua table. Keys are strings or float64; a table with only 1..n keys is still a luaTable.
+type luaTable map[any]any
+
+// str returns the string value of key, or ""
+func (t luaTable) str(key string) string {
+	s, _ := t[key].(string)
+	return s
+}
+
+// table returns the table value of key, or nil
+func (t luaTable) table(key string) luaTable {
+	v, _ := t[key].(luaTable)
+	return v
+}
+
+// list returns the values stored under 1..n, in order
+func (t luaTable) list() []any {
+	var values []any
+	for i := 1; ; i++ {
+		v, ok := t[float64(i)]
+		if !ok {
+			return values
+		}
+		values = append(values, v)
+	}
+}
+
+// parseLuaTable parses a data file of the form "return { ... }", as written by KOReader.
+// Only literals are supported: tables, strings, numbers, booleans and nil.
+func parseLuaTable(src string) (luaTable, error) {
+	p := &luaParser{src: src}
+	p.skipSpace()
+	if strings.HasPrefix(p.src[p.pos:], "return") {
+		p.pos += len("return")
+	}
+	v, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	table, ok := v.(luaTable)
+	if !ok {
+		return nil, fmt.Errorf("expected a Lua table")
+	}
+	return table, nil
+}
+
+type luaParser struct {
+	src string
+	pos int
+}
+
+func (p *luaParser) errorf(format string, args ...any) error {
+	line := strings.Count(p.src[:p.pos], "\n") + 1
+	return fmt.Errorf("lua line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+// skipSpace skips whitespace and -- comments
+func (p *luaParser) skipSpace() {
+	for p.pos < len(p.src) {
+		switch {
+		case unicode.IsSpace(rune(p.src[p.pos])):
+			p.pos++
+		case strings.HasPrefix(p.src[p.pos:], "--"):
+			p.pos += 2
+			if level, ok := p.longBracket(); ok {
+				if _, err := p.longString(level); err != nil {
+					p.pos = len(p.src)
+				}
+				continue
+			}
+			if n := strings.IndexByte(p.src[p.pos:], '\n'); n >= 0 {
+				p.pos += n + 1
+			} else {
+				p.pos = len(p.src)
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *luaParser) value() (any, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("unexpected end of file")
+	}
+	switch c := p.src[p.pos]; {
+	case c == '{':
+		return p.table()
+	case c == '"' || c == '\'':
+		return p.quotedString()
+	case c == '[':
+		level, ok := p.longBracket()
+		if !ok {
+			return nil, p.errorf("unexpected '['")
+		}
+		return p.longString(level)
+	case c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return p.number()
+	}
+	word := p.name()
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "nil":
+		return nil, nil
+	}
+	return nil, p.errorf("unexpected %q", word)
+}
+
+func (p *luaParser) table() (luaTable, error) {
+	p.pos++ // {
+	table := luaTable{}
+	next := 1
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, p.errorf("unterminated table")
+		}
+		if p.src[p.pos] == '}' {
+			p.pos++
+			return table, nil
+		}
+
+		var key any
+		switch start := p.pos; {
+		case p.src[p.pos] == '[' && !strings.HasPrefix(p.src[p.pos:], "[[") && !strings.HasPrefix(p.src[p.pos:], "[="):
+			p.pos++
+			k, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			p.skipSpace()
+			if !p.consume(']') {
+				return nil, p.errorf("expected ']'")
+			}
+			key = k
+		default:
+			if name := p.name(); name != "" {
+				p.skipSpace()
+				if p.pos < len(p.src) && p.src[p.pos] == '=' && !strings.HasPrefix(p.src[p.pos:], "==") {
+					key = name
+					break
+				}
+			}
+			p.pos = start // a positional value
+		}
+
+		if key != nil {
+			p.skipSpace()
+			if !p.consume('=') {
+				return nil, p.errorf("expected '='")
+			}
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		if key == nil {
+			key = float64(next)
+			next++
+		}
+		if v != nil {
+			table[key] = v
+		}
+
+		p.skipSpace()
+		if !p.consume(',') && !p.consume(';') {
+			p.skipSpace()
+			if p.pos >= len(p.src) || p.src[p.pos] != '}' {
+				return nil, p.errorf("expected ',' or '}'")
+			}
+		}
+	}
+}
+
+func (p *luaParser) consume(c byte) bool {
+	if p.pos < len(p.src) && p.src[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *luaParser) name() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c != '_' && !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(p.pos > start && c >= '0' && c <= '9') {
+			break
+		}
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *luaParser) number() (float64, error) {
+	start := p.pos
+	for p.pos < len(p.src) && strings.IndexByte("0123456789+-.eExXabcdefABCDEF", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+	text := p.src[start:p.pos]
+	if n, err := strconv.ParseFloat(text, 64); err == nil {
+		return n, nil
+	}
+	if n, err := strconv.ParseInt(text, 0, 64); err == nil {
+		return float64(n), nil
+	}
+	return 0, p.errorf("invalid number %q", text)
+}
+
+// longBracket reports whether a long bracket "[[" or "[==[" starts at pos and returns its level,
+// moving past it
+func (p *luaParser) longBracket() (int, bool) {
+	rest := p.src[p.pos:]
+	if !strings.HasPrefix(rest, "[") {
+		return 0, false
+	}
+	level := 0
+	for level+1 < len(rest) && rest[level+1] == '=' {
+		level++
+	}
+	if level+1 >= len(rest) || rest[level+1] != '[' {
+		return 0, false
+	}
+	p.pos += level + 2
+	return level, true
+}
+
+func (p *luaParser) longString(level int) (string, error) {
+	closing := "]" + strings.Repeat("=", level) + "]"
+	end := strings.Index(p.src[p.pos:], closing)
+	if end < 0 {
+		return "", p.errorf("unterminated long string")
+	}
+	s := strings.TrimPrefix(p.src[p.pos:p.pos+end], "\n")
+	p.pos += end + len(closing)
+	return s, nil
+}
+
+func (p *luaParser) quotedString() (string, error) {
+	quote := p.src[p.pos]
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		p.pos++
+		switch {
+		case c == quote:
+			return b.String(), nil
+		case c == '\\':
+			if err := p.escape(&b); err != nil {
+				return "", err
+			}
+		case c == '\n':
+			return "", p.errorf("unterminated string")
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// escape writes the escape sequence after a backslash
+func (p *luaParser) escape(b *strings.Builder) error {
+	if p.pos >= len(p.src) {
+		return p.errorf("unterminated string")
+	}
+	c := p.src[p.pos]
+	p.pos++
+	switch c {
+	case 'n':
+		b.WriteByte('\n')
+	case 't':
+		b.WriteByte('\t')
+	case 'r':
+		b.WriteByte('\r')
+	case 'a', 'b', 'f', 'v':
+		// control characters never matter in highlights
+	case '\n':
+		b.WriteByte('\n')
+	case 'z':
+		p.skipSpace()
+	case 'x':
+		if p.pos+2 > len(p.src) {
+			return p.errorf("invalid escape")
+		}
+		n, err := strconv.ParseUint(p.src[p.pos:p.pos+2], 16, 8)
+		if err != nil {
+			return p.errorf("invalid escape")
+		}
+		b.WriteByte(byte(n))
+		p.pos += 2
+	case 'u':
+		end := strings.IndexByte(p.src[p.pos:], '}')
+		if !strings.HasPrefix(p.src[p.pos:], "{") || end < 0 {
+			return p.errorf("invalid escape")
+		}
+		n, err := strconv.ParseUint(p.src[p.pos+1:p.pos+end], 16, 32)
+		if err != nil {
+			return p.errorf("invalid escape")
+		}
+		b.WriteRune(rune(n))
+		p.pos += end + 1
+	default:
+		if c >= '0' && c <= '9' {
+			start := p.pos - 1
+			for p.pos < len(p.src) && p.pos-start < 3 && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+				p.pos++
+			}
+			n, err := strconv.ParseUint(p.src[start:p.pos], 10, 8)
+			if err != nil {
+				return p.errorf("invalid escape")
+			}
+			b.WriteByte(byte(n))
+			return nil
+		}
+		b.WriteByte(c) // \\, \", \'
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package highlights
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"go.uber.org/zap"
+)
+
+// MoonReaderSourceName identifies Moon+ Reader exports
+const MoonReaderSourceName = "moon-reader"
+
+// Field lines of a .mrexpt record, after the "#" line that starts it
+const (
+	moonTitle   = 1
+	moonChapter = 4 // 0-based content document of the book
+	moonNote    = 11
+	moonText    = 12
+	// moonFields is the number of lines up to and including the text
+	moonFields = moonText + 1
+)
+
+// MoonReader reads the .mrexpt backup written by "Export" in the Moon+ Reader notes list
+type MoonReader struct {
+	logger *zap.Logger
+}
+
+// NewMoonReader creates a Moon+ Reader highlight source
+func NewMoonReader(logger *zap.Logger) *MoonReader {
+	return &MoonReader{
+		logger: logger,
+	}
+}
+
+// Name implements core.HighlightSource
+func (m *MoonReader) Name() string {
+	return MoonReaderSourceName
+}
+
+// ReadHighlights implements core.HighlightSource
+func (m *MoonReader) ReadHighlights(path string) ([]*core.Highlight, error) {
+	m.logger.Info("Reading highlights", zap.String("path", path), zap.String("format", MoonReaderSourceName))
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Moon+ Reader export: %w", err)
+	}
+	defer f.Close()
+
+	var (
+		records [][]string
+		record  []string
+		started bool // lines before the first "#" are a settings header
+	)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), 1<<20) //nolint:mnd
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "#" {
+			if started {
+				records = append(records, record)
+			}
+			started, record = true, nil
+			continue
+		}
+		if started {
+			record = append(record, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Moon+ Reader export: %w", err)
+	}
+	if started {
+		records = append(records, record)
+	}
+
+	var highlights []*core.Highlight
+	for _, fields := range records {
+		if len(fields) < moonFields {
+			continue
+		}
+		text := moonPlainText(fields[moonText])
+		if strings.TrimSpace(text) == "" {
+			continue // a bookmark
+		}
+		spine, err := strconv.Atoi(fields[moonChapter])
+		if err != nil {
+			spine = -1
+		}
+		highlights = append(highlights, &core.Highlight{
+			Text:  text,
+			Book:  fields[moonTitle],
+			Note:  moonPlainText(fields[moonNote]),
+			Spine: spine,
+		})
+	}
+	if len(records) > 0 && len(highlights) == 0 {
+		m.logger.Warn("Moon+ Reader export has notes but no highlighted text", zap.Int("records", len(records)))
+	}
+
+	m.logger.Info("Read highlights", zap.Int("count", len(highlights)))
+	return highlights, nil
+}
+
+// moonPlainText restores the line breaks Moon+ Reader stores as <BR>
+func moonPlainText(s string) string {
+	return strings.ReplaceAll(s, "<BR>", "\n")
+}
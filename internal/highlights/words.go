@@ -0,0 +1,73 @@
+// Package highlights reads highlights from e-book readers (KOReader, calibre, Apple Books, Moon+ Reader)
+// and the EPUB books they were made in
+package highlights
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+)
+
+// FilterBooks keeps highlights from books whose title contains one of the given strings (case-insensitive);
+// no strings keeps everything
+func FilterBooks(highlights []*core.Highlight, books []string) []*core.Highlight {
+	if len(books) == 0 {
+		return highlights
+	}
+	var kept []*core.Highlight
+	for _, h := range highlights {
+		title := strings.ToLower(h.Book)
+		if slices.ContainsFunc(books, func(book string) bool { return strings.Contains(title, strings.ToLower(book)) }) {
+			kept = append(kept, h)
+		}
+	}
+	return kept
+}
+
+// ExtractedWords turns highlights into sheet rows, one per highlighted word or phrase, in reading order.
+// Highlights longer than maxWords words are quotes rather than vocabulary and are skipped (0 keeps all).
+// The first sentence and chapter found become the example and chapter, and book titles become tags.
+func ExtractedWords(highlights []*core.Highlight, maxWords int) (words []*core.ExtractedWord, skipped int) {
+	byKey := make(map[string]*core.ExtractedWord)
+	for _, h := range highlights {
+		text := cleanHighlight(h.Text)
+		key := core.NormalizeText(text)
+		if key == "" {
+			continue
+		}
+		if maxWords > 0 && len(strings.Fields(text)) > maxWords {
+			skipped++
+			continue
+		}
+
+		extracted, ok := byKey[key]
+		if !ok {
+			extracted = &core.ExtractedWord{Target: text}
+			byKey[key] = extracted
+			words = append(words, extracted)
+		}
+		if extracted.Example == "" {
+			extracted.Example = h.Sentence
+			if h.Sentence != "" {
+				extracted.Chapter = h.Chapter
+			}
+		}
+		if extracted.Chapter == "" {
+			extracted.Chapter = h.Chapter
+		}
+		if tag := core.TagName(h.Book); tag != "" && !slices.Contains(extracted.Tags, tag) {
+			extracted.Tags = append(extracted.Tags, tag)
+		}
+	}
+	return words, skipped
+}
+
+// cleanHighlight collapses whitespace and drops punctuation and quotes selected around a word
+func cleanHighlight(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	return strings.TrimFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,85 @@
+// Package images provides image sources that work without network access.
+package images
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"go.uber.org/zap"
+)
+
+// LocalProviderName identifies the local image folder in --image-provider
+const LocalProviderName = "local"
+
+// localExtensions are the image formats picked up from the folder
+var localExtensions = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".gif":  true,
+	".webp": true,
+}
+
+// LocalFolder finds images named after the word in a folder, e.g. images/apple.jpg.
+// Names are matched case-insensitively; spaces in phrases may be written as '_' or '-'.
+type LocalFolder struct {
+	files  map[string]string // normalized name without extension -> path
+	logger *zap.Logger
+}
+
+// NewLocalFolder indexes the images in dir
+func NewLocalFolder(dir string, logger *zap.Logger) (*LocalFolder, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image directory: %w", err)
+	}
+
+	files := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(entry.Name())
+		if !localExtensions[strings.ToLower(ext)] {
+			continue
+		}
+		name := localName(strings.TrimSuffix(entry.Name(), ext))
+		if _, exists := files[name]; exists {
+			logger.Warn("Several images for the same word, keeping the first", zap.String("file", entry.Name()))
+			continue
+		}
+		files[name] = filepath.Join(dir, entry.Name())
+	}
+
+	logger.Info("Indexed local images", zap.String("dir", dir), zap.Int("images", len(files)))
+	return &LocalFolder{
+		files:  files,
+		logger: logger,
+	}, nil
+}
+
+// Name implements core.ImageProvider
+func (l *LocalFolder) Name() string {
+	return LocalProviderName
+}
+
+// FindImage implements core.ImageProvider
+func (l *LocalFolder) FindImage(_ context.Context, query string) (*core.Image, error) {
+	path, ok := l.files[localName(query)]
+	if !ok {
+		return nil, fmt.Errorf("%s: query '%s': %w", LocalProviderName, query, core.ErrNoImage)
+	}
+	l.logger.Debug("Found local image", zap.String("query", query), zap.String("path", path))
+	return &core.Image{Path: path}, nil
+}
+
+// localName normalizes a word or file name so that "Ice Cream", "ice_cream" and "ice-cream" match
+func localName(s string) string {
+	s = strings.NewReplacer("_", " ", "-", " ").Replace(s)
+	return core.NormalizeText(s)
+}
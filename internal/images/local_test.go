@@ -0,0 +1,57 @@
+package images
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"go.uber.org/zap"
+)
+
+func TestLocalFolder_FindImage(t *testing.T) {
+	dir := t.TempDir()
+	// apple.png is a second image for apple, and only the first file in name order is kept
+	for _, name := range []string{"Apple.JPG", "apple.png", "keep_in_mind.png", "Ice Cream.webp", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil { //nolint:gosec
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "banana.jpg"), 0755); err != nil { //nolint:gosec
+		t.Fatal(err)
+	}
+
+	folder, err := NewLocalFolder(dir, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewLocalFolder: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"apple", "Apple.JPG"},
+		{"Keep in mind", "keep_in_mind.png"},
+		{"keep-in-mind", "keep_in_mind.png"},
+		{"ice_cream", "Ice Cream.webp"},
+		{"  ICE   cream ", "Ice Cream.webp"},
+	}
+	for _, tt := range tests {
+		image, err := folder.FindImage(context.Background(), tt.query)
+		if err != nil {
+			t.Fatalf("FindImage(%q): %v", tt.query, err)
+		}
+		if image.Path != filepath.Join(dir, tt.want) {
+			t.Errorf("FindImage(%q) = %q, want %q", tt.query, image.Path, tt.want)
+		}
+	}
+
+	for _, query := range []string{"notes", "banana", "pineapple"} {
+		if _, err := folder.FindImage(context.Background(), query); !errors.Is(err, core.ErrNoImage) {
+			t.Errorf("FindImage(%q): expected ErrNoImage, got %v", query, err)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// Package kindle reads the Vocabulary Builder database of Kindle e-readers
+package kindle
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite" // pure-Go SQLite driver
+)
+
+// masteredCategory marks words moved to "Mastered" in the Vocabulary Builder
+const masteredCategory = 100
+
+// lookupsQuery joins every lookup with its word and book. Timestamps are Unix milliseconds.
+const lookupsQuery = `
+SELECT w.word, COALESCE(w.stem, ''), COALESCE(w.lang, ''), COALESCE(w.category, 0),
+       COALESCE(l.usage, ''), COALESCE(l.timestamp, 0),
+       COALESCE(b.title, ''), COALESCE(b.authors, '')
+FROM LOOKUPS l
+JOIN WORDS w ON w.id = l.word_key
+LEFT JOIN BOOK_INFO b ON b.id = l.book_key
+ORDER BY l.timestamp, l.id`
+
+// Lookup is one word looked up while reading
+type Lookup struct {
+	Word      string // as it appeared in the book, e.g. "running"
+	Stem      string // dictionary form, e.g. "run"; may be empty
+	Language  string // language code of the book, e.g. "en"
+	Mastered  bool
+	Usage     string // the sentence the word was looked up in
+	BookTitle string
+	Authors   string
+	LookedUp  time.Time
+}
+
+// Filter selects lookups; zero values select everything
+type Filter struct {
+	// Books keeps lookups from books whose title contains one of these strings (case-insensitive)
+	Books []string
+	// Since and Until bound the lookup time; Until is exclusive
+	Since, Until time.Time
+	// Language keeps lookups in books of this language code
+	Language        string
+	IncludeMastered bool
+}
+
+// Vocab is an open vocab.db, usually found in the system/vocabulary folder of a Kindle
+type Vocab struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// OpenVocab opens a Vocabulary Builder database read-only
+func OpenVocab(path string, logger *zap.Logger) (*Vocab, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to open Kindle vocabulary: %w", err)
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Kindle vocabulary: %w", err)
+	}
+	return &Vocab{
+		db:     db,
+		logger: logger,
+	}, nil
+}
+
+// Close closes the underlying database
+func (v *Vocab) Close() error {
+	return v.db.Close()
+}
+
+// Lookups returns the lookups matching the filter, oldest first
+func (v *Vocab) Lookups(ctx context.Context, filter *Filter) ([]*Lookup, error) {
+	rows, err := v.db.QueryContext(ctx, lookupsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Kindle vocabulary (is it a vocab.db file?): %w", err)
+	}
+	defer rows.Close()
+
+	var lookups []*Lookup
+	total := 0
+	for rows.Next() {
+		var (
+			lookup    Lookup
+			category  int
+			timestamp int64
+		)
+		if err := rows.Scan(&lookup.Word, &lookup.Stem, &lookup.Language, &category,
+			&lookup.Usage, &timestamp, &lookup.BookTitle, &lookup.Authors); err != nil {
+			return nil, fmt.Errorf("failed to read Kindle lookup: %w", err)
+		}
+		total++
+		lookup.Mastered = category == masteredCategory
+		lookup.LookedUp = time.UnixMilli(timestamp)
+		lookup.Usage = strings.TrimSpace(lookup.Usage)
+		if filter.matches(&lookup) {
+			lookups = append(lookups, &lookup)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query Kindle vocabulary: %w", err)
+	}
+
+	v.logger.Info("Read Kindle lookups", zap.Int("total", total), zap.Int("selected", len(lookups)))
+	return lookups, nil
+}
+
+func (f *Filter) matches(lookup *Lookup) bool {
+	if lookup.Mastered && !f.IncludeMastered {
+		return false
+	}
+	if f.Language != "" && lookup.Language != "" && !strings.EqualFold(baseLanguage(lookup.Language), f.Language) {
+		return false
+	}
+	if !f.Since.IsZero() && lookup.LookedUp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !lookup.LookedUp.Before(f.Until) {
+		return false
+	}
+	if len(f.Books) == 0 {
+		return true
+	}
+	title := strings.ToLower(lookup.BookTitle)
+	for _, book := range f.Books {
+		if strings.Contains(title, strings.ToLower(book)) {
+			return true
+		}
+	}
+	return false
+}
+
+// baseLanguage drops the region of a language code: "en-GB" -> "en"
+func baseLanguage(code string) string {
+	base, _, _ := strings.Cut(strings.ReplaceAll(code, "_", "-"), "-")
+	return base
+}
+
+// ExtractedWords turns lookups into sheet rows, one per word (or stem with useStems). The oldest usage
+// becomes the example and the titles of the books the word was looked up in become tags.
+func ExtractedWords(lookups []*Lookup, useStems bool) []*core.ExtractedWord {
+	var words []*core.ExtractedWord
+	byKey := make(map[string]*core.ExtractedWord)
+	for _, lookup := range lookups {
+		word := lookup.Word
+		if useStems && lookup.Stem != "" {
+			word = lookup.Stem
+		}
+		key := core.NormalizeText(word)
+		if key == "" {
+			continue
+		}
+
+		extracted, ok := byKey[key]
+		if !ok {
+			extracted = &core.ExtractedWord{Target: strings.TrimSpace(word)}
+			byKey[key] = extracted
+			words = append(words, extracted)
+		}
+		if extracted.Example == "" {
+			extracted.Example = lookup.Usage
+		}
+		if tag := core.TagName(lookup.BookTitle); tag != "" && !slices.Contains(extracted.Tags, tag) {
+			extracted.Tags = append(extracted.Tags, tag)
+		}
+	}
+	return words
+}
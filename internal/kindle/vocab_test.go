@@ -0,0 +1,104 @@
+package kindle
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// vocabSchema is the part of the Vocabulary Builder schema read by Vocab
+const vocabSchema = `
+CREATE TABLE WORDS (id TEXT PRIMARY KEY NOT NULL, word TEXT, stem TEXT, lang TEXT,
+    category INTEGER DEFAULT 0, timestamp INTEGER DEFAULT 0, profileid TEXT);
+CREATE TABLE LOOKUPS (id TEXT PRIMARY KEY NOT NULL, word_key TEXT, book_key TEXT, dict_key TEXT,
+    pos TEXT, usage TEXT, timestamp INTEGER DEFAULT 0);
+CREATE TABLE BOOK_INFO (id TEXT PRIMARY KEY NOT NULL, asin TEXT, guid TEXT, lang TEXT, title TEXT, authors TEXT);
+
+INSERT INTO BOOK_INFO (id, title, authors) VALUES
+    ('b1', 'The Great Gatsby', 'F. Scott Fitzgerald'),
+    ('b2', 'Der Prozess', 'Franz Kafka');
+INSERT INTO WORDS (id, word, stem, lang, category) VALUES
+    ('en:running', 'running', 'run', 'en', 0),
+    ('en:ran', 'ran', 'run', 'en', 0),
+    ('en:borne', 'borne', 'bear', 'en', 100),
+    ('de:Gericht', 'Gericht', 'Gericht', 'de', 0);
+INSERT INTO LOOKUPS (id, word_key, book_key, usage, timestamp) VALUES
+    ('l1', 'en:running', 'b1', 'He was running late.', 1700000000000),
+    ('l2', 'en:ran', 'b1', 'She ran home.', 1710000000000),
+    ('l3', 'en:borne', 'b1', 'So we beat on, borne back ceaselessly into the past.', 1700000001000),
+    ('l4', 'de:Gericht', 'b2', 'Das Gericht will nichts von dir.', 1700000002000);
+`
+
+func openTestVocab(t *testing.T) *Vocab {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "vocab.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(vocabSchema); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	vocab, err := OpenVocab(path, zap.NewNop())
+	if err != nil {
+		t.Fatalf("OpenVocab: %v", err)
+	}
+	t.Cleanup(func() { vocab.Close() })
+	return vocab
+}
+
+func TestVocab_ExtractedWords(t *testing.T) {
+	vocab := openTestVocab(t)
+
+	lookups, err := vocab.Lookups(context.Background(), &Filter{Language: "en"})
+	if err != nil {
+		t.Fatalf("Lookups: %v", err)
+	}
+	words := ExtractedWords(lookups, true)
+	if len(words) != 1 {
+		t.Fatalf("expected the two forms of run as one word without mastered words, got %+v", words)
+	}
+	if words[0].Target != "run" || words[0].Example != "He was running late." ||
+		!reflect.DeepEqual(words[0].Tags, []string{"The_Great_Gatsby"}) {
+		t.Errorf("unexpected word %+v", words[0])
+	}
+
+	if words := ExtractedWords(lookups, false); len(words) != 2 || words[1].Target != "ran" {
+		t.Errorf("expected word forms without stems, got %+v", words)
+	}
+}
+
+func TestVocab_Filter(t *testing.T) {
+	vocab := openTestVocab(t)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"mastered", Filter{IncludeMastered: true, Language: "en"}, []string{"running", "borne", "ran"}},
+		{"book", Filter{Books: []string{"prozess"}}, []string{"Gericht"}},
+		{"since", Filter{Since: time.UnixMilli(1705000000000)}, []string{"ran"}},
+		{"until", Filter{Until: time.UnixMilli(1700000002000)}, []string{"running"}},
+	}
+	for _, tt := range tests {
+		lookups, err := vocab.Lookups(context.Background(), &tt.filter)
+		if err != nil {
+			t.Fatalf("%s: Lookups: %v", tt.name, err)
+		}
+		var got []string
+		for _, lookup := range lookups {
+			got = append(got, lookup.Word)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
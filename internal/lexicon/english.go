@@ -0,0 +1,195 @@
+package lexicon
+
+import (
+	"strings"
+)
+
+// englishIrregular maps irregular English forms to their lemma
+var englishIrregular = map[string]string{
+	// be, have, do and modal forms
+	"am": "be", "is": "be", "are": "be", "was": "be", "were": "be", "been": "be", "being": "be",
+	"has": "have", "had": "have", "having": "have", "does": "do", "did": "do", "done": "do", "goes": "go",
+	// irregular verbs
+	"ate": "eat", "eaten": "eat", "began": "begin", "begun": "begin", "bent": "bend",
+	"bitten": "bite", "blew": "blow", "blown": "blow", "broke": "break", "broken": "break",
+	"brought": "bring", "built": "build", "bought": "buy", "caught": "catch", "chose": "choose",
+	"chosen": "choose", "came": "come", "dealt": "deal", "dug": "dig", "drew": "draw", "drawn": "draw",
+	"dreamt": "dream", "drank": "drink", "drove": "drive", "driven": "drive",
+	"fell": "fall", "fallen": "fall", "fed": "feed", "felt": "feel", "fought": "fight", "found": "find",
+	"fled": "flee", "flew": "fly", "flown": "fly", "forbade": "forbid", "forgot": "forget",
+	"forgotten": "forget", "forgave": "forgive", "forgiven": "forgive", "froze": "freeze",
+	"frozen": "freeze", "got": "get", "gotten": "get", "gave": "give", "given": "give", "went": "go",
+	"gone": "go", "grew": "grow", "grown": "grow", "hung": "hang", "heard": "hear", "hid": "hide",
+	"hidden": "hide", "held": "hold", "kept": "keep", "knelt": "kneel", "knew": "know", "known": "know",
+	"laid": "lay", "led": "lead", "leapt": "leap", "lent": "lend",
+	"lain": "lie", "lost": "lose", "made": "make", "meant": "mean", "met": "meet",
+	"paid": "pay", "rode": "ride", "ridden": "ride", "rang": "ring", "rung": "ring",
+	"risen": "rise", "ran": "run", "said": "say", "saw": "see", "seen": "see", "sought": "seek",
+	"sold": "sell", "sent": "send", "shook": "shake", "shaken": "shake", "shone": "shine", "shot": "shoot",
+	"shrank": "shrink", "shrunk": "shrink", "sang": "sing", "sung": "sing", "sank": "sink", "sunk": "sink",
+	"sat": "sit", "slept": "sleep", "slid": "slide", "spoke": "speak", "spoken": "speak", "spent": "spend",
+	"spun": "spin", "stood": "stand", "stole": "steal", "stolen": "steal",
+	"stung": "sting", "struck": "strike", "swore": "swear", "sworn": "swear", "swept": "sweep",
+	"swam": "swim", "swum": "swim", "swung": "swing", "took": "take", "taken": "take", "taught": "teach",
+	"tore": "tear", "torn": "tear", "told": "tell", "thought": "think", "threw": "throw",
+	"thrown": "throw", "understood": "understand", "woke": "wake", "woken": "wake", "wore": "wear",
+	"worn": "wear", "wept": "weep", "won": "win", "wrote": "write", "written": "write",
+	"dying": "die", "lying": "lie", "tying": "tie",
+	// irregular plurals
+	"children": "child", "men": "man", "women": "woman", "feet": "foot", "teeth": "tooth", "geese": "goose",
+	"mice": "mouse", "lice": "louse", "oxen": "ox", "dice": "die", "criteria": "criterion",
+	"phenomena": "phenomenon", "analyses": "analysis", "crises": "crisis", "theses": "thesis",
+	// comparatives
+	"better": "good", "best": "good", "worse": "bad", "worst": "bad",
+	// words that only look inflected
+	"news": "news", "series": "series", "species": "species", "always": "always", "perhaps": "perhaps",
+	"thus": "thus", "this": "this", "his": "his", "its": "its", "us": "us", "yes": "yes", "as": "as",
+	"politics": "politics", "physics": "physics", "mathematics": "mathematics", "during": "during",
+	"nothing": "nothing", "something": "something", "anything": "anything", "everything": "everything",
+	"morning": "morning", "evening": "evening", "ceiling": "ceiling", "wedding": "wedding",
+	"need": "need", "speed": "speed", "bed": "bed", "red": "red", "hundred": "hundred", "indeed": "indeed",
+	"seed": "seed", "proceed": "proceed", "succeed": "succeed", "exceed": "exceed",
+}
+
+// English lemmatizes English words with suffix rules. With a lexicon (e.g. a frequency list) it picks the
+// most common candidate, so "created" becomes "create" and "treated" becomes "treat". A word missing from the
+// lexicon, or any word without one, is only replaced when the rules are sure of the lemma ("stopped", "hoped",
+// "studies"); otherwise it is kept as written, so no made-up word such as "creat" ends up on a card.
+type English struct {
+	lexicon Frequency
+}
+
+// NewEnglish creates an English lemmatizer; lexicon may be nil
+func NewEnglish(lexicon Frequency) *English {
+	return &English{
+		lexicon: lexicon,
+	}
+}
+
+// Lemma implements core.Lemmatizer
+func (e *English) Lemma(word string) string {
+	if lemma, ok := englishIrregular[word]; ok {
+		return lemma
+	}
+	if strings.ContainsAny(word, " -'’") || len(word) <= 3 {
+		return word
+	}
+	candidates, sure := englishCandidates(word)
+	if len(candidates) == 0 {
+		return word
+	}
+
+	if e.lexicon != nil {
+		// The word itself is a candidate too ("building", "during"), but loses ties to its lemma
+		best, bestRank := word, e.lexicon.Rank(word)
+		for _, candidate := range candidates {
+			if rank := e.lexicon.Rank(candidate); rank > 0 && (bestRank == 0 || rank <= bestRank) {
+				best, bestRank = candidate, rank
+			}
+		}
+		if bestRank > 0 {
+			return best
+		}
+	}
+	if sure {
+		return candidates[0]
+	}
+	return word
+}
+
+// englishCandidates returns possible lemmas of a word, the likeliest first, and whether the first one is
+// certainly a word
+func englishCandidates(word string) ([]string, bool) {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		return []string{word[:len(word)-3] + "y", word[:len(word)-1]}, true
+	case strings.HasSuffix(word, "ves"):
+		// wolves, knives, waves
+		stem := word[:len(word)-3]
+		return []string{stem + "f", stem + "fe", word[:len(word)-1]}, false
+	case strings.HasSuffix(word, "oes"):
+		// heroes, shoes
+		return []string{word[:len(word)-2], word[:len(word)-1]}, false
+	case hasAnySuffix(word, "sses", "shes", "ches", "xes", "zzes"):
+		return []string{word[:len(word)-2], word[:len(word)-1]}, true
+	case strings.HasSuffix(word, "s") && !hasAnySuffix(word, "ss", "us", "is"):
+		return []string{word[:len(word)-1]}, true
+	case strings.HasSuffix(word, "ied") && len(word) > 4:
+		return []string{word[:len(word)-3] + "y"}, true
+	case strings.HasSuffix(word, "eed"):
+		// agreed, freed
+		return []string{word[:len(word)-1]}, true
+	case strings.HasSuffix(word, "ed"):
+		return verbCandidates(word[:len(word)-2])
+	case strings.HasSuffix(word, "ing") && len(word) > 5:
+		return verbCandidates(word[:len(word)-3])
+	}
+	return nil, false
+}
+
+// verbCandidates guesses the infinitive of the stem left after removing -ed or -ing, and reports whether the
+// spelling leaves no doubt about it
+func verbCandidates(stem string) ([]string, bool) {
+	n := len(stem)
+	if n < 2 { //nolint:mnd
+		return []string{stem, stem + "e"}, false
+	}
+	if strings.HasSuffix(stem, "ng") {
+		// singing, belonging; changed, challenged, plunging; banged or arranged
+		switch stem[max(n-3, 0)] {
+		case 'i', 'o':
+			return []string{stem, stem + "e"}, true
+		case 'e', 'u':
+			return []string{stem + "e", stem}, true
+		}
+		return []string{stem + "e", stem}, false
+	}
+
+	last, prev := stem[n-1], stem[n-2]
+	switch {
+	case last == prev && strings.ContainsRune("aeioulsfz", rune(last)):
+		// seeing, called, passed, buzzed
+		return []string{stem, stem + "e"}, true
+	case last == prev:
+		// stopped, running; nodded or added
+		return []string{stem[:n-1], stem}, last != 'd'
+	case strings.ContainsRune("vzcgu", rune(last)):
+		// loved, danced, judged, argued
+		return []string{stem + "e", stem}, true
+	case isVowel(last) || strings.ContainsRune("wxy", rune(last)):
+		// echoed, showed, fixed, played
+		return []string{stem, stem + "e"}, true
+	case last == 's' && prev != 's':
+		// used, caused, nursed, collapsed
+		return []string{stem + "e", stem}, true
+	case !isVowel(prev) && prev != 'y':
+		// handled, settled; worked, called, passed
+		if last == 'l' && !strings.ContainsRune("lr", rune(prev)) {
+			return []string{stem + "e", stem}, true
+		}
+		return []string{stem, stem + "e"}, true
+	case n >= 3 && isVowel(stem[n-3]):
+		// rained, looked; created or treated
+		return []string{stem, stem + "e"}, !strings.HasSuffix(stem, "eat")
+	case !strings.ContainsAny(stem[:n-2], "aeiouy"):
+		// A single syllable ending in consonant, vowel, consonant doubles the consonant unless a silent e
+		// was dropped: hoped, making, writing, typed
+		return []string{stem + "e", stem}, true
+	default:
+		// visited or decided
+		return []string{stem, stem + "e"}, false
+	}
+}
+
+func isVowel(c byte) bool {
+	return strings.IndexByte("aeiou", c) >= 0
+}
+
+func hasAnySuffix(word string, suffixes ...string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(word, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+// Package lexicon provides word lists used to mine vocabulary from running text: frequency lists,
+// known words and lemmatizers
+package lexicon
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+)
+
+// maxLineSize is the longest line read from word list files
+const maxLineSize = 1 << 20
+
+// Frequency ranks words by how common they are; 1 is the most common word
+type Frequency map[string]int
+
+// ReadFrequencyList reads a list of words from the most to the least common, one per line.
+// Anything after the word is ignored, so "word count" lists (e.g. hermitdave/FrequencyWords) work as is.
+func ReadFrequencyList(path string) (Frequency, error) {
+	ranks := make(Frequency)
+	err := scanLines(path, func(line string) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			return
+		}
+		word := core.NormalizeText(fields[0])
+		if _, seen := ranks[word]; !seen {
+			ranks[word] = len(ranks) + 1
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read frequency list: %w", err)
+	}
+	return ranks, nil
+}
+
+// Rank returns the rank of a word, or 0 if it is not in the list
+func (f Frequency) Rank(word string) int {
+	return f[word]
+}
+
+// Common reports whether a word is among the maxRank most common words
+func (f Frequency) Common(word string, maxRank int) bool {
+	rank := f.Rank(word)
+	return rank > 0 && rank <= maxRank
+}
+
+// scanLines calls fn with every trimmed line that is not empty or a '#' comment
+func scanLines(path string, fn func(line string)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fn(line)
+	}
+	return scanner.Err()
+}
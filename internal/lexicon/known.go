@@ -0,0 +1,50 @@
+package lexicon
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+)
+
+// knownSeparators end the word on lines of a known-words list, so "word — translation" lists can be reused
+var knownSeparators = []string{"\t", " — ", " – ", " - ", " = ", ";", ","}
+
+// KnownWords is a set of normalized words the learner already knows
+type KnownWords map[string]struct{}
+
+// Add adds words to the set
+func (k KnownWords) Add(words ...string) {
+	for _, word := range words {
+		if key := core.NormalizeText(word); key != "" {
+			k[key] = struct{}{}
+		}
+	}
+}
+
+// Contains reports whether any of the words is known
+func (k KnownWords) Contains(words ...string) bool {
+	for _, word := range words {
+		if _, ok := k[core.NormalizeText(word)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadKnownWordsList adds the words of a text file with one word or phrase per line
+func (k KnownWords) ReadKnownWordsList(path string) error {
+	err := scanLines(path, func(line string) {
+		for _, sep := range knownSeparators {
+			if before, _, ok := strings.Cut(line, sep); ok {
+				line = before
+				break
+			}
+		}
+		k.Add(line)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read known words: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package lexicon
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+)
+
+// ListLemmatizer looks words up in a lemma list and falls back to another lemmatizer
+type ListLemmatizer struct {
+	lemmas   map[string]string
+	fallback core.Lemmatizer
+}
+
+// ReadLemmaList reads "lemma<TAB>form" lines, the format of the lemmatization-lists project
+// (github.com/michmech/lemmatization-lists), which has lists for many languages
+func ReadLemmaList(path string, fallback core.Lemmatizer) (*ListLemmatizer, error) {
+	lemmas := make(map[string]string)
+	err := scanLines(path, func(line string) {
+		lemma, form, ok := strings.Cut(line, "\t")
+		if !ok {
+			return
+		}
+		lemma, form = core.NormalizeText(lemma), core.NormalizeText(form)
+		if _, seen := lemmas[form]; !seen && lemma != "" && form != "" {
+			lemmas[form] = lemma
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lemma list: %w", err)
+	}
+	if len(lemmas) == 0 {
+		return nil, fmt.Errorf("lemma list %s has no \"lemma<TAB>form\" lines", path)
+	}
+	return &ListLemmatizer{
+		lemmas:   lemmas,
+		fallback: fallback,
+	}, nil
+}
+
+// Lemma implements core.Lemmatizer
+func (l *ListLemmatizer) Lemma(word string) string {
+	if lemma, ok := l.lemmas[word]; ok {
+		return lemma
+	}
+	return l.fallback.Lemma(word)
+}
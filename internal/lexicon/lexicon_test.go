@@ -0,0 +1,88 @@
+package lexicon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnglish_Lemma(t *testing.T) {
+	lexicon := Frequency{"the": 1, "hope": 40, "hop": 900, "stop": 50, "call": 60, "cal": 5000,
+		"building": 300, "build": 400, "sing": 500, "singe": 9000, "study": 700, "wolf": 800,
+		"change": 100, "create": 200, "treat": 250, "visit": 150}
+	// Without a lexicon a word is only replaced by a lemma the rules are sure of, so none of them is made up
+	tests := []struct {
+		word, rules, withLexicon string
+	}{
+		{"running", "run", "run"},
+		{"stopped", "stop", "stop"},
+		{"hoped", "hope", "hope"},
+		{"making", "make", "make"},
+		{"taking", "take", "take"},
+		{"writing", "write", "write"},
+		{"coming", "come", "come"},
+		{"smiled", "smile", "smile"},
+		{"closed", "close", "close"},
+		{"used", "use", "use"},
+		{"created", "created", "create"},
+		{"treated", "treated", "treat"},
+		{"visited", "visited", "visit"},
+		{"called", "call", "call"},
+		{"changed", "changed", "change"},
+		{"handled", "handle", "handle"},
+		{"added", "added", "added"},
+		{"studies", "study", "study"},
+		{"wolves", "wolves", "wolf"},
+		{"boxes", "box", "box"},
+		{"singing", "sing", "sing"},
+		{"building", "build", "building"},
+		{"went", "go", "go"},
+		{"news", "news", "news"},
+		{"class", "class", "class"},
+		{"well-known", "well-known", "well-known"},
+	}
+	rules, withLexicon := NewEnglish(nil), NewEnglish(lexicon)
+	for _, tt := range tests {
+		if got := rules.Lemma(tt.word); got != tt.rules {
+			t.Errorf("rules: Lemma(%q) = %q, want %q", tt.word, got, tt.rules)
+		}
+		if got := withLexicon.Lemma(tt.word); got != tt.withLexicon {
+			t.Errorf("with lexicon: Lemma(%q) = %q, want %q", tt.word, got, tt.withLexicon)
+		}
+	}
+}
+
+func TestWordLists(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	frequency, err := ReadFrequencyList(write("freq.txt", "\ufeffthe 23135851\nyou 22649804\n\nThe 100\nknow 9000\n"))
+	if err != nil {
+		t.Fatalf("ReadFrequencyList: %v", err)
+	}
+	if frequency.Rank("the") != 1 || frequency.Rank("know") != 3 || !frequency.Common("you", 2) || frequency.Common("know", 2) {
+		t.Errorf("unexpected ranks %v", frequency)
+	}
+
+	known := make(KnownWords)
+	if err := known.ReadKnownWordsList(write("known.txt", "# my words\nApple — яблоко\nkeep in mind\n")); err != nil {
+		t.Fatalf("ReadKnownWordsList: %v", err)
+	}
+	if !known.Contains("apple") || !known.Contains("Keep in  mind") || known.Contains("яблоко") {
+		t.Errorf("unexpected known words %v", known)
+	}
+
+	lemmas, err := ReadLemmaList(write("lemmas.txt", "aller\tva\naller\tallons\n"), NewEnglish(nil))
+	if err != nil {
+		t.Fatalf("ReadLemmaList: %v", err)
+	}
+	if lemmas.Lemma("allons") != "aller" || lemmas.Lemma("running") != "run" {
+		t.Error("lemma list did not use its entries and fallback")
+	}
+}
@@ -0,0 +1,169 @@
+package pdf
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	rscpdf "rsc.io/pdf"
+)
+
+// NativeBackendName is the name of the pure-Go backend in --pdf-backend
+const NativeBackendName = "native"
+
+// Glyph boxes and spacing of the native backend, as fractions of the font size
+const (
+	glyphDescent = 0.2
+	glyphAscent  = 0.8
+	// spaceGap is the smallest gap between two glyphs of a line read as a space. The parser drops space
+	// glyphs, so spaces are recovered from the gaps they leave.
+	spaceGap = 0.15
+	// lineShift is the smallest vertical move read as a new line
+	lineShift = 0.5
+	// fallbackGlyphWidth is the box width of glyphs of fonts without /Widths
+	fallbackGlyphWidth = 0.5
+)
+
+// Native reads PDFs with a pure-Go parser (rsc.io/pdf): no license is needed and it works offline. It reads
+// PDF 1.0-1.7 files, unencrypted or with an empty user password. The parser places characters with the /Widths
+// of their font, so text drawn with a standard font whose widths were left out of the file runs together.
+type Native struct{}
+
+// NewNative creates the pure-Go backend
+func NewNative() *Native {
+	return &Native{}
+}
+
+// Name implements core.PDFBackend
+func (n *Native) Name() string {
+	return NativeBackendName
+}
+
+// Open implements core.PDFBackend
+func (n *Native) Open(path string) (doc core.PDFDocument, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to open PDF: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+		}
+	}()
+	defer recoverError(&err, "failed to read PDF")
+
+	reader, err := rscpdf.NewReader(f, info.Size())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+	return &nativeDocument{file: f, reader: reader}, nil
+}
+
+type nativeDocument struct {
+	file   *os.File
+	reader *rscpdf.Reader
+}
+
+func (d *nativeDocument) NumPages() int {
+	return d.reader.NumPage()
+}
+
+func (d *nativeDocument) Annotations(page int) (annotations []*core.PDFAnnotation, err error) {
+	defer recoverError(&err, fmt.Sprintf("failed to read annotations of page %d", page))
+
+	annots := d.reader.Page(page).V.Key("Annots")
+	for i := 0; i < annots.Len(); i++ {
+		a := annots.Index(i)
+		if a.Key("Subtype").Kind() != rscpdf.Name {
+			continue
+		}
+		annotation := &core.PDFAnnotation{
+			Subtype:    a.Key("Subtype").Name(),
+			Contents:   a.Key("Contents").Text(),
+			QuadPoints: numbers(a.Key("QuadPoints")),
+			Color:      numbers(a.Key("C")),
+			Author:     a.Key("T").Text(),
+			Modified:   a.Key("M").Text(),
+		}
+		if rect := numbers(a.Key("Rect")); len(rect) == 4 { //nolint:mnd
+			annotation.Rect = core.Rect{
+				Llx: math.Min(rect[0], rect[2]), Lly: math.Min(rect[1], rect[3]),
+				Urx: math.Max(rect[0], rect[2]), Ury: math.Max(rect[1], rect[3]),
+			}
+		}
+		annotations = append(annotations, annotation)
+	}
+	return annotations, nil
+}
+
+// Text implements core.PDFDocument. Glyphs are kept in the order they are drawn, which is the reading order of
+// most books; a space is put where a glyph starts a new line or leaves a gap after the previous one.
+func (d *nativeDocument) Text(page int) (text *core.PageText, err error) {
+	defer recoverError(&err, fmt.Sprintf("failed to extract text of page %d", page))
+
+	var (
+		b     strings.Builder
+		marks []core.TextMark
+		prev  *rscpdf.Text
+	)
+	glyphs := d.reader.Page(page).Content().Text
+	for i := range glyphs {
+		glyph := &glyphs[i]
+		if glyph.S == "" {
+			continue
+		}
+		width := glyph.W
+		if width <= 0 {
+			width = fallbackGlyphWidth * glyph.FontSize
+		}
+		if prev != nil && !strings.HasSuffix(b.String(), " ") {
+			size := math.Max(prev.FontSize, glyph.FontSize)
+			newLine := math.Abs(glyph.Y-prev.Y) > lineShift*size || glyph.X < prev.X-size
+			if newLine || glyph.X-(prev.X+prev.W) > spaceGap*size {
+				b.WriteByte(' ')
+			}
+		}
+		marks = append(marks, core.TextMark{
+			Text:   glyph.S,
+			Offset: b.Len(),
+			BBox: core.Rect{
+				Llx: glyph.X, Lly: glyph.Y - glyphDescent*glyph.FontSize,
+				Urx: glyph.X + width, Ury: glyph.Y + glyphAscent*glyph.FontSize,
+			},
+		})
+		b.WriteString(glyph.S)
+		prev = glyph
+	}
+	return &core.PageText{Text: b.String(), Marks: marks}, nil
+}
+
+func (d *nativeDocument) Close() error {
+	return d.file.Close()
+}
+
+// numbers reads an array of numbers
+func numbers(v rscpdf.Value) []float64 {
+	if v.Kind() != rscpdf.Array {
+		return nil
+	}
+	values := make([]float64, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Float64()
+	}
+	return values
+}
+
+// recoverError turns a panic of the parser, which panics on malformed files, into an error
+func recoverError(err *error, message string) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("%s: %v", message, r)
+	}
+}
@@ -0,0 +1,160 @@
+package pdf
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"github.com/unidoc/unipdf/v4/common/license"
+	pdfcore "github.com/unidoc/unipdf/v4/core"
+	"github.com/unidoc/unipdf/v4/extractor"
+	"github.com/unidoc/unipdf/v4/model"
+	"go.uber.org/zap"
+)
+
+// UniPDFBackendName is the name of the UniPDF backend in --pdf-backend
+const UniPDFBackendName = "unipdf"
+
+// UniPDF reads PDFs with UniPDF, which needs a metered license key and access to the license server
+type UniPDF struct {
+	apiKey string
+	logger *zap.Logger
+}
+
+// NewUniPDF creates a UniPDF backend with a metered license key
+func NewUniPDF(apiKey string, logger *zap.Logger) *UniPDF {
+	return &UniPDF{
+		apiKey: apiKey,
+		logger: logger,
+	}
+}
+
+// Name implements core.PDFBackend
+func (u *UniPDF) Name() string {
+	return UniPDFBackendName
+}
+
+// Open implements core.PDFBackend
+func (u *UniPDF) Open(path string) (core.PDFDocument, error) {
+	if err := license.SetMeteredKey(u.apiKey); err != nil {
+		return nil, fmt.Errorf("failed to set UniPDF license: %w", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF: %w", err)
+	}
+	reader, err := model.NewPdfReader(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+	return &uniPDFDocument{file: f, reader: reader, logger: u.logger}, nil
+}
+
+type uniPDFDocument struct {
+	file   *os.File
+	reader *model.PdfReader
+	logger *zap.Logger
+}
+
+func (d *uniPDFDocument) NumPages() int {
+	n, err := d.reader.GetNumPages()
+	if err != nil {
+		d.logger.Warn("Failed to get page count", zap.Error(err))
+	}
+	return n
+}
+
+func (d *uniPDFDocument) Annotations(page int) ([]*core.PDFAnnotation, error) {
+	p, err := d.reader.GetPage(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load page %d: %w", page, err)
+	}
+	annots, err := p.GetAnnotations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read annotations of page %d: %w", page, err)
+	}
+
+	var annotations []*core.PDFAnnotation
+	for i, a := range annots {
+		dict, ok := pdfcore.GetDict(a.GetContainingPdfObject())
+		if !ok {
+			d.logger.Warn("Annotation missing dictionary", zap.Int("page", page), zap.Int("annotation", i+1))
+			continue
+		}
+		subtype, ok := pdfcore.GetNameVal(dict.Get("Subtype"))
+		if !ok {
+			d.logger.Warn("Annotation missing subtype", zap.Int("page", page), zap.Int("annotation", i+1))
+			continue
+		}
+		annotation := &core.PDFAnnotation{
+			Subtype:    subtype,
+			Contents:   uniPDFText(dict.Get("Contents")),
+			QuadPoints: uniPDFNumbers(dict.Get("QuadPoints")),
+			Color:      uniPDFNumbers(dict.Get("C")),
+			Author:     uniPDFText(dict.Get("T")),
+			Modified:   uniPDFText(dict.Get("M")),
+		}
+		if rect := uniPDFNumbers(dict.Get("Rect")); len(rect) == 4 { //nolint:mnd
+			annotation.Rect = core.Rect{Llx: rect[0], Lly: rect[1], Urx: rect[2], Ury: rect[3]}
+		}
+		annotations = append(annotations, annotation)
+	}
+	return annotations, nil
+}
+
+func (d *uniPDFDocument) Text(page int) (*core.PageText, error) {
+	p, err := d.reader.GetPage(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load page %d: %w", page, err)
+	}
+	ext, err := extractor.New(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract text of page %d: %w", page, err)
+	}
+	text, _, _, err := ext.ExtractPageText()
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract text of page %d: %w", page, err)
+	}
+	elements := text.Marks().Elements()
+	marks := make([]core.TextMark, 0, len(elements))
+	for _, mark := range elements { //nolint:gocritic
+		marks = append(marks, core.TextMark{
+			Text:   mark.Text,
+			Offset: mark.Offset,
+			BBox:   core.Rect{Llx: mark.BBox.Llx, Lly: mark.BBox.Lly, Urx: mark.BBox.Urx, Ury: mark.BBox.Ury},
+		})
+	}
+	return &core.PageText{
+		Text:  strings.ReplaceAll(text.Text(), "\n", " "),
+		Marks: marks,
+	}, nil
+}
+
+func (d *uniPDFDocument) Close() error {
+	return d.file.Close()
+}
+
+// uniPDFText decodes a text string (PDFDocEncoding or UTF-16)
+func uniPDFText(obj pdfcore.PdfObject) string {
+	s, ok := pdfcore.GetString(obj)
+	if !ok {
+		return ""
+	}
+	return s.Decoded()
+}
+
+// uniPDFNumbers reads an array of numbers
+func uniPDFNumbers(obj pdfcore.PdfObject) []float64 {
+	arr, ok := pdfcore.GetArray(obj)
+	if !ok {
+		return nil
+	}
+	values, err := arr.ToFloat64Array()
+	if err != nil {
+		return nil
+	}
+	return values
+}
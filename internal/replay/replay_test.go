@@ -0,0 +1,80 @@
+package replay
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/image.jpg" {
+			w.Header().Set("Content-Type", "image/jpeg")
+			_, _ = w.Write([]byte{0xff, 0xd8, 0xff, 0xd9})
+			return
+		}
+		_, _ = io.WriteString(w, `{"q":"`+r.URL.Query().Get("q")+`"}`)
+	}))
+	defer upstream.Close()
+
+	cassette := &Cassette{}
+	client := &http.Client{Transport: NewRecorder(cassette, nil)}
+	for _, path := range []string{"/search?q=apple&key=secret", "/image.jpg"} {
+		resp, err := client.Get(upstream.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	if err := cassette.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("cassette contains the API key: %s", data)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewServer(loaded)
+	defer server.Close()
+	tests := []struct {
+		url, want string
+	}{
+		// The key is not matched, and the transport sends any host to the server
+		{server.URL + "/search?key=other&q=apple", `{"q":"apple"}`},
+		{"https://images.example.com/image.jpg", "\xff\xd8\xff\xd9"},
+	}
+	replayClient := &http.Client{Transport: server.Transport()}
+	for _, tt := range tests {
+		resp, err := replayClient.Get(tt.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || string(body) != tt.want {
+			t.Errorf("%s: got %d %q, want %q", tt.url, resp.StatusCode, body, tt.want)
+		}
+	}
+
+	resp, err := replayClient.Get(server.URL + "/search?q=pear")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotImplemented || len(server.Missing()) != 1 {
+		t.Errorf("unrecorded request: got %d, missing %q", resp.StatusCode, server.Missing())
+	}
+}
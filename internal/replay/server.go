@@ -0,0 +1,97 @@
+package replay
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+)
+
+// Server is a fake HTTP server that answers requests from a cassette. Requests that are not in the cassette
+// get a 501 response and are listed by Missing, so a test can fail instead of silently losing data.
+type Server struct {
+	*httptest.Server
+
+	cassette *Cassette
+	mu       sync.Mutex
+	missing  []string
+}
+
+// NewServer starts a fake server for the cassette; close it with Close
+func NewServer(cassette *Cassette) *Server {
+	s := &Server{cassette: cassette}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	resp, ok := s.cassette.Find(r.Method, r.URL.String())
+	if !ok {
+		s.mu.Lock()
+		s.missing = append(s.missing, r.Method+" "+redactURL(r.URL.String()))
+		s.mu.Unlock()
+		http.Error(w, "request is not in the cassette", http.StatusNotImplemented)
+		return
+	}
+	resp.write(w)
+}
+
+// Missing returns the requests the cassette had no response for
+func (s *Server) Missing() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.missing...)
+}
+
+// Transport returns a transport that sends every request to the server whatever its host, for URLs that
+// come from responses (image and audio files) rather than from a configurable base URL
+func (s *Server) Transport() http.RoundTripper {
+	target, _ := url.Parse(s.URL)
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		req.URL.Scheme = target.Scheme
+		req.URL.Host = target.Host
+		req.Host = ""
+		return s.Client().Transport.RoundTrip(req)
+	})
+}
+
+// Recorder is a transport that sends requests over the network and records the responses into a cassette
+type Recorder struct {
+	cassette *Cassette
+	next     http.RoundTripper
+}
+
+// NewRecorder records into cassette the requests sent over next; nil next means http.DefaultTransport
+func NewRecorder(cassette *Cassette, next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{cassette: cassette, next: next}
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	r.cassette.Add(req.Method, req.URL.String(), resp.StatusCode, resp.Header.Get("Content-Type"), body)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,140 @@
+package subtitles
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/lexicon"
+)
+
+// token matches words, including inner apostrophes and hyphens ("don't", "well-known")
+var token = regexp.MustCompile(`\p{L}+(?:['’-]\p{L}+)*`)
+
+// maxElisionLength is the longest prefix treated as an elided article or pronoun: "l'homme", "qu'il"
+const maxElisionLength = 2
+
+// Options select the words worth learning
+type Options struct {
+	Lemmatizer core.Lemmatizer
+	// Frequency and MaxRank drop common words: the MaxRank most frequent words are assumed known
+	Frequency lexicon.Frequency
+	MaxRank   int
+	Known     lexicon.KnownWords // words already in a deck or known-words list
+	MinLength int                // shorter words are skipped
+	// SkipNames drops words that are never written in lowercase. Disable it for languages that
+	// capitalize nouns, such as German.
+	SkipNames bool
+}
+
+// Stats counts the words the miner dropped
+type Stats struct {
+	Tokens int // words read
+	Short  int
+	Common int
+	Known  int
+	Names  int
+}
+
+// candidate is a word kept so far
+type candidate struct {
+	word      *core.ExtractedWord
+	lowercase bool // seen in lowercase at least once
+}
+
+// Miner collects candidate words from subtitles, in order of first appearance
+type Miner struct {
+	opts       *Options
+	candidates []*candidate
+	byLemma    map[string]*candidate
+	stats      Stats
+}
+
+// NewMiner creates a Miner
+func NewMiner(opts *Options) *Miner {
+	if opts.Lemmatizer == nil {
+		opts.Lemmatizer = core.IdentityLemmatizer{}
+	}
+	return &Miner{
+		opts:    opts,
+		byLemma: make(map[string]*candidate),
+	}
+}
+
+// Add mines the cues of one subtitle file; tag (e.g. the episode) is added to the words found in it
+func (m *Miner) Add(cues []*Cue, tag string) {
+	tag = core.TagName(tag)
+	for _, cue := range cues {
+		for _, text := range token.FindAllString(cue.Text, -1) {
+			m.stats.Tokens++
+			word := splitApostrophe(text)
+			if word == "" || utf8.RuneCountInString(word) < m.opts.MinLength {
+				m.stats.Short++
+				continue
+			}
+
+			lower := strings.ToLower(word)
+			lemma := m.opts.Lemmatizer.Lemma(lower)
+			if m.opts.Known.Contains(lemma, lower) {
+				m.stats.Known++
+				continue
+			}
+			if m.opts.MaxRank > 0 && (m.opts.Frequency.Common(lemma, m.opts.MaxRank) || m.opts.Frequency.Common(lower, m.opts.MaxRank)) {
+				m.stats.Common++
+				continue
+			}
+
+			c, ok := m.byLemma[lemma]
+			if !ok {
+				c = &candidate{word: &core.ExtractedWord{
+					Target:  lemma,
+					Example: fmt.Sprintf("%s [%s]", cue.Text, FormatTimestamp(cue.Start)),
+				}}
+				m.byLemma[lemma] = c
+				m.candidates = append(m.candidates, c)
+			}
+			first, _ := utf8.DecodeRuneInString(word)
+			c.lowercase = c.lowercase || !unicode.IsUpper(first)
+			if tag != "" && !slices.Contains(c.word.Tags, tag) {
+				c.word.Tags = append(c.word.Tags, tag)
+			}
+		}
+	}
+}
+
+// Words returns the candidate words and what was dropped
+func (m *Miner) Words() ([]*core.ExtractedWord, Stats) {
+	stats := m.stats
+	var words []*core.ExtractedWord
+	for _, c := range m.candidates {
+		if m.opts.SkipNames && !c.lowercase {
+			stats.Names++
+			continue
+		}
+		words = append(words, c.word)
+	}
+	return words, stats
+}
+
+// splitApostrophe drops elided articles ("l'homme" -> "homme") and possessives ("John's" -> "John").
+// Other contractions ("don't", "you'll") return "" as they are not vocabulary.
+func splitApostrophe(word string) string {
+	i := strings.IndexAny(word, "'’")
+	if i < 0 {
+		return word
+	}
+	_, size := utf8.DecodeRuneInString(word[i:])
+	before, after := word[:i], word[i+size:]
+	switch {
+	case utf8.RuneCountInString(before) <= maxElisionLength:
+		return after
+	case after == "s":
+		return before
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,144 @@
+// Package subtitles reads SubRip (.srt) and WebVTT (.vtt) subtitles and mines them for vocabulary
+package subtitles
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/wordlist"
+)
+
+// timingArrow separates the start and end time of a cue
+const timingArrow = "-->"
+
+var (
+	// markup matches HTML-like tags (<i>, <v Speaker>, <00:01.000>) and SubRip/ASS style overrides ({\an8})
+	markup = regexp.MustCompile(`<[^>]*>|\{\\[^}]*\}`)
+	// soundDescriptions matches hearing-impaired descriptions: [door slams], (laughs), ♪ lyrics ♪
+	soundDescriptions = regexp.MustCompile(`\[[^\]]*\]|\([^)]*\)|♪[^♪]*♪?`)
+	// speakerLabel matches "JOHN:" or "MAN 2:" at the start of a line
+	speakerLabel = regexp.MustCompile(`^[\p{Lu}][\p{Lu}\d .'-]*:\s*`)
+)
+
+// Cue is one subtitle shown on screen
+type Cue struct {
+	Start time.Duration
+	Text  string // one line, without markup and sound descriptions
+}
+
+// ReadFile reads the cues of an .srt or .vtt file in the given encoding ("" for UTF-8)
+func ReadFile(path, encoding string) ([]*Cue, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open subtitles: %w", err)
+	}
+	defer f.Close()
+
+	r, err := wordlist.Decode(f, encoding)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subtitles: %w", err)
+	}
+	cues, err := Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse subtitles %s: %w", path, err)
+	}
+	return cues, nil
+}
+
+// Parse reads SubRip or WebVTT cues. Both are blocks separated by blank lines with a
+// "start --> end" timing line; blocks without one (WEBVTT header, NOTE, STYLE) are skipped.
+func Parse(data string) ([]*Cue, error) {
+	data = strings.ReplaceAll(strings.ReplaceAll(data, "\r\n", "\n"), "\r", "\n")
+
+	var cues []*Cue
+	for _, block := range strings.Split(data, "\n\n") {
+		lines := strings.Split(strings.Trim(block, "\n"), "\n")
+		timing := -1
+		for i, line := range lines {
+			if strings.Contains(line, timingArrow) {
+				timing = i
+				break
+			}
+		}
+		if timing < 0 || strings.HasPrefix(lines[0], "NOTE") {
+			continue
+		}
+
+		startText, _, _ := strings.Cut(lines[timing], timingArrow)
+		start, err := parseTimestamp(strings.TrimSpace(startText))
+		if err != nil {
+			return nil, err
+		}
+		if text := cleanCue(lines[timing+1:]); text != "" {
+			cues = append(cues, &Cue{Start: start, Text: text})
+		}
+	}
+	if len(cues) == 0 {
+		return nil, fmt.Errorf("no subtitle cues found")
+	}
+	return cues, nil
+}
+
+// cleanCue joins the lines of a cue and drops markup, sound descriptions, speaker labels and dialogue dashes
+func cleanCue(lines []string) string {
+	var parts []string
+	for _, line := range lines {
+		line = markup.ReplaceAllString(line, "")
+		line = soundDescriptions.ReplaceAllString(line, "")
+		line = strings.TrimSpace(line)
+		line = strings.TrimSpace(strings.TrimLeft(line, "-–—"))
+		line = speakerLabel.ReplaceAllString(line, "")
+		if line != "" {
+			parts = append(parts, line)
+		}
+	}
+	return strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+}
+
+// parseTimestamp parses "01:02:03,456" (SubRip), "01:02:03.456" or "02:03.456" (WebVTT)
+func parseTimestamp(s string) (time.Duration, error) {
+	s = strings.Replace(s, ",", ".", 1)
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	var total time.Duration
+	for i, part := range parts {
+		unit := time.Minute
+		if i == len(parts)-1 {
+			seconds, err := strconv.ParseFloat(part, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid timestamp %q", s)
+			}
+			total += time.Duration(seconds * float64(time.Second))
+			break
+		}
+		if len(parts) == 3 && i == 0 {
+			unit = time.Hour
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		total += time.Duration(n) * unit
+	}
+	return total, nil
+}
+
+// FormatTimestamp formats a cue start as "01:02:03"
+func FormatTimestamp(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	hours := d / time.Hour
+	minutes := (d % time.Hour) / time.Minute
+	seconds := (d % time.Minute) / time.Second
+	return fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds)
+}
@@ -0,0 +1,86 @@
+package subtitles
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/lexicon"
+)
+
+const srt = "\ufeff1\r\n00:00:01,500 --> 00:00:03,000\r\n<i>- Walter, the chemistry is flawless.</i>\r\n- [sighs] It's remarkable.\r\n\r\n" +
+	"2\r\n01:02:03,456 --> 01:02:05,000\r\nJESSE: We're cooking, Walter! Flawless cooking.\r\n"
+
+const vtt = `WEBVTT
+Kind: captions
+
+NOTE this is a comment --> with an arrow
+
+STYLE
+::cue { color: yellow }
+
+intro
+00:01.000 --> 00:02.500 align:start
+<v Walter>Say my name. ♪ la la ♪
+
+00:03.000 --> 00:04.000
+{\an8}(whispers) Heisenberg.
+`
+
+func TestParse(t *testing.T) {
+	cues, err := Parse(srt)
+	if err != nil {
+		t.Fatalf("Parse(srt): %v", err)
+	}
+	want := []*Cue{
+		{Start: 1500 * time.Millisecond, Text: "Walter, the chemistry is flawless. It's remarkable."},
+		{Start: time.Hour + 2*time.Minute + 3456*time.Millisecond, Text: "We're cooking, Walter! Flawless cooking."},
+	}
+	if !reflect.DeepEqual(cues, want) {
+		t.Errorf("srt: got %+v %+v", cues[0], cues[len(cues)-1])
+	}
+	if got := FormatTimestamp(cues[1].Start); got != "01:02:03" {
+		t.Errorf("FormatTimestamp = %q", got)
+	}
+
+	cues, err = Parse(vtt)
+	if err != nil {
+		t.Fatalf("Parse(vtt): %v", err)
+	}
+	want = []*Cue{{Start: time.Second, Text: "Say my name."}, {Start: 3 * time.Second, Text: "Heisenberg."}}
+	if !reflect.DeepEqual(cues, want) {
+		t.Errorf("vtt: got %+v", cues)
+	}
+}
+
+func TestMiner(t *testing.T) {
+	cues, err := Parse(srt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	known := make(lexicon.KnownWords)
+	known.Add("remarkable")
+	miner := NewMiner(&Options{
+		Lemmatizer: lexicon.NewEnglish(nil),
+		Frequency:  lexicon.Frequency{"the": 1, "is": 2, "cook": 3},
+		MaxRank:    3,
+		Known:      known,
+		MinLength:  3,
+		SkipNames:  true,
+	})
+	miner.Add(cues, "Breaking Bad S01E01")
+
+	words, stats := miner.Words()
+	want := []*core.ExtractedWord{
+		{Target: "chemistry", Example: "Walter, the chemistry is flawless. It's remarkable. [00:00:01]", Tags: []string{"Breaking_Bad_S01E01"}},
+		{Target: "flawless", Example: "Walter, the chemistry is flawless. It's remarkable. [00:00:01]", Tags: []string{"Breaking_Bad_S01E01"}},
+	}
+	if !reflect.DeepEqual(words, want) {
+		t.Errorf("got %+v", words)
+	}
+	// "cooking" twice is common as "cook", "Walter" is a name
+	if stats.Common != 3 || stats.Known != 1 || stats.Names != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,89 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned by Wait when it does not block: the quota is spent and the limiter does not
+// wait for it, or the wait would last past the deadline of the context
+var ErrRateLimited = errors.New("rate limit reached")
+
+// RateLimiter is a token bucket limiter safe for concurrent use.
+// A nil *RateLimiter never blocks.
+type RateLimiter struct {
+	mu       sync.Mutex
+	capacity float64
+	tokens   float64
+	perToken time.Duration
+	last     time.Time
+	noWait   bool
+}
+
+// NewRateLimiter allows up to events requests per period, refilling continuously.
+// The bucket starts full, so a quota like 50 per hour can be used up front and then refills.
+// Returns nil (unlimited) if events is not positive.
+func NewRateLimiter(events float64, per time.Duration) *RateLimiter {
+	if events <= 0 || per <= 0 {
+		return nil
+	}
+	capacity := math.Max(1, math.Floor(events))
+	return &RateLimiter{
+		capacity: capacity,
+		tokens:   capacity,
+		perToken: time.Duration(float64(per) / events),
+		last:     time.Now(),
+	}
+}
+
+// SetNoWait makes Wait fail with ErrRateLimited instead of blocking once the quota is spent, for quotas
+// that refill too slowly to wait for (e.g. image searches per hour)
+func (l *RateLimiter) SetNoWait(noWait bool) {
+	if l != nil {
+		l.noWait = noWait
+	}
+}
+
+// Wait blocks until a token is available or the context is done. It never blocks past the deadline of the
+// context: if the token would come later, or the limiter does not wait, it returns ErrRateLimited at once.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = math.Min(l.capacity, l.tokens+float64(now.Sub(l.last))/float64(l.perToken))
+	l.last = now
+
+	// Take the token now (possibly going negative) and sleep until it would have been refilled
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens * float64(l.perToken))
+	}
+	if deadline, ok := ctx.Deadline(); delay > 0 && (l.noWait || (ok && now.Add(delay).After(deadline))) {
+		// Give the token back, so callers that do wait are not delayed by this one
+		l.tokens++
+		l.mu.Unlock()
+		return fmt.Errorf("%w: next request allowed in %v", ErrRateLimited, delay.Round(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
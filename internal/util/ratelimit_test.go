@@ -0,0 +1,72 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	// 2 per 100ms: the first two calls pass immediately, the third waits ~50ms
+	limiter := NewRateLimiter(2, 100*time.Millisecond)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if err := limiter.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("Burst should not block, took %v", elapsed)
+	}
+
+	if err := limiter.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Third call should wait for a refill, took %v", elapsed)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	limiter.Wait(ctx) //nolint:errcheck // drain the bucket
+	if err := limiter.Wait(cancelled); err == nil {
+		t.Errorf("Expected error for cancelled context")
+	}
+}
+
+func TestRateLimiter_Unlimited(t *testing.T) {
+	limiter := NewRateLimiter(0, time.Second)
+	if limiter != nil {
+		t.Fatalf("Expected nil limiter for zero rate")
+	}
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Errorf("nil limiter should never fail: %v", err)
+	}
+}
+
+func TestRateLimiter_DoesNotBlockPastDeadline(t *testing.T) {
+	// 1 per hour: the first call passes, the next token is an hour away
+	limiter := NewRateLimiter(1, time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if err := limiter.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if err := limiter.Wait(ctx); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited when the token comes after the deadline, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("Wait should return at once, took %v", elapsed)
+	}
+
+	// Without a deadline it would block, unless the limiter does not wait
+	limiter.SetNoWait(true)
+	if err := limiter.Wait(context.Background()); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited from a no-wait limiter, got %v", err)
+	}
+}
@@ -2,37 +2,127 @@ package util
 
 import (
 	"context"
-	"math"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
 	"time"
 )
 
-// RetryWithBackoff retries a function with exponential backoff
-func RetryWithBackoff(ctx context.Context, maxRetries int, fn func() error) error {
-	var lastErr error
+// RetryPolicy decides whether a failed HTTP request is sent again and how long to wait before that.
+// Transport errors and responses with a retryable status (408, 429, 500, 502, 503, 504) are retried with
+// exponential backoff and full jitter, or after the delay a Retry-After header asks for.
+type RetryPolicy struct {
+	MaxAttempts int           // attempts including the first one
+	BaseDelay   time.Duration // backoff before the second attempt, doubled for each further one
+	MaxDelay    time.Duration // longest wait between attempts; a longer Retry-After ends the retries
+	MaxElapsed  time.Duration // total time after which no attempt is started; 0 means no limit
+}
 
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
+// DefaultRetryPolicy returns the policy used by the API clients and the downloader
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   time.Second,
+		MaxDelay:    30 * time.Second,
+		MaxElapsed:  2 * time.Minute,
+	}
+}
+
+// retryableStatuses are the response statuses worth trying again: timeouts, rate limits and server errors
+var retryableStatuses = map[int]bool{
+	http.StatusRequestTimeout:      true,
+	http.StatusTooManyRequests:     true,
+	http.StatusInternalServerError: true,
+	http.StatusBadGateway:          true,
+	http.StatusServiceUnavailable:  true,
+	http.StatusGatewayTimeout:      true,
+}
+
+// Do sends a request built by newRequest, waiting for the limiter before each attempt, until a response
+// should not be retried or the policy gives up. A new request is built for every attempt, so request bodies
+// are sent in full each time. The last response is returned even if its status is an error, so callers
+// report it as usual; the caller closes its body.
+func (p *RetryPolicy) Do(ctx context.Context, client *http.Client, limiter *RateLimiter,
+	newRequest func() (*http.Request, error)) (*http.Response, error) {
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		if err := limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		req, err := newRequest()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		resp, err := client.Do(req) //nolint:bodyclose
+		if err != nil {
+			err = fmt.Errorf("failed to make request: %w", err)
 		}
 
-		if err := fn(); err == nil {
-			return nil
-		} else {
-			lastErr = err
+		delay, retry := p.retryDelay(ctx, attempt, resp, err)
+		if !retry || attempt >= p.MaxAttempts || (p.MaxElapsed > 0 && time.Since(start)+delay > p.MaxElapsed) {
+			return resp, err
+		}
+		if resp != nil {
+			// Drain the body so the connection can be reused
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) //nolint:mnd
+			resp.Body.Close()
 		}
 
-		// Don't sleep on the last attempt
-		if attempt < maxRetries {
-			backoff := time.Duration(math.Pow(2, float64(attempt))) * time.Second
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(backoff):
-			}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
 		}
 	}
+}
 
-	return lastErr
+// retryDelay reports whether an attempt should be retried and after how long
+func (p *RetryPolicy) retryDelay(ctx context.Context, attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if err != nil {
+		// A cancelled request is not a failure of the server
+		return p.backoff(attempt), ctx.Err() == nil
+	}
+	if !retryableStatuses[resp.StatusCode] {
+		return 0, false
+	}
+	if delay, ok := ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		// Retrying sooner than the server asks is pointless; waiting longer than MaxDelay is not worth it
+		return delay, delay <= p.MaxDelay
+	}
+	return p.backoff(attempt), true
+}
+
+// backoff returns a random delay between zero and the exponential backoff for the attempt ("full jitter"),
+// so clients that failed together do not retry together
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	ceiling := p.MaxDelay
+	if shift := attempt - 1; shift < 32 && p.BaseDelay<<shift < ceiling { //nolint:mnd
+		ceiling = p.BaseDelay << shift
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(ceiling) + 1)) //nolint:gosec
+}
+
+// ParseRetryAfter reads a Retry-After header, given either in seconds or as an HTTP date relative to now
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(date.Sub(now), 0), true
 }
@@ -0,0 +1,104 @@
+package util
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Do(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Second}
+
+	tests := []struct {
+		name         string
+		failures     int    // responses with status before a 200
+		status       int    // status of the failing responses
+		retryAfter   string // Retry-After of the failing responses
+		wantStatus   int
+		wantAttempts int32
+	}{
+		{"server error then success", 2, http.StatusServiceUnavailable, "", http.StatusOK, 3},
+		{"rate limit honours Retry-After", 1, http.StatusTooManyRequests, "0", http.StatusOK, 2},
+		{"Retry-After longer than MaxDelay gives up", 1, http.StatusTooManyRequests, "120", http.StatusTooManyRequests, 1},
+		{"not found is not retried", 1, http.StatusNotFound, "", http.StatusNotFound, 1},
+		{"attempts run out", 5, http.StatusBadGateway, "", http.StatusBadGateway, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// Every attempt must send the whole body
+				if body, _ := io.ReadAll(r.Body); string(body) != "payload" {
+					t.Errorf("attempt %d sent body %q", attempts.Load()+1, body)
+				}
+				if int(attempts.Add(1)) <= tt.failures {
+					if tt.retryAfter != "" {
+						w.Header().Set("Retry-After", tt.retryAfter)
+					}
+					w.WriteHeader(tt.status)
+				}
+			}))
+			defer server.Close()
+
+			resp, err := policy.Do(context.Background(), server.Client(), nil, func() (*http.Request, error) {
+				return http.NewRequest("POST", server.URL, strings.NewReader("payload")) //nolint:noctx
+			})
+			if err != nil {
+				t.Fatalf("Do: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus || attempts.Load() != tt.wantAttempts {
+				t.Errorf("got status %d after %d attempts, want %d after %d", resp.StatusCode, attempts.Load(), tt.wantStatus, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_MaxElapsed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// The server asks for a wait longer than the time left, so the first response is returned at once
+	policy := &RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond, MaxDelay: time.Minute, MaxElapsed: 500 * time.Millisecond}
+	start := time.Now()
+	resp, err := policy.Do(context.Background(), server.Client(), nil, func() (*http.Request, error) {
+		return http.NewRequest("GET", server.URL, http.NoBody) //nolint:noctx
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || time.Since(start) > 400*time.Millisecond {
+		t.Errorf("got status %d after %v", resp.StatusCode, time.Since(start))
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"30", 30 * time.Second, true},
+		{"Wed, 01 May 2024 12:01:00 GMT", time.Minute, true},
+		{"Wed, 01 May 2024 11:00:00 GMT", 0, true},
+		{"-5", 0, false},
+		{"soon", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package wordlist
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Column roles that flashcard fields are read from, used as keys of Options.Columns
+const (
+	ColumnSource       = "source"
+	ColumnTarget       = "target"
+	ColumnPartOfSpeech = "pos"
+	ColumnTags         = "tags"
+	ColumnExample      = "example"
+	ColumnNotes        = "notes"
+	ColumnDeck         = "deck"
+)
+
+// columnRoles lists the roles in the order they are matched against headers
+var columnRoles = []string{ColumnSource, ColumnTarget, ColumnPartOfSpeech, ColumnTags, ColumnExample, ColumnNotes, ColumnDeck}
+
+// headerAliases are the normalized header names recognized for each role. The language names and
+// codes of the deck ("English", "EN") are recognized as well, see roleAliases.
+var headerAliases = map[string][]string{
+	ColumnSource:       {"source", "translation", "meaning"},
+	ColumnTarget:       {"target", "word", "term", "expression"},
+	ColumnPartOfSpeech: {"partofspeech", "pos", "wordclass", "type"},
+	ColumnTags:         {"tags", "tag"},
+	ColumnExample:      {"example", "examples", "sentence", "context"},
+	ColumnNotes:        {"notes", "note", "comment", "comments"},
+	ColumnDeck:         {"deck", "subdeck"},
+}
+
+// ignoredHeaders are columns written by this tool that are not flashcard data
+var ignoredHeaders = []string{"review", "added"}
+
+// maxHeaderRow is how many rows are searched for the header row, so workbooks may have a title above the table
+const maxHeaderRow = 10
+
+// Options selects how word pairs are read from an input file
+type Options struct {
+	// Sheet is a sheet name or 1-based index of a workbook; empty reads the first sheet
+	Sheet string
+	// Columns maps roles (ColumnSource, ColumnTarget, ...) to a header name or column letter,
+	// overriding header detection
+	Columns map[string]string
+	// NoHeader reads every row as data, with the source in column A, the target in B and the part of speech in C
+	NoHeader bool
+	// Languages names the source and target columns ("Russian", "English")
+	Languages core.LanguagePair
+	// Delimiter separates CSV/TSV fields; zero uses ',' for CSV and a tab for TSV
+	Delimiter rune
+	// NoQuotes reads CSV/TSV fields literally instead of unquoting "..." fields
+	NoQuotes bool
+	// Encoding of CSV/TSV and text files, e.g. "windows-1251"; empty means UTF-8
+	Encoding string
+}
+
+// columnMap is the position (0-based) of each role in a sheet
+type columnMap struct {
+	headerRow int            // 0-based index of the header row
+	roles     map[string]int // role -> column
+	extra     map[int]string // other named columns -> header
+}
+
+// column returns the value of a role in a row, or "" if the sheet has no such column
+func (m *columnMap) column(row []string, role string) string {
+	i, ok := m.roles[role]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+// extras returns the values of the unmapped columns of a row by header
+func (m *columnMap) extras(row []string) map[string]string {
+	var values map[string]string
+	for i, header := range m.extra {
+		if i >= len(row) || strings.TrimSpace(row[i]) == "" {
+			continue
+		}
+		if values == nil {
+			values = make(map[string]string)
+		}
+		values[header] = strings.TrimSpace(row[i])
+	}
+	return values
+}
+
+// detectColumns finds the header row and maps its columns to roles. Sheets without a recognizable
+// header use the original layout: source in A, target in B, part of speech in C.
+func detectColumns(rows [][]string, opts *Options) (*columnMap, error) {
+	for role := range opts.Columns {
+		if _, ok := headerAliases[role]; !ok {
+			return nil, fmt.Errorf("unknown column role %q, expected one of: %s", role, strings.Join(columnRoles, ", "))
+		}
+	}
+	if opts.NoHeader {
+		m, err := positionalColumns(opts)
+		if err != nil {
+			return nil, err
+		}
+		m.headerRow = -1
+		return m, nil
+	}
+
+	for i := 0; i < len(rows) && i < maxHeaderRow; i++ {
+		m := mapHeader(rows[i], opts)
+		if _, ok := m.roles[ColumnSource]; !ok {
+			continue
+		}
+		if _, ok := m.roles[ColumnTarget]; !ok {
+			continue
+		}
+		m.headerRow = i
+		return m, nil
+	}
+
+	// No header found: fall back to positions, with explicit columns given as letters
+	m, err := positionalColumns(opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) > 0 {
+		m.extra = extraColumns(rows[0], m.roles)
+	}
+	return m, nil
+}
+
+// positionalColumns returns the original A/B/C layout with explicit columns given as letters
+func positionalColumns(opts *Options) (*columnMap, error) {
+	m := &columnMap{roles: map[string]int{ColumnSource: 0, ColumnTarget: 1, ColumnPartOfSpeech: 2}}
+	for role, name := range opts.Columns {
+		index, err := columnIndex(name)
+		if err != nil {
+			return nil, fmt.Errorf("column %q for %s not found in the header row", name, role)
+		}
+		// An explicit column replaces the default role at the same position
+		for other, i := range m.roles {
+			if i == index {
+				delete(m.roles, other)
+			}
+		}
+		m.roles[role] = index
+	}
+	return m, nil
+}
+
+// mapHeader maps the cells of a candidate header row to roles
+func mapHeader(header []string, opts *Options) *columnMap {
+	m := &columnMap{roles: make(map[string]int)}
+	taken := make(map[int]bool)
+
+	// Explicit columns first, so detection never steals their header
+	for _, role := range columnRoles {
+		name, ok := opts.Columns[role]
+		if !ok {
+			continue
+		}
+		if i := slices.IndexFunc(header, func(cell string) bool { return normalizeHeader(cell) == normalizeHeader(name) }); i >= 0 {
+			m.roles[role], taken[i] = i, true
+		} else if i, err := columnIndex(name); err == nil {
+			m.roles[role], taken[i] = i, true
+		}
+	}
+	for _, role := range columnRoles {
+		if _, ok := opts.Columns[role]; ok {
+			continue
+		}
+		aliases := roleAliases(role, opts.Languages)
+		for i, cell := range header {
+			if !taken[i] && slices.Contains(aliases, normalizeHeader(cell)) {
+				m.roles[role], taken[i] = i, true
+				break
+			}
+		}
+	}
+	m.extra = extraColumns(header, m.roles)
+	return m
+}
+
+// roleAliases returns the header names of a role, including the deck's language name and code
+func roleAliases(role string, langs core.LanguagePair) []string {
+	aliases := headerAliases[role]
+	var lang string
+	switch role {
+	case ColumnSource:
+		lang = langs.Source
+	case ColumnTarget:
+		lang = langs.Target
+	default:
+		return aliases
+	}
+	if lang == "" {
+		return aliases
+	}
+	aliases = append(slices.Clip(aliases), lang)
+	if name := core.LanguageName(lang); name != "" {
+		aliases = append(aliases, name)
+	}
+	return aliases
+}
+
+// extraColumns returns the named header cells that are not mapped to a role
+func extraColumns(header []string, roles map[string]int) map[int]string {
+	mapped := make(map[int]bool, len(roles))
+	for _, i := range roles {
+		mapped[i] = true
+	}
+	extra := make(map[int]string)
+	for i, cell := range header {
+		name := strings.TrimSpace(cell)
+		if mapped[i] || name == "" || slices.Contains(ignoredHeaders, normalizeHeader(name)) {
+			continue
+		}
+		extra[i] = name
+	}
+	return extra
+}
+
+// maxColumnLetters limits column letters to A-ZZ, so short headers like "POS" are not taken for columns
+const maxColumnLetters = 2
+
+// columnIndex converts a column letter ("C") to a 0-based index
+func columnIndex(name string) (int, error) {
+	name = strings.TrimSpace(name)
+	if len(name) > maxColumnLetters {
+		return 0, fmt.Errorf("invalid column letter %q", name)
+	}
+	n, err := excelize.ColumnNameToNumber(name)
+	if err != nil {
+		return 0, err
+	}
+	return n - 1, nil
+}
+
+// normalizeHeader lowercases a header and drops everything but letters and digits,
+// so "Part of speech", "part_of_speech" and "PartOfSpeech" match
+func normalizeHeader(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// splitTags splits a tags cell on commas, semicolons and spaces, the separators used in spreadsheets and Anki
+func splitTags(cell string) []string {
+	var tags []string
+	for _, tag := range strings.FieldsFunc(cell, func(r rune) bool {
+		return r == ',' || r == ';' || unicode.IsSpace(r)
+	}) {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,130 @@
+package wordlist
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"go.uber.org/zap"
+)
+
+// Names of the delimited word sources
+const (
+	CSVSourceName = "csv"
+	TSVSourceName = "tsv"
+)
+
+// maxLineSize is the longest line read from unquoted files
+const maxLineSize = 1 << 20
+
+// DelimitedFile reads word pairs from CSV and TSV files with the same column layout as workbooks
+type DelimitedFile struct {
+	name      string
+	delimiter rune
+	opts      *Options
+	logger    *zap.Logger
+}
+
+// NewCSV creates a CSV word source; fields are separated by commas unless Options.Delimiter is set
+func NewCSV(opts *Options, logger *zap.Logger) *DelimitedFile {
+	return newDelimitedFile(CSVSourceName, ',', opts, logger)
+}
+
+// NewTSV creates a TSV word source; fields are separated by tabs unless Options.Delimiter is set
+func NewTSV(opts *Options, logger *zap.Logger) *DelimitedFile {
+	return newDelimitedFile(TSVSourceName, '\t', opts, logger)
+}
+
+func newDelimitedFile(name string, delimiter rune, opts *Options, logger *zap.Logger) *DelimitedFile {
+	if opts.Delimiter != 0 {
+		delimiter = opts.Delimiter
+	}
+	return &DelimitedFile{
+		name:      name,
+		delimiter: delimiter,
+		opts:      opts,
+		logger:    logger,
+	}
+}
+
+// Name implements core.WordSource
+func (d *DelimitedFile) Name() string {
+	return d.name
+}
+
+// ReadWordPairs implements core.WordSource
+func (d *DelimitedFile) ReadWordPairs(path string) ([]*core.RawFlashcard, error) {
+	d.logger.Info("Reading word list", zap.String("path", path), zap.String("format", d.name))
+
+	rows, err := d.readRows(path)
+	if err != nil {
+		return nil, err
+	}
+	wordPairs, err := ParseRows(rows, d.opts, d.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	d.logger.Info("Successfully read word pairs", zap.Int("count", len(wordPairs)))
+	return wordPairs, nil
+}
+
+// Validate implements core.WordSource
+func (d *DelimitedFile) Validate(path string) error {
+	rows, err := d.readRows(path)
+	if err != nil {
+		return err
+	}
+	dataRows, err := ValidateRows(rows, d.opts)
+	if err != nil {
+		return err
+	}
+
+	d.logger.Info("Word list validation passed",
+		zap.String("file", path),
+		zap.Int("total_rows", len(rows)),
+		zap.Int("data_rows", dataRows))
+	return nil
+}
+
+// readRows reads all records of the file
+func (d *DelimitedFile) readRows(path string) ([][]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s file: %w", d.name, err)
+	}
+	defer f.Close()
+
+	r, err := Decode(f, d.opts.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.opts.NoQuotes {
+		var rows [][]string
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+		for scanner.Scan() {
+			line := strings.TrimSuffix(scanner.Text(), "\r")
+			rows = append(rows, strings.Split(line, string(d.delimiter)))
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read %s file: %w", d.name, err)
+		}
+		return rows, nil
+	}
+
+	reader := csv.NewReader(r)
+	reader.Comma = d.delimiter
+	reader.FieldsPerRecord = -1 // rows may have trailing empty cells cut off
+	reader.LazyQuotes = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s file: %w", d.name, err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,25 @@
+package wordlist
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Decode converts text in the named encoding (e.g. "windows-1251", "koi8-r", "utf-16") to UTF-8.
+// UTF-8 is the default; its byte order mark, added by Excel's CSV export, is dropped.
+func Decode(r io.Reader, encoding string) (io.Reader, error) {
+	switch strings.ToLower(encoding) {
+	case "", "utf-8", "utf8":
+		return transform.NewReader(r, unicode.UTF8BOM.NewDecoder()), nil
+	}
+	enc, err := htmlindex.Get(encoding)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported encoding %q: %w", encoding, err)
+	}
+	return transform.NewReader(r, enc.NewDecoder()), nil
+}
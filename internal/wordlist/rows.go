@@ -0,0 +1,123 @@
+// Package wordlist turns tables and text files of word pairs into raw flashcards
+package wordlist
+
+import (
+	"fmt"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"github.com/xuri/excelize/v2"
+	"go.uber.org/zap"
+)
+
+// ParseRows reads word pairs from table rows. Columns are found by their headers (see Options);
+// without a recognizable header the source language word (e.g. Russian) is read from column A,
+// the target language word (e.g. English) from B and the part of speech from C.
+// Named columns without a role are passed through as extra fields.
+func ParseRows(rows [][]string, opts *Options, logger *zap.Logger) ([]*core.RawFlashcard, error) {
+	if err := checkRowCount(rows, opts); err != nil {
+		return nil, err
+	}
+	columns, err := detectColumns(rows, opts)
+	if err != nil {
+		return nil, err
+	}
+	logColumns(columns, logger)
+
+	var wordPairs []*core.RawFlashcard
+
+	// Skip header row, process data rows
+	for i, row := range rows[columns.headerRow+1:] {
+		rowNumber := columns.headerRow + i + 2
+
+		source := columns.column(row, ColumnSource)
+		target := columns.column(row, ColumnTarget)
+
+		// Skip empty rows
+		if source == "" && target == "" {
+			logger.Debug("Skipping empty row", zap.Int("row", rowNumber))
+			continue
+		}
+		if source == "" || target == "" {
+			logger.Warn("Skipping row without translation (fill in both the source and target columns)",
+				zap.Int("row", rowNumber), zap.String("source", source), zap.String("target", target))
+			continue
+		}
+
+		wordPair := &core.RawFlashcard{
+			Source:       source,
+			Target:       target,
+			PartOfSpeech: columns.column(row, ColumnPartOfSpeech),
+			Tags:         splitTags(columns.column(row, ColumnTags)),
+			Example:      columns.column(row, ColumnExample),
+			Notes:        columns.column(row, ColumnNotes),
+			Deck:         columns.column(row, ColumnDeck),
+			Extra:        columns.extras(row),
+		}
+
+		wordPairs = append(wordPairs, wordPair)
+		logger.Debug("Read word pair", zap.String("source", source), zap.String("target", target))
+	}
+
+	return wordPairs, nil
+}
+
+// ValidateRows checks that rows have a usable layout and at least one word pair, and returns the number of word pairs
+func ValidateRows(rows [][]string, opts *Options) (int, error) {
+	if err := checkRowCount(rows, opts); err != nil {
+		return 0, err
+	}
+	columns, err := detectColumns(rows, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	// Validate that we have some data rows
+	dataRows, untranslated := 0, 0
+	for _, row := range rows[columns.headerRow+1:] {
+		source, target := columns.column(row, ColumnSource), columns.column(row, ColumnTarget)
+		switch {
+		case source != "" && target != "":
+			dataRows++
+		case target != "":
+			untranslated++
+		}
+	}
+	if dataRows == 0 && untranslated > 0 {
+		return 0, fmt.Errorf("no valid data rows found: all %d words have no translation in the source column "+
+			"(fill it in, or extract the words again with --translator)", untranslated)
+	}
+	if dataRows == 0 {
+		return 0, fmt.Errorf("no valid data rows found (rows need both a source and a target word)")
+	}
+	return dataRows, nil
+}
+
+// checkRowCount requires a header and a data row, or a data row with Options.NoHeader
+func checkRowCount(rows [][]string, opts *Options) error {
+	if opts.NoHeader {
+		if len(rows) == 0 {
+			return fmt.Errorf("input has no rows")
+		}
+		return nil
+	}
+	if len(rows) < 2 {
+		return fmt.Errorf("input must have at least 2 rows (header + data)")
+	}
+	return nil
+}
+
+// logColumns logs where each role was found
+func logColumns(columns *columnMap, logger *zap.Logger) {
+	fields := []zap.Field{zap.Int("header_row", columns.headerRow+1)}
+	for _, role := range columnRoles {
+		if i, ok := columns.roles[role]; ok {
+			name, _ := excelize.ColumnNumberToName(i + 1)
+			fields = append(fields, zap.String(role, name))
+		}
+	}
+	if len(columns.extra) > 0 {
+		fields = append(fields, zap.Int("extra_columns", len(columns.extra)))
+	}
+	logger.Info("Mapped columns", fields...)
+}
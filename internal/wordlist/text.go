@@ -0,0 +1,157 @@
+package wordlist
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"go.uber.org/zap"
+)
+
+// TextSourceName identifies line-based text word lists
+const TextSourceName = "text"
+
+// textSeparators split a line into the word and its translation, tried in order
+var textSeparators = []string{"\t", " — ", " – ", " - ", " = ", "—", "–", "="}
+
+// textPartsOfSpeech are the part of speech marks recognized in parentheses after a word
+var textPartsOfSpeech = map[string]string{
+	"n":            "noun",
+	"noun":         "noun",
+	"v":            "verb",
+	"verb":         "verb",
+	"adj":          "adjective",
+	"adjective":    "adjective",
+	"adv":          "adverb",
+	"adverb":       "adverb",
+	"pron":         "pronoun",
+	"pronoun":      "pronoun",
+	"prep":         "preposition",
+	"preposition":  "preposition",
+	"conj":         "conjunction",
+	"conjunction":  "conjunction",
+	"interj":       "interjection",
+	"interjection": "interjection",
+	"phr":          "phrase",
+	"phrase":       "phrase",
+	"phrasal verb": "phrasal verb",
+	"idiom":        "idiom",
+}
+
+// TextFile reads word lists with one "word — translation" pair per line. The word is in the target
+// language and may be followed by a part of speech in parentheses: "run (v) — бежать".
+// Empty lines and lines starting with '#' are ignored.
+type TextFile struct {
+	opts   *Options
+	logger *zap.Logger
+}
+
+// NewText creates a text word source
+func NewText(opts *Options, logger *zap.Logger) *TextFile {
+	return &TextFile{
+		opts:   opts,
+		logger: logger,
+	}
+}
+
+// Name implements core.WordSource
+func (t *TextFile) Name() string {
+	return TextSourceName
+}
+
+// ReadWordPairs implements core.WordSource
+func (t *TextFile) ReadWordPairs(path string) ([]*core.RawFlashcard, error) {
+	t.logger.Info("Reading word list", zap.String("path", path), zap.String("format", TextSourceName))
+
+	wordPairs, _, err := t.read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	t.logger.Info("Successfully read word pairs", zap.Int("count", len(wordPairs)))
+	return wordPairs, nil
+}
+
+// Validate implements core.WordSource
+func (t *TextFile) Validate(path string) error {
+	wordPairs, lines, err := t.read(path)
+	if err != nil {
+		return err
+	}
+	if len(wordPairs) == 0 {
+		return fmt.Errorf("no word pairs found (lines need a word and its translation, e.g. \"apple — яблоко\")")
+	}
+
+	t.logger.Info("Word list validation passed",
+		zap.String("file", path),
+		zap.Int("total_lines", lines),
+		zap.Int("data_rows", len(wordPairs)))
+	return nil
+}
+
+// read parses the file and returns its word pairs and number of lines
+func (t *TextFile) read(path string) ([]*core.RawFlashcard, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open text file: %w", err)
+	}
+	defer f.Close()
+
+	r, err := Decode(f, t.opts.Encoding)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var wordPairs []*core.RawFlashcard
+	lines := 0
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+	for scanner.Scan() {
+		lines++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		wordPair := parseTextLine(line)
+		if wordPair == nil {
+			t.logger.Warn("Skipping line without translation (write \"word — translation\")",
+				zap.Int("line", lines), zap.String("text", line))
+			continue
+		}
+		wordPairs = append(wordPairs, wordPair)
+		t.logger.Debug("Read word pair", zap.String("source", wordPair.Source), zap.String("target", wordPair.Target))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read text file: %w", err)
+	}
+	return wordPairs, lines, nil
+}
+
+// parseTextLine splits "word (pos) — translation"; it returns nil if a side is missing
+func parseTextLine(line string) *core.RawFlashcard {
+	for _, sep := range textSeparators {
+		target, source, ok := strings.Cut(line, sep)
+		if !ok {
+			continue
+		}
+		target, source = strings.TrimSpace(target), strings.TrimSpace(source)
+		if target == "" || source == "" {
+			return nil
+		}
+
+		wordPair := &core.RawFlashcard{Source: source, Target: target}
+		if open := strings.LastIndex(target, "("); open > 0 && strings.HasSuffix(target, ")") {
+			mark := strings.ToLower(strings.TrimSpace(strings.Trim(target[open+1:len(target)-1], ".")))
+			if pos, known := textPartsOfSpeech[mark]; known {
+				wordPair.Target = strings.TrimSpace(target[:open])
+				wordPair.PartOfSpeech = pos
+			}
+		}
+		return wordPair
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package wordlist
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"go.uber.org/zap"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil { //nolint:gosec
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDelimitedFile_Windows1251(t *testing.T) {
+	text := "Перевод;English;Tags\r\nяблоко;apple;food\r\n\"ключ; клавиша\";key;\r\n;orphan;\r\n"
+	encoded, err := charmap.Windows1251.NewEncoder().String(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := writeFile(t, "words.csv", []byte(encoded))
+
+	opts := &Options{
+		Columns:   map[string]string{ColumnSource: "Перевод"},
+		Languages: core.DefaultLanguagePair,
+		Delimiter: ';',
+		Encoding:  "windows-1251",
+	}
+	source := NewCSV(opts, zap.NewNop())
+	if err := source.Validate(path); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	words, err := source.ReadWordPairs(path)
+	if err != nil {
+		t.Fatalf("ReadWordPairs: %v", err)
+	}
+	want := []*core.RawFlashcard{
+		{Source: "яблоко", Target: "apple", Tags: []string{"food"}},
+		{Source: "ключ; клавиша", Target: "key"},
+	}
+	if !reflect.DeepEqual(words, want) {
+		t.Errorf("got %+v, want %+v", words, want)
+	}
+}
+
+func TestDelimitedFile_NoHeaderNoQuotes(t *testing.T) {
+	path := writeFile(t, "words.tsv", []byte("\xEF\xBB\xBFкнига\t\"book\"\tnoun\n"))
+
+	words, err := NewTSV(&Options{NoHeader: true, NoQuotes: true}, zap.NewNop()).ReadWordPairs(path)
+	if err != nil {
+		t.Fatalf("ReadWordPairs: %v", err)
+	}
+	want := []*core.RawFlashcard{{Source: "книга", Target: `"book"`, PartOfSpeech: "noun"}}
+	if !reflect.DeepEqual(words, want) {
+		t.Errorf("got %+v, want %+v", words, want)
+	}
+}
+
+func TestTextFile_ReadWordPairs(t *testing.T) {
+	path := writeFile(t, "words.txt", []byte(`# team list
+apple — яблоко
+run (v.) — бежать
+keep in mind - иметь ввиду
+bank (of a river) — берег
+well-known = известный
+no translation here
+`))
+
+	source := NewText(&Options{}, zap.NewNop())
+	if err := source.Validate(path); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	words, err := source.ReadWordPairs(path)
+	if err != nil {
+		t.Fatalf("ReadWordPairs: %v", err)
+	}
+	want := []*core.RawFlashcard{
+		{Source: "яблоко", Target: "apple"},
+		{Source: "бежать", Target: "run", PartOfSpeech: "verb"},
+		{Source: "иметь ввиду", Target: "keep in mind"},
+		{Source: "берег", Target: "bank (of a river)"},
+		{Source: "известный", Target: "well-known"},
+	}
+	if !reflect.DeepEqual(words, want) {
+		t.Errorf("got %+v, want %+v", words, want)
+	}
+}
+
+func TestValidateRows_Untranslated(t *testing.T) {
+	// A sheet written by extract-pdf without a translator: the source column is empty
+	rows := [][]string{{"Russian", "English", "Part of Speech"}, {"", "serendipity", "noun"}, {"", "ubiquitous", "adjective"}}
+	_, err := ValidateRows(rows, &Options{Languages: core.DefaultLanguagePair})
+	if err == nil || !strings.Contains(err.Error(), "all 2 words have no translation") {
+		t.Errorf("got %v, want an error naming the missing translations", err)
+	}
+
+	rows = append(rows, []string{"книга", "book", "noun"})
+	if n, err := ValidateRows(rows, &Options{Languages: core.DefaultLanguagePair}); n != 1 || err != nil {
+		t.Errorf("got %d rows, %v; want the translated row", n, err)
+	}
+}
@@ -4,9 +4,11 @@ package free_dictionary
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/util"
@@ -14,10 +16,15 @@ import (
 	"go.uber.org/zap"
 )
 
+// ErrNotFound is returned when the dictionary has no entry for the word
+var ErrNotFound = errors.New("word not found")
+
 // API client for Free Dictionary API
 type API struct {
 	client  *http.Client
 	baseURL string
+	limiter *util.RateLimiter
+	retry   *util.RetryPolicy
 	logger  *zap.Logger
 }
 
@@ -28,10 +35,26 @@ func NewAPI(logger *zap.Logger) *API {
 			Timeout: 30 * time.Second,
 		},
 		baseURL: "https://api.dictionaryapi.dev/api/v2/entries/en",
+		retry:   util.DefaultRetryPolicy(),
 		logger:  logger,
 	}
 }
 
+// SetRateLimiter limits how often the API is called; nil means unlimited
+func (api *API) SetRateLimiter(limiter *util.RateLimiter) {
+	api.limiter = limiter
+}
+
+// SetBaseURL points the client at another server, e.g. a fake server in tests
+func (api *API) SetBaseURL(baseURL string) {
+	api.baseURL = strings.TrimSuffix(baseURL, "/")
+}
+
+// SetTransport replaces the HTTP transport, e.g. to record or replay requests in tests; nil means the default
+func (api *API) SetTransport(transport http.RoundTripper) {
+	api.client.Transport = transport
+}
+
 // GetWordInfo retrieves word information from Free Dictionary API
 func (api *API) GetWordInfo(ctx context.Context, word string) ([]WordInfoResp, error) {
 	// Clean and encode the word
@@ -40,19 +63,8 @@ func (api *API) GetWordInfo(ctx context.Context, word string) ([]WordInfoResp, e
 
 	api.logger.Debug("Fetching word info", zap.String("word", word), zap.String("url", apiURL))
 
-	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, http.NoBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Add retry logic
-	var resp *http.Response
-	err = util.RetryWithBackoff(ctx, 3, func() error {
-		resp, err = api.client.Do(req) //nolint:bodyclose
-		if err != nil {
-			return fmt.Errorf("failed to make request: %w", err)
-		}
-		return nil
+	resp, err := api.retry.Do(ctx, api.client, api.limiter, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, "GET", apiURL, http.NoBody)
 	})
 	if err != nil {
 		return nil, err
@@ -60,7 +72,7 @@ func (api *API) GetWordInfo(ctx context.Context, word string) ([]WordInfoResp, e
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("word '%s' not found", word)
+		return nil, fmt.Errorf("word '%s': %w", word, ErrNotFound)
 	}
 
 	if resp.StatusCode != http.StatusOK {
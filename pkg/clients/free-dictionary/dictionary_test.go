@@ -0,0 +1,49 @@
+package free_dictionary //nolint:stylecheck
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"go.uber.org/zap"
+)
+
+func TestLookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/entries/en/book":
+			_, _ = io.WriteString(w, `[{"word":"book","phonetics":[{"text":"/bʊk/","audio":"https://example.com/book-us.mp3"}],
+				"meanings":[{"partOfSpeech":"noun","definitions":[{"definition":"A collection of sheets","synonyms":["volume"]}],
+				"synonyms":["tome"]},{"partOfSpeech":"verb","definitions":[{"definition":"To reserve","example":"I booked a table."}]}]}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"title":"No Definitions Found"}`)
+		}
+	}))
+	defer server.Close()
+
+	api := NewAPI(zap.NewNop())
+	api.SetBaseURL(server.URL + "/entries/en/")
+	api.SetTransport(server.Client().Transport)
+
+	entry, err := api.Lookup(context.Background(), "book", "")
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if len(entry.Senses) != 2 || entry.Senses[1].Example != "I booked a table." || entry.AudioUS == "" || entry.IPAUS != "/bʊk/" {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	if !slices.Equal(entry.Senses[0].Synonyms, []string{"volume", "tome"}) {
+		t.Errorf("noun synonyms %q", entry.Senses[0].Synonyms)
+	}
+
+	if _, err := api.Lookup(context.Background(), "glorpfish", ""); !errors.Is(err, core.ErrWordNotFound) {
+		t.Errorf("missing word: got %v, want core.ErrWordNotFound", err)
+	}
+}
@@ -8,31 +8,39 @@ type WordInfoErrorResp struct {
 }
 
 type WordInfoResp struct {
-	Word      string `json:"word"`
-	Phonetic  string `json:"phonetic"`
-	Phonetics []struct {
-		Text      string `json:"text"`
-		Audio     string `json:"audio"`
-		SourceURL string `json:"sourceUrl"`
-		License   struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"license"`
-	} `json:"phonetics"`
-	Meanings []struct {
-		PartOfSpeech string `json:"partOfSpeech"`
-		Definitions  []struct {
-			Definition string        `json:"definition"`
-			Synonyms   []interface{} `json:"synonyms"`
-			Antonyms   []interface{} `json:"antonyms"`
-			Example    string        `json:"example,omitempty"`
-		} `json:"definitions"`
-		Synonyms []string `json:"synonyms"`
-		Antonyms []string `json:"antonyms"`
-	} `json:"meanings"`
-	License struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"license"`
-	SourceURLs []string `json:"sourceUrls"`
+	Word       string     `json:"word"`
+	Phonetic   string     `json:"phonetic"`
+	Phonetics  []Phonetic `json:"phonetics"`
+	Meanings   []Meaning  `json:"meanings"`
+	License    License    `json:"license"`
+	SourceURLs []string   `json:"sourceUrls"`
+}
+
+// Phonetic is a pronunciation, with an audio file URL when there is one
+type Phonetic struct {
+	Text      string  `json:"text"`
+	Audio     string  `json:"audio"`
+	SourceURL string  `json:"sourceUrl"`
+	License   License `json:"license"`
+}
+
+// Meaning groups the definitions of one part of speech
+type Meaning struct {
+	PartOfSpeech string       `json:"partOfSpeech"`
+	Definitions  []Definition `json:"definitions"`
+	Synonyms     []string     `json:"synonyms"`
+	Antonyms     []string     `json:"antonyms"`
+}
+
+// Definition is a single sense of a word
+type Definition struct {
+	Definition string   `json:"definition"`
+	Synonyms   []string `json:"synonyms"`
+	Antonyms   []string `json:"antonyms"`
+	Example    string   `json:"example,omitempty"`
+}
+
+type License struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
 }
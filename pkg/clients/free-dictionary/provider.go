@@ -0,0 +1,96 @@
+package free_dictionary //nolint:stylecheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+)
+
+// ProviderName identifies the Free Dictionary API in provider chains and the enrichment cache
+const ProviderName = "free-dictionary"
+
+// Name implements core.DictionaryProvider
+func (api *API) Name() string {
+	return ProviderName
+}
+
+// IgnoresPartOfSpeech implements core.PartOfSpeechAgnostic: the API returns all senses of a word
+func (api *API) IgnoresPartOfSpeech() bool {
+	return true
+}
+
+// Lookup implements core.DictionaryProvider on top of GetWordInfo
+func (api *API) Lookup(ctx context.Context, word, _ string) (*core.DictionaryEntry, error) {
+	resp, err := api.GetWordInfo(ctx, word)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("%s: word '%s': %w", ProviderName, word, core.ErrWordNotFound)
+		}
+		return nil, err
+	}
+	return toDictionaryEntry(word, resp), nil
+}
+
+// toDictionaryEntry converts the API response: senses in response order, and UK/US pronunciation
+// taken from the first phonetics whose audio file is marked "-uk.mp3" / "-us.mp3"
+func toDictionaryEntry(word string, resp []WordInfoResp) *core.DictionaryEntry {
+	entry := &core.DictionaryEntry{Word: word}
+	if len(resp) > 0 {
+		entry.Word = resp[0].Word
+	}
+
+	for _, info := range resp { //nolint:gocritic
+		for _, meaning := range info.Meanings {
+			for i, def := range meaning.Definitions {
+				sense := core.Sense{
+					PartOfSpeech: meaning.PartOfSpeech,
+					Definition:   def.Definition,
+					Example:      def.Example,
+					Synonyms:     appendUnique(nil, def.Synonyms...),
+					Antonyms:     appendUnique(nil, def.Antonyms...),
+				}
+				// The synonyms of the part of speech go with its first, most common definition
+				if i == 0 {
+					sense.Synonyms = appendUnique(sense.Synonyms, meaning.Synonyms...)
+					sense.Antonyms = appendUnique(sense.Antonyms, meaning.Antonyms...)
+				}
+				entry.Senses = append(entry.Senses, sense)
+			}
+		}
+	}
+
+	for _, info := range resp { //nolint:gocritic
+		for _, phonetic := range info.Phonetics {
+			if phonetic.Audio == "" {
+				continue
+			}
+			if strings.Contains(phonetic.Audio, "-uk.mp3") && entry.AudioUK == "" {
+				entry.AudioUK = phonetic.Audio
+				entry.IPAUK = phonetic.Text
+			} else if strings.Contains(phonetic.Audio, "-us.mp3") && entry.AudioUS == "" {
+				entry.AudioUS = phonetic.Audio
+				entry.IPAUS = phonetic.Text
+			}
+		}
+		// If we found both UK and US audio, we can stop searching
+		if entry.AudioUK != "" && entry.AudioUS != "" {
+			break
+		}
+	}
+
+	return entry
+}
+
+// appendUnique appends the values that are not in list yet
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		if value != "" && !slices.Contains(list, value) {
+			list = append(list, value)
+		}
+	}
+	return list
+}
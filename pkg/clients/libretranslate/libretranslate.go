@@ -0,0 +1,137 @@
+// Package libretranslate is a client for LibreTranslate-compatible machine translation services.
+package libretranslate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/util"
+
+	"go.uber.org/zap"
+)
+
+// ProviderName identifies LibreTranslate in --translator
+const ProviderName = "libretranslate"
+
+// machineConfidence is the confidence given to machine translations of single words,
+// which lack the context to pick the right sense
+const machineConfidence = 0.5
+
+// API client for a LibreTranslate server
+type API struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	langs   core.LanguagePair
+	limiter *util.RateLimiter
+	retry   *util.RetryPolicy
+	logger  *zap.Logger
+}
+
+// NewAPI creates a new LibreTranslate client for the server at baseURL (e.g. http://localhost:5000)
+// that translates words of the deck's target language back to its source language.
+// apiKey may be empty for servers that do not require one.
+func NewAPI(baseURL, apiKey string, langs core.LanguagePair, logger *zap.Logger) *API {
+	return &API{
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+		langs:   langs,
+		retry:   util.DefaultRetryPolicy(),
+		logger:  logger,
+	}
+}
+
+// SetRateLimiter limits how often the API is called; nil means unlimited
+func (api *API) SetRateLimiter(limiter *util.RateLimiter) {
+	api.limiter = limiter
+}
+
+// SetTransport replaces the HTTP transport, e.g. to record or replay requests in tests; nil means the default
+func (api *API) SetTransport(transport http.RoundTripper) {
+	api.client.Transport = transport
+}
+
+// TranslateText translates text from the target language to the source language
+func (api *API) TranslateText(ctx context.Context, text string) (*TranslateResp, error) {
+	body, err := json.Marshal(TranslateReq{
+		Q:            text,
+		Source:       api.langs.Target,
+		Target:       api.langs.Source,
+		Format:       "text",
+		Alternatives: 2, //nolint:mnd
+		APIKey:       api.apiKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	apiURL := api.baseURL + "/translate"
+	api.logger.Debug("Translating text", zap.String("text", text), zap.String("url", apiURL))
+
+	resp, err := api.retry.Do(ctx, api.client, api.limiter, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResp TranslateErrorResp
+		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err == nil && errorResp.Error != "" {
+			return nil, fmt.Errorf("API error: %s", errorResp.Error)
+		}
+		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+
+	var apiResponse TranslateResp
+	if err := json.NewDecoder(resp.Body).Decode(&apiResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &apiResponse, nil
+}
+
+// Name implements core.Translator
+func (api *API) Name() string {
+	return ProviderName
+}
+
+// Translate implements core.Translator. Machine translations are always given a low confidence.
+func (api *API) Translate(ctx context.Context, text string) (*core.Translation, error) {
+	resp, err := api.TranslateText(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+
+	translated := strings.TrimSpace(resp.TranslatedText)
+	if translated == "" || strings.EqualFold(translated, strings.TrimSpace(text)) {
+		return nil, fmt.Errorf("%s: text '%s': %w", ProviderName, text, core.ErrNoTranslation)
+	}
+
+	candidates := []string{translated}
+	for _, alternative := range resp.Alternatives {
+		if alternative = strings.TrimSpace(alternative); alternative != "" && alternative != translated {
+			candidates = append(candidates, alternative)
+		}
+	}
+	translation, err := core.CandidatesTranslation(candidates)
+	if err != nil {
+		return nil, err
+	}
+	translation.Confidence *= machineConfidence
+	return translation, nil
+}
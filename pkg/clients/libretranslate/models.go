@@ -0,0 +1,22 @@
+package libretranslate
+
+// TranslateReq represents the LibreTranslate /translate request body.
+type TranslateReq struct {
+	Q            string `json:"q"`
+	Source       string `json:"source"`
+	Target       string `json:"target"`
+	Format       string `json:"format"`
+	Alternatives int    `json:"alternatives,omitempty"`
+	APIKey       string `json:"api_key,omitempty"`
+}
+
+// TranslateResp represents the LibreTranslate /translate response.
+type TranslateResp struct {
+	TranslatedText string   `json:"translatedText"`
+	Alternatives   []string `json:"alternatives"`
+}
+
+// TranslateErrorResp represents the LibreTranslate error response.
+type TranslateErrorResp struct {
+	Error string `json:"error"`
+}
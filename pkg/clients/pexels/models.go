@@ -0,0 +1,19 @@
+package pexels
+
+// ImageErrorResp represents the Pexels API error response.
+type ImageErrorResp struct {
+	Error string `json:"error"`
+}
+
+// ImageResp represents the Pexels API search response.
+type ImageResp struct {
+	TotalResults int `json:"total_results"`
+	Photos       []struct {
+		ID  int `json:"id"`
+		Src struct {
+			Original string `json:"original"`
+			Large    string `json:"large"`
+			Medium   string `json:"medium"`
+		} `json:"src"`
+	} `json:"photos"`
+}
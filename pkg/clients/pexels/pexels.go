@@ -0,0 +1,128 @@
+package pexels
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/util"
+
+	"go.uber.org/zap"
+)
+
+// ProviderName identifies Pexels in --image-provider and the enrichment cache
+const ProviderName = "pexels"
+
+// ErrNoImages is returned when the search has no results for the query
+var ErrNoImages = errors.New("no images found")
+
+// API client for Pexels API
+type API struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	limiter *util.RateLimiter
+	retry   *util.RetryPolicy
+	logger  *zap.Logger
+}
+
+// NewAPI creates a new Pexels API client
+func NewAPI(apiKey string, logger *zap.Logger) *API {
+	return &API{
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		baseURL: "https://api.pexels.com/v1/search",
+		apiKey:  apiKey,
+		retry:   util.DefaultRetryPolicy(),
+		logger:  logger,
+	}
+}
+
+// SetRateLimiter limits how often the API is called; nil means unlimited
+func (api *API) SetRateLimiter(limiter *util.RateLimiter) {
+	api.limiter = limiter
+}
+
+// SetBaseURL points the client at another server, e.g. a fake server in tests
+func (api *API) SetBaseURL(baseURL string) {
+	api.baseURL = baseURL
+}
+
+// SetTransport replaces the HTTP transport, e.g. to record or replay requests in tests; nil means the default
+func (api *API) SetTransport(transport http.RoundTripper) {
+	api.client.Transport = transport
+}
+
+// GetImageURL retrieves an image URL from Pexels API
+func (api *API) GetImageURL(ctx context.Context, query string) (string, error) {
+	params := url.Values{}
+	params.Add("query", query)
+	params.Add("per_page", "1")
+	params.Add("orientation", "landscape")
+
+	apiURL := fmt.Sprintf("%s?%s", api.baseURL, params.Encode())
+	api.logger.Debug("Fetching image URL", zap.String("query", query), zap.String("url", apiURL))
+
+	resp, err := api.retry.Do(ctx, api.client, api.limiter, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", apiURL, http.NoBody)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", api.apiKey)
+		return req, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResp ImageErrorResp
+		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err == nil && errorResp.Error != "" {
+			return "", fmt.Errorf("API error: %s", errorResp.Error)
+		}
+		switch resp.StatusCode {
+		case http.StatusUnauthorized:
+			return "", fmt.Errorf("unauthorized: invalid API key")
+		case http.StatusTooManyRequests:
+			return "", fmt.Errorf("rate limit exceeded: too many requests")
+		default:
+			return "", fmt.Errorf("API returned status %d", resp.StatusCode)
+		}
+	}
+
+	var apiResponse ImageResp
+	if err := json.NewDecoder(resp.Body).Decode(&apiResponse); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(apiResponse.Photos) == 0 {
+		return "", fmt.Errorf("query '%s': %w", query, ErrNoImages)
+	}
+
+	imageURL := apiResponse.Photos[0].Src.Large
+	api.logger.Debug("Successfully fetched image URL", zap.String("query", query), zap.String("url", imageURL))
+	return imageURL, nil
+}
+
+// Name implements core.ImageProvider
+func (api *API) Name() string {
+	return ProviderName
+}
+
+// FindImage implements core.ImageProvider on top of GetImageURL
+func (api *API) FindImage(ctx context.Context, query string) (*core.Image, error) {
+	imageURL, err := api.GetImageURL(ctx, query)
+	if err != nil {
+		if errors.Is(err, ErrNoImages) {
+			return nil, fmt.Errorf("%s: query '%s': %w", ProviderName, query, core.ErrNoImage)
+		}
+		return nil, err
+	}
+	return &core.Image{URL: imageURL}, nil
+}
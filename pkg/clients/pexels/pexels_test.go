@@ -0,0 +1,62 @@
+package pexels
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"go.uber.org/zap"
+)
+
+func TestFindImage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = io.WriteString(w, `{"error":"Authorization field missing"}`)
+			return
+		}
+		if r.URL.Query().Get("per_page") != "1" || r.URL.Query().Get("orientation") != "landscape" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		switch r.URL.Query().Get("query") {
+		case "ice cream":
+			_, _ = io.WriteString(w, `{"page":1,"per_page":1,"total_results":8000,"photos":[{"id":1,"width":4000,"height":3000,
+				"src":{"original":"https://images.pexels.com/photos/1/ice-cream.jpeg",
+				"large":"https://images.pexels.com/photos/1/ice-cream.jpeg?h=650&w=940",
+				"medium":"https://images.pexels.com/photos/1/ice-cream.jpeg?h=350"}}]}`)
+		default:
+			_, _ = io.WriteString(w, `{"page":1,"per_page":1,"total_results":0,"photos":[]}`)
+		}
+	}))
+	defer server.Close()
+
+	api := NewAPI("test-key", zap.NewNop())
+	api.SetBaseURL(server.URL + "/v1/search")
+	api.SetTransport(server.Client().Transport)
+
+	image, err := api.FindImage(context.Background(), "ice cream")
+	if err != nil {
+		t.Fatalf("FindImage: %v", err)
+	}
+	if image.URL != "https://images.pexels.com/photos/1/ice-cream.jpeg?h=650&w=940" {
+		t.Errorf("image URL %q, want the large size of the first photo", image.URL)
+	}
+
+	if _, err := api.FindImage(context.Background(), "glorpfish"); !errors.Is(err, core.ErrNoImage) {
+		t.Errorf("no photos: got %v, want core.ErrNoImage", err)
+	}
+
+	api = NewAPI("", zap.NewNop())
+	api.SetBaseURL(server.URL + "/v1/search")
+	api.SetTransport(server.Client().Transport)
+	_, err = api.FindImage(context.Background(), "ice cream")
+	if err == nil || !strings.Contains(err.Error(), "Authorization field missing") {
+		t.Errorf("missing key: got %v, want the error of the API", err)
+	}
+}
@@ -0,0 +1,12 @@
+package pixabay
+
+// ImageResp represents the Pixabay API search response.
+type ImageResp struct {
+	Total     int `json:"total"`
+	TotalHits int `json:"totalHits"`
+	Hits      []struct {
+		ID            int    `json:"id"`
+		WebformatURL  string `json:"webformatURL"`
+		LargeImageURL string `json:"largeImageURL"`
+	} `json:"hits"`
+}
@@ -0,0 +1,124 @@
+package pixabay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/util"
+
+	"go.uber.org/zap"
+)
+
+// ProviderName identifies Pixabay in --image-provider and the enrichment cache
+const ProviderName = "pixabay"
+
+// ErrNoImages is returned when the search has no results for the query
+var ErrNoImages = errors.New("no images found")
+
+// API client for Pixabay API
+type API struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	limiter *util.RateLimiter
+	retry   *util.RetryPolicy
+	logger  *zap.Logger
+}
+
+// NewAPI creates a new Pixabay API client
+func NewAPI(apiKey string, logger *zap.Logger) *API {
+	return &API{
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		baseURL: "https://pixabay.com/api/",
+		apiKey:  apiKey,
+		retry:   util.DefaultRetryPolicy(),
+		logger:  logger,
+	}
+}
+
+// SetRateLimiter limits how often the API is called; nil means unlimited
+func (api *API) SetRateLimiter(limiter *util.RateLimiter) {
+	api.limiter = limiter
+}
+
+// SetBaseURL points the client at another server, e.g. a fake server in tests
+func (api *API) SetBaseURL(baseURL string) {
+	api.baseURL = baseURL
+}
+
+// SetTransport replaces the HTTP transport, e.g. to record or replay requests in tests; nil means the default
+func (api *API) SetTransport(transport http.RoundTripper) {
+	api.client.Transport = transport
+}
+
+// GetImageURL retrieves an image URL from Pixabay API
+func (api *API) GetImageURL(ctx context.Context, query string) (string, error) {
+	params := url.Values{}
+	params.Add("key", api.apiKey)
+	params.Add("q", query)
+	params.Add("image_type", "photo")
+	params.Add("orientation", "horizontal")
+	params.Add("safesearch", "true")
+	params.Add("per_page", "3") // API minimum
+
+	apiURL := fmt.Sprintf("%s?%s", api.baseURL, params.Encode())
+	api.logger.Debug("Fetching image URL", zap.String("query", query))
+
+	resp, err := api.retry.Do(ctx, api.client, api.limiter, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, "GET", apiURL, http.NoBody)
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// Pixabay reports errors as plain text
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512)) //nolint:mnd
+		switch resp.StatusCode {
+		case http.StatusTooManyRequests:
+			return "", fmt.Errorf("rate limit exceeded: too many requests")
+		default:
+			return "", fmt.Errorf("API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		}
+	}
+
+	var apiResponse ImageResp
+	if err := json.NewDecoder(resp.Body).Decode(&apiResponse); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(apiResponse.Hits) == 0 {
+		return "", fmt.Errorf("query '%s': %w", query, ErrNoImages)
+	}
+
+	imageURL := apiResponse.Hits[0].WebformatURL
+	api.logger.Debug("Successfully fetched image URL", zap.String("query", query), zap.String("url", imageURL))
+	return imageURL, nil
+}
+
+// Name implements core.ImageProvider
+func (api *API) Name() string {
+	return ProviderName
+}
+
+// FindImage implements core.ImageProvider on top of GetImageURL
+func (api *API) FindImage(ctx context.Context, query string) (*core.Image, error) {
+	imageURL, err := api.GetImageURL(ctx, query)
+	if err != nil {
+		if errors.Is(err, ErrNoImages) {
+			return nil, fmt.Errorf("%s: query '%s': %w", ProviderName, query, core.ErrNoImage)
+		}
+		return nil, err
+	}
+	return &core.Image{URL: imageURL}, nil
+}
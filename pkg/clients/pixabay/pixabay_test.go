@@ -0,0 +1,61 @@
+package pixabay
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+
+	"go.uber.org/zap"
+)
+
+func TestFindImage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("key") != "test-key" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, "[ERROR 400] Invalid or missing API key (https://pixabay.com/api/docs/).\n")
+			return
+		}
+		if query.Get("image_type") != "photo" || query.Get("per_page") != "3" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		switch query.Get("q") {
+		case "ice cream":
+			_, _ = io.WriteString(w, `{"total":120,"totalHits":120,"hits":[
+				{"id":1,"webformatURL":"https://pixabay.com/get/ice-cream_640.jpg","largeImageURL":"https://pixabay.com/get/ice-cream_1280.jpg"},
+				{"id":2,"webformatURL":"https://pixabay.com/get/cone_640.jpg","largeImageURL":"https://pixabay.com/get/cone_1280.jpg"}]}`)
+		default:
+			_, _ = io.WriteString(w, `{"total":0,"totalHits":0,"hits":[]}`)
+		}
+	}))
+	defer server.Close()
+
+	api := NewAPI("test-key", zap.NewNop())
+	api.SetBaseURL(server.URL + "/api/")
+	api.SetTransport(server.Client().Transport)
+
+	image, err := api.FindImage(context.Background(), "ice cream")
+	if err != nil {
+		t.Fatalf("FindImage: %v", err)
+	}
+	if image.URL != "https://pixabay.com/get/ice-cream_640.jpg" {
+		t.Errorf("image URL %q, want the web format of the first hit", image.URL)
+	}
+
+	if _, err := api.FindImage(context.Background(), "glorpfish"); !errors.Is(err, core.ErrNoImage) {
+		t.Errorf("no hits: got %v, want core.ErrNoImage", err)
+	}
+
+	api = NewAPI("wrong-key", zap.NewNop())
+	api.SetBaseURL(server.URL + "/api/")
+	api.SetTransport(server.Client().Transport)
+	if _, err := api.FindImage(context.Background(), "ice cream"); err == nil || !strings.Contains(err.Error(), "Invalid or missing API key") {
+		t.Errorf("bad key: got %v, want the plain-text error of the API", err)
+	}
+}
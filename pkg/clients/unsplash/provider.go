@@ -0,0 +1,29 @@
+package unsplash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/commedesvlados/anki-flashcards-autogen-cli/internal/core"
+)
+
+// ProviderName identifies Unsplash in --image-provider and the enrichment cache
+const ProviderName = "unsplash"
+
+// Name implements core.ImageProvider
+func (api *API) Name() string {
+	return ProviderName
+}
+
+// FindImage implements core.ImageProvider on top of GetImageURL
+func (api *API) FindImage(ctx context.Context, query string) (*core.Image, error) {
+	imageURL, err := api.GetImageURL(ctx, query)
+	if err != nil {
+		if errors.Is(err, ErrNoImages) {
+			return nil, fmt.Errorf("%s: query '%s': %w", ProviderName, query, core.ErrNoImage)
+		}
+		return nil, err
+	}
+	return &core.Image{URL: imageURL}, nil
+}
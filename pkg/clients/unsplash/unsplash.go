@@ -3,6 +3,7 @@ package unsplash
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -13,11 +14,16 @@ import (
 	"go.uber.org/zap"
 )
 
+// ErrNoImages is returned when the search has no results for the query
+var ErrNoImages = errors.New("no images found")
+
 // API client for Unsplash API
 type API struct {
 	client    *http.Client
 	baseURL   string
 	accessKey string
+	limiter   *util.RateLimiter
+	retry     *util.RetryPolicy
 	logger    *zap.Logger
 }
 
@@ -29,10 +35,26 @@ func NewAPI(accessKey string, logger *zap.Logger) *API {
 		},
 		baseURL:   "https://api.unsplash.com/search/photos",
 		accessKey: accessKey,
+		retry:     util.DefaultRetryPolicy(),
 		logger:    logger,
 	}
 }
 
+// SetRateLimiter limits how often the API is called; nil means unlimited
+func (api *API) SetRateLimiter(limiter *util.RateLimiter) {
+	api.limiter = limiter
+}
+
+// SetBaseURL points the client at another server, e.g. a fake server in tests
+func (api *API) SetBaseURL(baseURL string) {
+	api.baseURL = baseURL
+}
+
+// SetTransport replaces the HTTP transport, e.g. to record or replay requests in tests; nil means the default
+func (api *API) SetTransport(transport http.RoundTripper) {
+	api.client.Transport = transport
+}
+
 // GetImageURL retrieves an image URL from Unsplash API
 func (api *API) GetImageURL(ctx context.Context, query string) (string, error) {
 	// Build URL with query parameters
@@ -45,24 +67,14 @@ func (api *API) GetImageURL(ctx context.Context, query string) (string, error) {
 
 	api.logger.Debug("Fetching image URL", zap.String("query", query), zap.String("url", apiURL))
 
-	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, http.NoBody)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Add authorization header
-	req.Header.Set("Authorization", fmt.Sprintf("Client-ID %s", api.accessKey))
-
-	// Add retry logic
-	var resp *http.Response
-	err = util.RetryWithBackoff(ctx, 3, func() error {
-		resp, err = api.client.Do(req) //nolint:bodyclose
+	resp, err := api.retry.Do(ctx, api.client, api.limiter, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", apiURL, http.NoBody)
 		if err != nil {
-			return fmt.Errorf("failed to make request: %w", err)
+			return nil, err
 		}
-		return nil
+		req.Header.Set("Authorization", fmt.Sprintf("Client-ID %s", api.accessKey))
+		return req, nil
 	})
-
 	if err != nil {
 		return "", err
 	}
@@ -98,7 +110,7 @@ func (api *API) GetImageURL(ctx context.Context, query string) (string, error) {
 	}
 
 	if len(apiResponse.Results) == 0 {
-		return "", fmt.Errorf("no images found for query '%s'", query)
+		return "", fmt.Errorf("query '%s': %w", query, ErrNoImages)
 	}
 
 	imageURL := apiResponse.Results[0].URLs.Regular
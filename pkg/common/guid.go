@@ -0,0 +1,29 @@
+package common
+
+import (
+	"crypto/sha256"
+	"strings"
+)
+
+const base91Table = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!#$%&()*+,-./:;<=>?@[]^_`{|}~"
+
+// GUIDFor derives an Anki note GUID from the given values, compatible with genanki's guid_for
+func GUIDFor(values ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(values, "__")))
+
+	var n uint64
+	for _, b := range sum[:8] {
+		n = n<<8 | uint64(b)
+	}
+
+	base := uint64(len(base91Table))
+	var out []byte
+	for n > 0 {
+		out = append(out, base91Table[n%base])
+		n /= base
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
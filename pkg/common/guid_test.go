@@ -0,0 +1,22 @@
+package common
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGUIDFor(t *testing.T) {
+	// Same algorithm as genanki.guid_for("яблоко", "apple")
+	got := GUIDFor("яблоко", "apple")
+	if got != "mT(+VDF*6u" {
+		t.Errorf("Expected GUID mT(+VDF*6u, got %q", got)
+	}
+	if got == GUIDFor("apple", "яблоко") {
+		t.Errorf("GUIDFor should depend on value order")
+	}
+	for _, r := range got {
+		if !strings.ContainsRune(base91Table, r) {
+			t.Errorf("GUID %q contains character outside of base91 table", got)
+		}
+	}
+}
@@ -11,15 +11,45 @@ import sys
 import os
 sys.path.insert(0, os.path.join(os.path.dirname(__file__), '..', 'venv', 'lib', 'python3.12', 'site-packages'))
 
+import html
 import json
 import genanki  # type: ignore
 import hashlib
 from pathlib import Path
 
-def create_note_type():
-    """Create a custom note type for Designed Autogenerated RU-EN Flashcards"""
+DEFAULT_MODEL_ID = 1607392319
+DEFAULT_DECK_ID = 2059400110
+DEFAULT_SOURCE_LANG = 'ru'
+DEFAULT_TARGET_LANG = 'en'
+
+# English language names used in reverso links; mirrors internal/core/language.go
+LANGUAGE_NAMES = {
+    'ar': 'arabic', 'de': 'german', 'en': 'english', 'es': 'spanish', 'fr': 'french',
+    'he': 'hebrew', 'it': 'italian', 'ja': 'japanese', 'nl': 'dutch', 'pl': 'polish',
+    'pt': 'portuguese', 'ro': 'romanian', 'ru': 'russian', 'sv': 'swedish', 'tr': 'turkish',
+    'uk': 'ukrainian', 'zh': 'chinese',
+}
+
+def localize(text, source, target):
+    """Rename the RU-EN fields and classes of a template after a language pair"""
+    replacements = [
+        ('{{RU}}', '{{' + source.upper() + '}}'),
+        ('{{EN}}', '{{' + target.upper() + '}}'),
+        ('ru-word', source + '-word'),
+        ('en-word', target + '-word'),
+        ('english-russian', LANGUAGE_NAMES.get(target, '') + '-' + LANGUAGE_NAMES.get(source, '')),
+    ]
+    # Replace placeholders first so replacements never apply to each other's output
+    for i, (old, _) in enumerate(replacements):
+        text = text.replace(old, f'\x00{i}\x00')
+    for i, (_, new) in enumerate(replacements):
+        text = text.replace(f'\x00{i}\x00', new)
+    return text
+
+def create_note_type(model_id=DEFAULT_MODEL_ID, source=DEFAULT_SOURCE_LANG, target=DEFAULT_TARGET_LANG):
+    """Create a custom note type for Designed Autogenerated flashcards of a language pair"""
     
-    # Front template (Russian word)
+    # Front template (source language word)
     front_template = """
     <div class="card">
       <div class="card-image">{{Image}}</div>
@@ -27,7 +57,7 @@ def create_note_type():
     </div>
     """
     
-    # Back template (English word + details)
+    # Back template (target language word + details)
     back_template = """
     <div class="card">
 
@@ -35,9 +65,22 @@ def create_note_type():
 
       <div class="ru-word">{{RU}}</div>
       <div class="en-word">{{EN}}</div>
+      {{#PartOfSpeech}}
       <div class="part-of-speech"><i>{{PartOfSpeech}}</i></div>
+      {{/PartOfSpeech}}
+      {{#Definition}}
       <div class="definition">{{Definition}}</div>
+      {{/Definition}}
+      {{#Example}}
       <div class="example"><em>{{Example}}</em></div>
+      {{/Example}}
+
+      {{#Synonyms}}
+      <div class="related"><span>Synonyms:</span> {{Synonyms}}</div>
+      {{/Synonyms}}
+      {{#Antonyms}}
+      <div class="related"><span>Antonyms:</span> {{Antonyms}}</div>
+      {{/Antonyms}}
 
       {{#AudioUK}}
       <div class="audio-row">
@@ -109,6 +152,15 @@ def create_note_type():
       margin-bottom: 10px;
     }
 
+    .related {
+      color: #555;
+      margin: 4px 0;
+    }
+
+    .related span {
+      font-weight: bold;
+    }
+
     .audio-row {
       display: flex;
       justify-content: center;
@@ -171,12 +223,19 @@ def create_note_type():
     }
     """
     
+    if source not in LANGUAGE_NAMES or target not in LANGUAGE_NAMES:
+        # Reverso links need language names; drop the link for languages we cannot name
+        start = back_template.index('      <div class="reverso-link">')
+        end = back_template.index('      </div>\n', start) + len('      </div>\n')
+        back_template = back_template[:start] + back_template[end:]
+
+    name = f'Designed Autogenerated {source.upper()}-{target.upper()} Flashcard'
     return genanki.Model(
-        1607392319,  # Random model ID
-        'Designed Autogenerated RU-EN Flashcard',
+        model_id,  # Derived from the note type definition by the Go binary
+        name,
         fields=[
-            {'name': 'RU'},
-            {'name': 'EN'},
+            {'name': source.upper()},
+            {'name': target.upper()},
             {'name': 'PartOfSpeech'},
             {'name': 'Definition'},
             {'name': 'Example'},
@@ -185,26 +244,46 @@ def create_note_type():
             {'name': 'AudioUK'},
             {'name': 'AudioUS'},
             {'name': 'Image'},
+            {'name': 'Synonyms'},
+            {'name': 'Antonyms'},
         ],
         templates=[
             {
-                'name': 'Designed Autogenerated RU-EN Flashcard',
-                'qfmt': front_template,
-                'afmt': back_template,
+                'name': name,
+                'qfmt': localize(front_template, source, target),
+                'afmt': localize(back_template, source, target),
             }
         ],
-        css=css
+        css=localize(css, source, target)
     )
 
-def create_deck(flashcards, deck_name="Designed Autogenerated RU-EN Vocabulary"):
+def definition_markup(flashcard):
+    """The definition, or a numbered list of the ranked senses; mirrors definitionMarkup in internal/anki"""
+    senses = flashcard.get('senses') or []
+    if len(senses) < 2:
+        return flashcard.get('definition', '')
+    items = []
+    for sense in senses:
+        item = ''
+        if sense.get('part_of_speech'):
+            item += '<i>' + html.escape(sense['part_of_speech']) + '</i> '
+        item += html.escape(sense.get('definition', ''))
+        if sense.get('example'):
+            item += '<br><small><em>' + html.escape(sense['example']) + '</em></small>'
+        items.append('<li>' + item + '</li>')
+    return '<ol style="text-align: left; display: inline-block; margin: 0;">' + ''.join(items) + '</ol>'
+
+def create_deck(flashcards, deck_name="Designed Autogenerated RU-EN Vocabulary",
+                deck_id=DEFAULT_DECK_ID, model_id=DEFAULT_MODEL_ID,
+                source=DEFAULT_SOURCE_LANG, target=DEFAULT_TARGET_LANG):
     """Create an Anki deck from flashcards"""
     
     # Create note type
-    model = create_note_type()
+    model = create_note_type(model_id, source, target)
     
     # Create deck
     deck = genanki.Deck(
-        2059400110,  # Random deck ID
+        deck_id,  # Derived from the deck name by the Go binary
         deck_name
     )
     
@@ -222,22 +301,26 @@ def create_deck(flashcards, deck_name="Designed Autogenerated RU-EN Vocabulary")
         
         # Create note fields
         fields = [
-            flashcard.get('russian', ''),
-            flashcard.get('english', ''),
+            flashcard.get('source', flashcard.get('russian', '')),  # enriched.json before language pairs
+            flashcard.get('target', flashcard.get('english', '')),
             flashcard.get('part_of_speech', ''),
-            flashcard.get('definition', ''),
+            definition_markup(flashcard),
             flashcard.get('example', ''),
             flashcard.get('ipa_uk', ''),
             flashcard.get('ipa_us', ''),
             audio_uk_markup,
             audio_us_markup,
             image_markup,
+            ', '.join(flashcard.get('synonyms') or []),
+            ', '.join(flashcard.get('antonyms') or []),
         ]
         
-        # Create note
+        # Create note; a stable GUID lets re-imports update existing notes
         note = genanki.Note(
             model=model,
-            fields=fields
+            fields=fields,
+            guid=flashcard.get('guid') or None,
+            tags=flashcard.get('tags') or []
         )
         
         deck.add_note(note)
@@ -262,14 +345,19 @@ def add_media_files(deck, media_dir):
     return media_files
 
 def main():
-    if len(sys.argv) != 5:
-        print("Usage: python make_apkg.py <json_file> <media_dir> <output_file> <deck_name>")
+    if len(sys.argv) not in (5, 7, 9):
+        print("Usage: python make_apkg.py <json_file> <media_dir> <output_file> <deck_name> "
+              "[<deck_id> <model_id> [<source_lang> <target_lang>]]")
         sys.exit(1)
     
     json_file = sys.argv[1]
     media_dir = sys.argv[2]
     output_file = sys.argv[3]
     deck_name = sys.argv[4]
+    deck_id = int(sys.argv[5]) if len(sys.argv) >= 7 else DEFAULT_DECK_ID
+    model_id = int(sys.argv[6]) if len(sys.argv) >= 7 else DEFAULT_MODEL_ID
+    source = sys.argv[7] if len(sys.argv) == 9 else DEFAULT_SOURCE_LANG
+    target = sys.argv[8] if len(sys.argv) == 9 else DEFAULT_TARGET_LANG
     
     # Read JSON file
     try:
@@ -285,7 +373,7 @@ def main():
     print(f"Loaded {len(flashcards)} flashcards from {json_file}")
     
     # Create deck
-    deck = create_deck(flashcards, deck_name)
+    deck = create_deck(flashcards, deck_name, deck_id, model_id, source, target)
     
     # Add media files
     media_files = add_media_files(deck, media_dir)